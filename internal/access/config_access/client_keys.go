@@ -0,0 +1,254 @@
+package configaccess
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	internalusage "github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// clientKey holds the restrictions attached to an accepted key. Plain api-keys entries use
+// the zero value, which carries no restrictions.
+type clientKey struct {
+	name            string
+	key             string
+	keyHash         string
+	allowedModels   []string
+	allowedPrefixes []string
+	expiresAt       time.Time
+	monthlyBudget   int64
+}
+
+// monthlyTokens tracks tokens consumed per client key in the current UTC calendar month.
+// It outlives provider instances so budgets survive config reloads, and is seeded from the
+// persistent usage store, when one is configured, so they survive restarts too.
+var monthlyTokens = &monthlyUsage{tokens: make(map[string]int64), store: internalusage.ActiveStore}
+
+func init() {
+	coreusage.RegisterPlugin(monthlyTokens)
+}
+
+func normalizeClientKeys(entries []sdkconfig.ClientKey) []*clientKey {
+	if len(entries) == 0 {
+		return nil
+	}
+	out := make([]*clientKey, 0, len(entries))
+	for i := range entries {
+		entry := entries[i]
+		key := strings.TrimSpace(entry.Key)
+		keyHash := strings.ToLower(strings.TrimSpace(entry.KeyHash))
+		keyHash = strings.TrimPrefix(keyHash, "sha256:")
+		if key == "" && keyHash == "" {
+			continue
+		}
+		normalized := &clientKey{
+			name:            strings.TrimSpace(entry.Name),
+			key:             key,
+			keyHash:         keyHash,
+			allowedModels:   trimList(entry.AllowedModels, false),
+			allowedPrefixes: trimList(entry.AllowedCredentialPrefixes, true),
+			monthlyBudget:   entry.MonthlyTokenBudget,
+		}
+		if raw := strings.TrimSpace(entry.ExpiresAt); raw != "" {
+			expiresAt, ok := parseExpiry(raw)
+			if !ok {
+				log.Warnf("client-keys: ignoring entry %q with invalid expires-at %q", normalized.name, raw)
+				continue
+			}
+			normalized.expiresAt = expiresAt
+		}
+		out = append(out, normalized)
+	}
+	return out
+}
+
+// trimList trims entries and drops empty ones unless keepEmpty is set, in which case an empty
+// entry is preserved once (used to allow unprefixed credentials).
+func trimList(values []string, keepEmpty bool) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" && !keepEmpty {
+			continue
+		}
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		out = append(out, value)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func parseExpiry(raw string) (time.Time, bool) {
+	if parsed, err := time.Parse(time.RFC3339, raw); err == nil {
+		return parsed, true
+	}
+	if parsed, err := time.Parse(time.DateOnly, raw); err == nil {
+		return parsed.Add(24*time.Hour - time.Nanosecond), true
+	}
+	return time.Time{}, false
+}
+
+func hashKey(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// check rejects expired keys and keys whose monthly token budget is exhausted.
+func (k *clientKey) check(principal string, now time.Time) *sdkaccess.AuthError {
+	if !k.expiresAt.IsZero() && !now.Before(k.expiresAt) {
+		return sdkaccess.NewExpiredCredentialError()
+	}
+	if k.monthlyBudget > 0 && monthlyTokens.used(principal, now) >= k.monthlyBudget {
+		return sdkaccess.NewQuotaExceededError("Monthly token budget exhausted")
+	}
+	return nil
+}
+
+// annotate surfaces the key restrictions through the access result metadata.
+func (k *clientKey) annotate(metadata map[string]string) {
+	if k.name != "" {
+		metadata[sdkaccess.MetadataKeyClientName] = k.name
+	}
+	if len(k.allowedModels) > 0 {
+		metadata[sdkaccess.MetadataKeyAllowedModels] = encodeList(k.allowedModels)
+	}
+	if len(k.allowedPrefixes) > 0 {
+		metadata[sdkaccess.MetadataKeyAllowedCredentialPrefixes] = encodeList(k.allowedPrefixes)
+	}
+	if !k.expiresAt.IsZero() {
+		metadata[sdkaccess.MetadataKeyExpiresAt] = k.expiresAt.UTC().Format(time.RFC3339)
+	}
+	if k.monthlyBudget > 0 {
+		metadata[sdkaccess.MetadataKeyMonthlyTokenBudget] = strconv.FormatInt(k.monthlyBudget, 10)
+	}
+}
+
+func encodeList(values []string) string {
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+// monthlyUsage counts tokens per client key, keyed by the key's SHA-256 as the usage store is.
+type monthlyUsage struct {
+	mu     sync.Mutex
+	month  string
+	tokens map[string]int64
+	// store returns the persistent usage store the month is seeded from, if any.
+	store  func() internalusage.Store
+	seeded string
+}
+
+// HandleUsage implements coreusage.Plugin.
+func (u *monthlyUsage) HandleUsage(_ context.Context, record coreusage.Record) {
+	key := strings.TrimSpace(record.APIKey)
+	if key == "" || record.Hedged {
+		return
+	}
+	tokens := record.Detail.TotalTokens
+	if tokens <= 0 {
+		tokens = record.Detail.InputTokens + record.Detail.OutputTokens + record.Detail.ReasoningTokens
+	}
+	if tokens <= 0 {
+		return
+	}
+	at := record.RequestedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.rollLocked(at)
+	if month := at.UTC().Format("2006-01"); month != u.month {
+		// Late records from a previous month do not count against the current budget.
+		return
+	}
+	u.tokens[internalusage.HashAPIKey(key)] += tokens
+}
+
+func (u *monthlyUsage) used(key string, now time.Time) int64 {
+	u.seed(now)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.rollLocked(now)
+	return u.tokens[internalusage.HashAPIKey(key)]
+}
+
+func (u *monthlyUsage) rollLocked(now time.Time) {
+	month := now.UTC().Format("2006-01")
+	if month > u.month {
+		u.month = month
+		u.tokens = make(map[string]int64)
+	}
+}
+
+// seed loads the month's stored usage once per month. Records of this process are usually in the
+// store already, so each key keeps the larger of the stored and the counted total.
+func (u *monthlyUsage) seed(now time.Time) {
+	if u.store == nil {
+		return
+	}
+	month := now.UTC().Format("2006-01")
+	u.mu.Lock()
+	if u.seeded >= month {
+		u.mu.Unlock()
+		return
+	}
+	store := u.store()
+	if store == nil {
+		u.mu.Unlock()
+		return
+	}
+	u.seeded = month
+	u.mu.Unlock()
+
+	start := time.Date(now.UTC().Year(), now.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	records, err := store.Query(ctx, start, time.Time{})
+	if err != nil {
+		log.Warnf("client-keys: seed monthly token usage: %v", err)
+		return
+	}
+	stored := make(map[string]int64)
+	for _, record := range records {
+		if record.APIKeyHash == "" || record.Hedged {
+			continue
+		}
+		tokens := record.Tokens.TotalTokens
+		if tokens <= 0 {
+			tokens = record.Tokens.InputTokens + record.Tokens.OutputTokens + record.Tokens.ReasoningTokens
+		}
+		stored[record.APIKeyHash] += tokens
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.rollLocked(now)
+	if u.month != month {
+		return
+	}
+	for hash, tokens := range stored {
+		u.tokens[hash] = max(u.tokens[hash], tokens)
+	}
+}
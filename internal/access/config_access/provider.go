@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
@@ -17,32 +18,44 @@ func Register(cfg *sdkconfig.SDKConfig) {
 	}
 
 	keys := normalizeKeys(cfg.APIKeys)
-	if len(keys) == 0 {
+	clientKeys := normalizeClientKeys(cfg.ClientKeys)
+	if len(keys) == 0 && len(clientKeys) == 0 {
 		sdkaccess.UnregisterProvider(sdkaccess.AccessProviderTypeConfigAPIKey)
 		return
 	}
 
 	sdkaccess.RegisterProvider(
 		sdkaccess.AccessProviderTypeConfigAPIKey,
-		newProvider(sdkaccess.DefaultAccessProviderName, keys),
+		newProvider(sdkaccess.DefaultAccessProviderName, keys, clientKeys...),
 	)
 }
 
 type provider struct {
 	name string
-	keys map[string]struct{}
+	keys map[string]*clientKey
+	// hashed indexes structured client keys configured by SHA-256 digest.
+	hashed map[string]*clientKey
 }
 
-func newProvider(name string, keys []string) *provider {
+func newProvider(name string, keys []string, clientKeys ...*clientKey) *provider {
 	providerName := strings.TrimSpace(name)
 	if providerName == "" {
 		providerName = sdkaccess.DefaultAccessProviderName
 	}
-	keySet := make(map[string]struct{}, len(keys))
+	keySet := make(map[string]*clientKey, len(keys)+len(clientKeys))
 	for _, key := range keys {
-		keySet[key] = struct{}{}
+		keySet[key] = &clientKey{}
+	}
+	hashed := make(map[string]*clientKey)
+	for _, entry := range clientKeys {
+		if entry.key != "" {
+			keySet[entry.key] = entry
+		}
+		if entry.keyHash != "" {
+			hashed[entry.keyHash] = entry
+		}
 	}
-	return &provider{name: providerName, keys: keySet}
+	return &provider{name: providerName, keys: keySet, hashed: hashed}
 }
 
 func (p *provider) Identifier() string {
@@ -56,7 +69,7 @@ func (p *provider) Authenticate(_ context.Context, r *http.Request) (*sdkaccess.
 	if p == nil {
 		return nil, sdkaccess.NewNotHandledError()
 	}
-	if len(p.keys) == 0 {
+	if len(p.keys) == 0 && len(p.hashed) == 0 {
 		return nil, sdkaccess.NewNotHandledError()
 	}
 	authHeader := r.Header.Get("Authorization")
@@ -89,20 +102,37 @@ func (p *provider) Authenticate(_ context.Context, r *http.Request) (*sdkaccess.
 		if candidate.value == "" {
 			continue
 		}
-		if _, ok := p.keys[candidate.value]; ok {
-			return &sdkaccess.Result{
-				Provider:  p.Identifier(),
-				Principal: candidate.value,
-				Metadata: map[string]string{
-					"source": candidate.source,
-				},
-			}, nil
+		entry := p.lookup(candidate.value)
+		if entry == nil {
+			continue
+		}
+		if errAuth := entry.check(candidate.value, time.Now()); errAuth != nil {
+			return nil, errAuth
 		}
+		metadata := map[string]string{
+			"source": candidate.source,
+		}
+		entry.annotate(metadata)
+		return &sdkaccess.Result{
+			Provider:  p.Identifier(),
+			Principal: candidate.value,
+			Metadata:  metadata,
+		}, nil
 	}
 
 	return nil, sdkaccess.NewInvalidCredentialError()
 }
 
+func (p *provider) lookup(value string) *clientKey {
+	if entry, ok := p.keys[value]; ok {
+		return entry
+	}
+	if len(p.hashed) == 0 {
+		return nil
+	}
+	return p.hashed[hashKey(value)]
+}
+
 func extractBearerToken(header string) string {
 	if header == "" {
 		return ""
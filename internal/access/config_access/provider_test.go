@@ -0,0 +1,147 @@
+package configaccess
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	internalusage "github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func authenticateWithKey(t *testing.T, p *provider, key string) (*sdkaccess.Result, *sdkaccess.AuthError) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+	return p.Authenticate(context.Background(), req)
+}
+
+func TestProviderAuthenticate_ClientKeyMetadata(t *testing.T) {
+	p := newProvider("", []string{"plain"}, normalizeClientKeys([]sdkconfig.ClientKey{{
+		Name:                      "team-a",
+		Key:                       "team-a-key",
+		AllowedModels:             []string{"claude-*"},
+		AllowedCredentialPrefixes: []string{"teamA", ""},
+		ExpiresAt:                 "2999-01-01",
+	}})...)
+
+	result, errAuth := authenticateWithKey(t, p, "team-a-key")
+	if errAuth != nil {
+		t.Fatalf("Authenticate() error = %v", errAuth)
+	}
+	if result.Principal != "team-a-key" {
+		t.Fatalf("Principal = %q, want %q", result.Principal, "team-a-key")
+	}
+	if got := result.Metadata[sdkaccess.MetadataKeyClientName]; got != "team-a" {
+		t.Fatalf("client name = %q, want %q", got, "team-a")
+	}
+	models, ok := sdkaccess.MetadataList(result.Metadata, sdkaccess.MetadataKeyAllowedModels)
+	if !ok || len(models) != 1 || models[0] != "claude-*" {
+		t.Fatalf("allowed models = %v (ok=%t), want [claude-*]", models, ok)
+	}
+	prefixes, ok := sdkaccess.MetadataList(result.Metadata, sdkaccess.MetadataKeyAllowedCredentialPrefixes)
+	if !ok || len(prefixes) != 2 || prefixes[0] != "teamA" || prefixes[1] != "" {
+		t.Fatalf("allowed prefixes = %q (ok=%t), want [teamA \"\"]", prefixes, ok)
+	}
+
+	result, errAuth = authenticateWithKey(t, p, "plain")
+	if errAuth != nil {
+		t.Fatalf("Authenticate(plain) error = %v", errAuth)
+	}
+	if _, ok = result.Metadata[sdkaccess.MetadataKeyAllowedModels]; ok {
+		t.Fatalf("plain api-keys entry should carry no restrictions, got %v", result.Metadata)
+	}
+}
+
+func TestProviderAuthenticate_HashedClientKey(t *testing.T) {
+	sum := sha256.Sum256([]byte("hashed-secret"))
+	p := newProvider("", nil, normalizeClientKeys([]sdkconfig.ClientKey{{
+		Name:    "hashed",
+		KeyHash: "sha256:" + hex.EncodeToString(sum[:]),
+	}})...)
+
+	if _, errAuth := authenticateWithKey(t, p, "hashed-secret"); errAuth != nil {
+		t.Fatalf("Authenticate() error = %v", errAuth)
+	}
+	if _, errAuth := authenticateWithKey(t, p, "wrong"); !sdkaccess.IsAuthErrorCode(errAuth, sdkaccess.AuthErrorCodeInvalidCredential) {
+		t.Fatalf("Authenticate(wrong) error = %v, want invalid credential", errAuth)
+	}
+}
+
+func TestProviderAuthenticate_ExpiredClientKey(t *testing.T) {
+	p := newProvider("", nil, normalizeClientKeys([]sdkconfig.ClientKey{{
+		Key:       "expired-key",
+		ExpiresAt: "2020-01-01T00:00:00Z",
+	}})...)
+
+	_, errAuth := authenticateWithKey(t, p, "expired-key")
+	if !sdkaccess.IsAuthErrorCode(errAuth, sdkaccess.AuthErrorCodeExpiredCredential) {
+		t.Fatalf("Authenticate() error = %v, want expired credential", errAuth)
+	}
+}
+
+func TestProviderAuthenticate_MonthlyBudgetExhausted(t *testing.T) {
+	p := newProvider("", nil, normalizeClientKeys([]sdkconfig.ClientKey{{
+		Key:                "budget-key",
+		MonthlyTokenBudget: 100,
+	}})...)
+
+	if _, errAuth := authenticateWithKey(t, p, "budget-key"); errAuth != nil {
+		t.Fatalf("Authenticate() error = %v", errAuth)
+	}
+	monthlyTokens.HandleUsage(context.Background(), coreusage.Record{
+		APIKey:      "budget-key",
+		RequestedAt: time.Now(),
+		Detail:      coreusage.Detail{TotalTokens: 150},
+	})
+
+	_, errAuth := authenticateWithKey(t, p, "budget-key")
+	if !sdkaccess.IsAuthErrorCode(errAuth, sdkaccess.AuthErrorCodeQuotaExceeded) {
+		t.Fatalf("Authenticate() error = %v, want quota exceeded", errAuth)
+	}
+	if errAuth.HTTPStatusCode() != http.StatusTooManyRequests {
+		t.Fatalf("HTTPStatusCode() = %d, want %d", errAuth.HTTPStatusCode(), http.StatusTooManyRequests)
+	}
+}
+
+func TestMonthlyUsage_ResetsOnNewMonth(t *testing.T) {
+	usage := &monthlyUsage{tokens: make(map[string]int64)}
+	january := time.Date(2026, time.January, 31, 12, 0, 0, 0, time.UTC)
+	usage.HandleUsage(context.Background(), coreusage.Record{APIKey: "k", RequestedAt: january, Detail: coreusage.Detail{TotalTokens: 10}})
+
+	if got := usage.used("k", january); got != 10 {
+		t.Fatalf("used(january) = %d, want 10", got)
+	}
+	if got := usage.used("k", january.AddDate(0, 0, 1)); got != 0 {
+		t.Fatalf("used(february) = %d, want 0", got)
+	}
+}
+
+func TestMonthlyUsage_SeedsFromUsageStore(t *testing.T) {
+	store, err := internalusage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	now := time.Now()
+	for _, record := range []internalusage.StoredRecord{
+		{APIKeyHash: internalusage.HashAPIKey("k"), RequestDetail: internalusage.RequestDetail{Timestamp: now, Tokens: internalusage.TokenStats{TotalTokens: 40}}},
+		{APIKeyHash: internalusage.HashAPIKey("k"), RequestDetail: internalusage.RequestDetail{Timestamp: now, Tokens: internalusage.TokenStats{TotalTokens: 99}, Hedged: true}},
+		{APIKeyHash: internalusage.HashAPIKey("k"), RequestDetail: internalusage.RequestDetail{Timestamp: now.AddDate(0, -1, 0), Tokens: internalusage.TokenStats{TotalTokens: 500}}},
+	} {
+		if errAppend := store.Append(context.Background(), record); errAppend != nil {
+			t.Fatalf("Append() error = %v", errAppend)
+		}
+	}
+
+	usage := &monthlyUsage{tokens: make(map[string]int64), store: func() internalusage.Store { return store }}
+	if got := usage.used("k", now); got != 40 {
+		t.Fatalf("used() = %d, want the 40 tokens stored this month", got)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 )
 
@@ -171,3 +172,28 @@ func TestResolveTokenForAuth_Antigravity_SkipsRefreshWhenTokenValid(t *testing.T
 		t.Fatalf("expected no refresh calls, got %d", callCount)
 	}
 }
+
+func TestAPICallTransportIgnoresEnvironmentProxy(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://127.0.0.1:9")
+	t.Setenv("HTTP_PROXY", "http://127.0.0.1:9")
+
+	h := &Handler{cfg: &config.Config{}}
+	rt, ok := h.apiCallTransport(&coreauth.Auth{Provider: "management-direct-test"}).(*http.Transport)
+	if !ok {
+		t.Fatalf("apiCallTransport() = %T, want *http.Transport", rt)
+	}
+	if rt.Proxy != nil {
+		proxyURL, _ := rt.Proxy(httptest.NewRequest(http.MethodGet, "https://api.example.com/v1/models", nil))
+		t.Fatalf("direct management transport has a proxy func (resolves to %v), want HTTPS_PROXY ignored", proxyURL)
+	}
+
+	h.cfg.ProxyURL = "http://proxy.example.com:8080"
+	proxied, ok := h.apiCallTransport(&coreauth.Auth{Provider: "management-direct-test"}).(*http.Transport)
+	if !ok || proxied.Proxy == nil {
+		t.Fatal("configured proxy-url was not applied")
+	}
+	proxyURL, _ := proxied.Proxy(httptest.NewRequest(http.MethodGet, "https://api.example.com/v1/models", nil))
+	if proxyURL == nil || proxyURL.Host != "proxy.example.com:8080" {
+		t.Fatalf("proxied transport resolves to %v, want the configured proxy", proxyURL)
+	}
+}
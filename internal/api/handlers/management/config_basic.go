@@ -286,6 +286,10 @@ func normalizeRoutingStrategy(strategy string) (string, bool) {
 		return "round-robin", true
 	case "fill-first", "fillfirst", "ff":
 		return "fill-first", true
+	case "least-latency", "leastlatency", "adaptive":
+		return "least-latency", true
+	case "weighted", "weighted-random":
+		return "weighted", true
 	default:
 		return "", false
 	}
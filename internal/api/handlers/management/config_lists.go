@@ -486,6 +486,126 @@ func (h *Handler) DeleteOpenAICompat(c *gin.Context) {
 	c.JSON(400, gin.H{"error": "missing name or index"})
 }
 
+// anthropic-compatibility: []AnthropicCompatibility
+func (h *Handler) GetAnthropicCompat(c *gin.Context) {
+	c.JSON(200, gin.H{"anthropic-compatibility": normalizedAnthropicCompatibilityEntries(h.cfg.AnthropicCompatibility)})
+}
+func (h *Handler) PutAnthropicCompat(c *gin.Context) {
+	data, err := c.GetRawData()
+	if err != nil {
+		c.JSON(400, gin.H{"error": "failed to read body"})
+		return
+	}
+	var arr []config.AnthropicCompatibility
+	if err = json.Unmarshal(data, &arr); err != nil {
+		var obj struct {
+			Items []config.AnthropicCompatibility `json:"items"`
+		}
+		if err2 := json.Unmarshal(data, &obj); err2 != nil || len(obj.Items) == 0 {
+			c.JSON(400, gin.H{"error": "invalid body"})
+			return
+		}
+		arr = obj.Items
+	}
+	for i := range arr {
+		normalizeAnthropicCompatibilityEntry(&arr[i])
+	}
+	h.cfg.AnthropicCompatibility = arr
+	h.cfg.SanitizeAnthropicCompatibility()
+	h.persist(c)
+}
+func (h *Handler) PatchAnthropicCompat(c *gin.Context) {
+	type anthropicCompatPatch struct {
+		Name          *string                                `json:"name"`
+		Prefix        *string                                `json:"prefix"`
+		BaseURL       *string                                `json:"base-url"`
+		APIKeyEntries *[]config.AnthropicCompatibilityAPIKey `json:"api-key-entries"`
+		Models        *[]config.AnthropicCompatibilityModel  `json:"models"`
+		Headers       *map[string]string                     `json:"headers"`
+	}
+	var body struct {
+		Name  *string               `json:"name"`
+		Index *int                  `json:"index"`
+		Value *anthropicCompatPatch `json:"value"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Value == nil {
+		c.JSON(400, gin.H{"error": "invalid body"})
+		return
+	}
+	targetIndex := -1
+	if body.Index != nil && *body.Index >= 0 && *body.Index < len(h.cfg.AnthropicCompatibility) {
+		targetIndex = *body.Index
+	}
+	if targetIndex == -1 && body.Name != nil {
+		match := strings.TrimSpace(*body.Name)
+		for i := range h.cfg.AnthropicCompatibility {
+			if h.cfg.AnthropicCompatibility[i].Name == match {
+				targetIndex = i
+				break
+			}
+		}
+	}
+	if targetIndex == -1 {
+		c.JSON(404, gin.H{"error": "item not found"})
+		return
+	}
+
+	entry := h.cfg.AnthropicCompatibility[targetIndex]
+	if body.Value.Name != nil {
+		entry.Name = strings.TrimSpace(*body.Value.Name)
+	}
+	if body.Value.Prefix != nil {
+		entry.Prefix = strings.TrimSpace(*body.Value.Prefix)
+	}
+	if body.Value.BaseURL != nil {
+		trimmed := strings.TrimSpace(*body.Value.BaseURL)
+		if trimmed == "" {
+			h.cfg.AnthropicCompatibility = append(h.cfg.AnthropicCompatibility[:targetIndex], h.cfg.AnthropicCompatibility[targetIndex+1:]...)
+			h.cfg.SanitizeAnthropicCompatibility()
+			h.persist(c)
+			return
+		}
+		entry.BaseURL = trimmed
+	}
+	if body.Value.APIKeyEntries != nil {
+		entry.APIKeyEntries = append([]config.AnthropicCompatibilityAPIKey(nil), (*body.Value.APIKeyEntries)...)
+	}
+	if body.Value.Models != nil {
+		entry.Models = append([]config.AnthropicCompatibilityModel(nil), (*body.Value.Models)...)
+	}
+	if body.Value.Headers != nil {
+		entry.Headers = config.NormalizeHeaders(*body.Value.Headers)
+	}
+	normalizeAnthropicCompatibilityEntry(&entry)
+	h.cfg.AnthropicCompatibility[targetIndex] = entry
+	h.cfg.SanitizeAnthropicCompatibility()
+	h.persist(c)
+}
+
+func (h *Handler) DeleteAnthropicCompat(c *gin.Context) {
+	if name := c.Query("name"); name != "" {
+		out := make([]config.AnthropicCompatibility, 0, len(h.cfg.AnthropicCompatibility))
+		for _, v := range h.cfg.AnthropicCompatibility {
+			if v.Name != name {
+				out = append(out, v)
+			}
+		}
+		h.cfg.AnthropicCompatibility = out
+		h.persist(c)
+		return
+	}
+	if idxStr := c.Query("index"); idxStr != "" {
+		var idx int
+		_, err := fmt.Sscanf(idxStr, "%d", &idx)
+		if err == nil && idx >= 0 && idx < len(h.cfg.AnthropicCompatibility) {
+			h.cfg.AnthropicCompatibility = append(h.cfg.AnthropicCompatibility[:idx], h.cfg.AnthropicCompatibility[idx+1:]...)
+			h.persist(c)
+			return
+		}
+	}
+	c.JSON(400, gin.H{"error": "missing name or index"})
+}
+
 // vertex-api-key: []VertexCompatKey
 func (h *Handler) GetVertexCompatKeys(c *gin.Context) {
 	c.JSON(200, gin.H{"vertex-api-key": h.cfg.VertexCompatAPIKey})
@@ -965,6 +1085,34 @@ func normalizedOpenAICompatibilityEntries(entries []config.OpenAICompatibility)
 	return out
 }
 
+func normalizeAnthropicCompatibilityEntry(entry *config.AnthropicCompatibility) {
+	if entry == nil {
+		return
+	}
+	entry.BaseURL = strings.TrimSpace(entry.BaseURL)
+	entry.Headers = config.NormalizeHeaders(entry.Headers)
+	for i := range entry.APIKeyEntries {
+		entry.APIKeyEntries[i].APIKey = strings.TrimSpace(entry.APIKeyEntries[i].APIKey)
+		entry.APIKeyEntries[i].ProxyURL = strings.TrimSpace(entry.APIKeyEntries[i].ProxyURL)
+	}
+}
+
+func normalizedAnthropicCompatibilityEntries(entries []config.AnthropicCompatibility) []config.AnthropicCompatibility {
+	if len(entries) == 0 {
+		return nil
+	}
+	out := make([]config.AnthropicCompatibility, len(entries))
+	for i := range entries {
+		copyEntry := entries[i]
+		if len(copyEntry.APIKeyEntries) > 0 {
+			copyEntry.APIKeyEntries = append([]config.AnthropicCompatibilityAPIKey(nil), copyEntry.APIKeyEntries...)
+		}
+		normalizeAnthropicCompatibilityEntry(&copyEntry)
+		out[i] = copyEntry
+	}
+	return out
+}
+
 func normalizeClaudeKey(entry *config.ClaudeKey) {
 	if entry == nil {
 		return
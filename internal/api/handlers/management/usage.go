@@ -2,7 +2,9 @@ package management
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -20,11 +22,51 @@ type usageImportPayload struct {
 	Usage   usage.StatisticsSnapshot `json:"usage"`
 }
 
-// GetUsageStatistics returns the in-memory request statistics snapshot.
+// maxUsageQueryRange bounds how much history one request aggregates from the usage store.
+const maxUsageQueryRange = 31 * 24 * time.Hour
+
+// GetUsageStatistics returns request statistics. Without a range the in-memory statistics are
+// used when they are enabled. With a range, or when only the persistent usage store holds data,
+// the snapshot is built from stored records of at most maxUsageQueryRange; an open "from" starts
+// that long before "to". The "from" and "to" query parameters accept RFC3339 or YYYY-MM-DD; a
+// date-only "to" includes that whole day.
 func (h *Handler) GetUsageStatistics(c *gin.Context) {
+	from, errFrom := parseUsageTime(c.Query("from"), false)
+	if errFrom != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+	to, errTo := parseUsageTime(c.Query("to"), true)
+	if errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return
+	}
+
 	var snapshot usage.StatisticsSnapshot
-	if h != nil && h.usageStats != nil {
+	ranged := !from.IsZero() || !to.IsZero()
+	inMemory := h != nil && h.usageStats != nil && usage.StatisticsEnabled()
+	if store := usage.ActiveStore(); store != nil && (ranged || !inMemory) {
+		if to.IsZero() {
+			to = time.Now()
+		}
+		if from.IsZero() {
+			from = to.Add(-maxUsageQueryRange)
+		}
+		if to.Sub(from) > maxUsageQueryRange {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("range exceeds %d days", int(maxUsageQueryRange/(24*time.Hour)))})
+			return
+		}
+		stored, err := usage.SnapshotFromStore(c.Request.Context(), store, from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to query usage store: %v", err)})
+			return
+		}
+		snapshot = stored
+	} else if h != nil && h.usageStats != nil {
 		snapshot = h.usageStats.Snapshot()
+		if !from.IsZero() || !to.IsZero() {
+			snapshot = usage.FilterSnapshot(snapshot, from, to)
+		}
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"usage":           snapshot,
@@ -32,6 +74,26 @@ func (h *Handler) GetUsageStatistics(c *gin.Context) {
 	})
 }
 
+// parseUsageTime parses a range bound. Date-only values resolve to the start of that UTC day, or
+// to the start of the following day when endOfDay is set so the bound stays exclusive.
+func parseUsageTime(raw string, endOfDay bool) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, raw); err == nil {
+		return parsed, nil
+	}
+	parsed, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		parsed = parsed.Add(24 * time.Hour)
+	}
+	return parsed, nil
+}
+
 // ExportUsageStatistics returns a complete usage snapshot for backup/migration.
 func (h *Handler) ExportUsageStatistics(c *gin.Context) {
 	var snapshot usage.StatisticsSnapshot
@@ -27,6 +27,8 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/managementasset"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/metrics"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
@@ -36,6 +38,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers/openai"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 )
@@ -51,6 +54,7 @@ type serverOptionConfig struct {
 	keepAliveEnabled     bool
 	keepAliveTimeout     time.Duration
 	keepAliveOnTimeout   func()
+	translatorPipeline   *sdktranslator.Pipeline
 }
 
 // ServerOption customises HTTP server construction.
@@ -111,6 +115,14 @@ func WithRequestLoggerFactory(factory func(*config.Config, string) logging.Reque
 	}
 }
 
+// WithTranslatorPipeline runs the middleware of p around request and response translation for
+// every request served by the API handlers.
+func WithTranslatorPipeline(p *sdktranslator.Pipeline) ServerOption {
+	return func(cfg *serverOptionConfig) {
+		cfg.translatorPipeline = p
+	}
+}
+
 // Server represents the main API server.
 // It encapsulates the Gin engine, HTTP server, handlers, and configuration.
 type Server struct {
@@ -203,6 +215,8 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 	// Add middleware
 	engine.Use(logging.GinLogrusLogger())
 	engine.Use(logging.GinLogrusRecovery())
+	engine.Use(metrics.GinMiddleware())
+	engine.Use(tracing.GinMiddleware())
 	for _, mw := range optionState.extraMiddleware {
 		engine.Use(mw)
 	}
@@ -246,6 +260,7 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 		envManagementSecret: envManagementSecret,
 		wsRoutes:            make(map[string]struct{}),
 	}
+	s.handlers.Pipeline = optionState.translatorPipeline
 	s.wsAuthEnabled.Store(cfg.WebsocketAuth)
 	// Save initial YAML snapshot
 	s.oldConfigYaml, _ = yaml.Marshal(cfg)
@@ -322,6 +337,11 @@ func (s *Server) setupRoutes() {
 		v1.GET("/models", s.unifiedModelsHandler(openaiHandlers, claudeCodeHandlers))
 		v1.POST("/chat/completions", openaiHandlers.ChatCompletions)
 		v1.POST("/completions", openaiHandlers.Completions)
+		v1.POST("/embeddings", openaiHandlers.Embeddings)
+		v1.POST("/images/generations", openaiHandlers.ImageGenerations)
+		v1.POST("/images/edits", openaiHandlers.ImageEdits)
+		v1.POST("/audio/transcriptions", openaiHandlers.AudioTranscriptions)
+		v1.POST("/audio/speech", openaiHandlers.AudioSpeech)
 		v1.POST("/messages", claudeCodeHandlers.ClaudeMessages)
 		v1.POST("/messages/count_tokens", claudeCodeHandlers.ClaudeCountTokens)
 		v1.GET("/responses", openaiResponsesHandlers.ResponsesWebsocket)
@@ -338,6 +358,10 @@ func (s *Server) setupRoutes() {
 		v1beta.GET("/models/*action", geminiHandlers.GeminiGetHandler)
 	}
 
+	// Prometheus metrics; responds 404 unless enabled without a dedicated listener. The labels name
+	// credentials and models, so the main listener requires a client API key.
+	s.engine.GET("/metrics", AuthMiddleware(s.accessManager), metrics.GinHandler)
+
 	// Root endpoint
 	s.engine.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -345,6 +369,11 @@ func (s *Server) setupRoutes() {
 			"endpoints": []string{
 				"POST /v1/chat/completions",
 				"POST /v1/completions",
+				"POST /v1/embeddings",
+				"POST /v1/images/generations",
+				"POST /v1/images/edits",
+				"POST /v1/audio/transcriptions",
+				"POST /v1/audio/speech",
 				"GET /v1/models",
 			},
 		})
@@ -596,6 +625,11 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.PATCH("/openai-compatibility", s.mgmt.PatchOpenAICompat)
 		mgmt.DELETE("/openai-compatibility", s.mgmt.DeleteOpenAICompat)
 
+		mgmt.GET("/anthropic-compatibility", s.mgmt.GetAnthropicCompat)
+		mgmt.PUT("/anthropic-compatibility", s.mgmt.PutAnthropicCompat)
+		mgmt.PATCH("/anthropic-compatibility", s.mgmt.PatchAnthropicCompat)
+		mgmt.DELETE("/anthropic-compatibility", s.mgmt.DeleteAnthropicCompat)
+
 		mgmt.GET("/vertex-api-key", s.mgmt.GetVertexCompatKeys)
 		mgmt.PUT("/vertex-api-key", s.mgmt.PutVertexCompatKeys)
 		mgmt.PATCH("/vertex-api-key", s.mgmt.PatchVertexCompatKey)
@@ -895,6 +929,10 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		usage.SetStatisticsEnabled(cfg.UsageStatisticsEnabled)
 	}
 
+	if oldCfg != nil && oldCfg.UsageStore.RetentionDays != cfg.UsageStore.RetentionDays {
+		usage.SetStoreRetention(time.Duration(cfg.UsageStore.RetentionDays) * 24 * time.Hour)
+	}
+
 	if s.requestLogger != nil && (oldCfg == nil || oldCfg.ErrorLogsMaxFiles != cfg.ErrorLogsMaxFiles) {
 		if setter, ok := s.requestLogger.(interface{ SetErrorLogsMaxFiles(int) }); ok {
 			setter.SetErrorLogsMaxFiles(cfg.ErrorLogsMaxFiles)
@@ -991,9 +1029,13 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		entry := cfg.OpenAICompatibility[i]
 		openAICompatCount += len(entry.APIKeyEntries)
 	}
+	anthropicCompatCount := 0
+	for i := range cfg.AnthropicCompatibility {
+		anthropicCompatCount += len(cfg.AnthropicCompatibility[i].APIKeyEntries)
+	}
 
-	total := authEntries + geminiAPIKeyCount + claudeAPIKeyCount + codexAPIKeyCount + vertexAICompatCount + openAICompatCount
-	fmt.Printf("server clients and configuration updated: %d clients (%d auth entries + %d Gemini API keys + %d Claude API keys + %d Codex keys + %d Vertex-compat + %d OpenAI-compat)\n",
+	total := authEntries + geminiAPIKeyCount + claudeAPIKeyCount + codexAPIKeyCount + vertexAICompatCount + openAICompatCount + anthropicCompatCount
+	fmt.Printf("server clients and configuration updated: %d clients (%d auth entries + %d Gemini API keys + %d Claude API keys + %d Codex keys + %d Vertex-compat + %d OpenAI-compat + %d Anthropic-compat)\n",
 		total,
 		authEntries,
 		geminiAPIKeyCount,
@@ -1001,6 +1043,7 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		codexAPIKeyCount,
 		vertexAICompatCount,
 		openAICompatCount,
+		anthropicCompatCount,
 	)
 }
 
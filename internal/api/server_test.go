@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -10,6 +11,7 @@ import (
 
 	gin "github.com/gin-gonic/gin"
 	proxyconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/metrics"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
@@ -109,3 +111,35 @@ func TestAmpProviderModelRoutes(t *testing.T) {
 		})
 	}
 }
+
+type headerKeyProvider struct{}
+
+func (headerKeyProvider) Identifier() string { return "test-header-key" }
+
+func (headerKeyProvider) Authenticate(_ context.Context, r *http.Request) (*sdkaccess.Result, *sdkaccess.AuthError) {
+	if r.Header.Get("Authorization") != "Bearer test-key" {
+		return nil, sdkaccess.NewNoCredentialsError()
+	}
+	return &sdkaccess.Result{Provider: "test-header-key", Principal: "test-key"}, nil
+}
+
+func TestMetricsRouteRequiresClientKey(t *testing.T) {
+	server := newTestServer(t)
+	server.accessManager.SetProviders([]sdkaccess.Provider{headerKeyProvider{}})
+	metrics.SetEnabled(true)
+	t.Cleanup(func() { metrics.SetEnabled(false) })
+
+	rr := httptest.NewRecorder()
+	server.engine.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("unauthenticated /metrics status = %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer test-key")
+	rr = httptest.NewRecorder()
+	server.engine.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("authenticated /metrics status = %d, want %d", rr.Code, http.StatusOK)
+	}
+}
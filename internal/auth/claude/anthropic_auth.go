@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/transport"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -66,6 +67,17 @@ func NewClaudeAuth(cfg *config.Config) *ClaudeAuth {
 	}
 }
 
+// NewAnthropicHttpClient creates an HTTP client that bypasses TLS fingerprinting
+// for Anthropic domains by using the pooled Firefox-fingerprint transport.
+// It accepts optional SDK configuration for proxy settings.
+func NewAnthropicHttpClient(cfg *config.SDKConfig) *http.Client {
+	key := transport.Key{TLSProfile: transport.TLSProfileFirefox, Provider: "claude"}
+	if cfg != nil {
+		key.ProxyURL = cfg.ProxyURL
+	}
+	return transport.Client(key, 0)
+}
+
 // GenerateAuthURL creates the OAuth authorization URL with PKCE.
 // This method generates a secure authorization URL including PKCE challenge codes
 // for the OAuth2 flow with Anthropic's API.
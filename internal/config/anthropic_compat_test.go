@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestSanitizeAnthropicCompatibility_DropsClashingNames(t *testing.T) {
+	cfg := &Config{
+		OpenAICompatibility: []OpenAICompatibility{
+			{Name: "OpenRouter", BaseURL: "https://openrouter.ai/api/v1"},
+		},
+		AnthropicCompatibility: []AnthropicCompatibility{
+			{Name: " Claude ", BaseURL: "https://relay.example.com"},
+			{Name: "codex", BaseURL: "https://relay.example.com"},
+			{Name: "openrouter", BaseURL: "https://openrouter.ai/api"},
+			{Name: " relay ", BaseURL: " https://relay.example.com "},
+			{Name: "empty"},
+		},
+	}
+
+	cfg.SanitizeAnthropicCompatibility()
+
+	if len(cfg.AnthropicCompatibility) != 1 {
+		t.Fatalf("expected 1 remaining provider, got %d: %+v", len(cfg.AnthropicCompatibility), cfg.AnthropicCompatibility)
+	}
+	if got := cfg.AnthropicCompatibility[0]; got.Name != "relay" || got.BaseURL != "https://relay.example.com" {
+		t.Fatalf("expected the trimmed relay provider, got name=%q base-url=%q", got.Name, got.BaseURL)
+	}
+}
@@ -47,6 +47,12 @@ type Config struct {
 	// Pprof config controls the optional pprof HTTP debug server.
 	Pprof PprofConfig `yaml:"pprof" json:"pprof"`
 
+	// Metrics config controls the Prometheus metrics endpoint.
+	Metrics MetricsConfig `yaml:"metrics" json:"metrics"`
+
+	// Tracing config controls OpenTelemetry trace export.
+	Tracing TracingConfig `yaml:"tracing" json:"tracing"`
+
 	// CommercialMode disables high-overhead HTTP middleware features to minimize per-request memory usage.
 	CommercialMode bool `yaml:"commercial-mode" json:"commercial-mode"`
 
@@ -64,6 +70,9 @@ type Config struct {
 	// UsageStatisticsEnabled toggles in-memory usage aggregation; when false, usage data is discarded.
 	UsageStatisticsEnabled bool `yaml:"usage-statistics-enabled" json:"usage-statistics-enabled"`
 
+	// UsageStore persists usage records so statistics survive restarts and can be queried by time range.
+	UsageStore UsageStoreConfig `yaml:"usage-store" json:"usage-store"`
+
 	// DisableCooling disables quota cooldown scheduling when true.
 	DisableCooling bool `yaml:"disable-cooling" json:"disable-cooling"`
 
@@ -72,6 +81,9 @@ type Config struct {
 	// MaxRetryInterval defines the maximum wait time in seconds before retrying a cooled-down credential.
 	MaxRetryInterval int `yaml:"max-retry-interval" json:"max-retry-interval"`
 
+	// Timeouts bounds the connect, TLS handshake, first-byte, idle and total phases of upstream requests.
+	Timeouts TimeoutsConfig `yaml:"timeouts,omitempty" json:"timeouts,omitempty"`
+
 	// QuotaExceeded defines the behavior when a quota is exceeded.
 	QuotaExceeded QuotaExceeded `yaml:"quota-exceeded" json:"quota-exceeded"`
 
@@ -97,6 +109,9 @@ type Config struct {
 	// OpenAICompatibility defines OpenAI API compatibility configurations for external providers.
 	OpenAICompatibility []OpenAICompatibility `yaml:"openai-compatibility" json:"openai-compatibility"`
 
+	// AnthropicCompatibility defines third-party providers that speak the Anthropic Messages API.
+	AnthropicCompatibility []AnthropicCompatibility `yaml:"anthropic-compatibility,omitempty" json:"anthropic-compatibility,omitempty"`
+
 	// VertexCompatAPIKey defines Vertex AI-compatible API key configurations for third-party providers.
 	// Used for services that use Vertex AI-style paths but with simple API key authentication.
 	VertexCompatAPIKey []VertexCompatKey `yaml:"vertex-api-key" json:"vertex-api-key"`
@@ -115,6 +130,10 @@ type Config struct {
 	// gemini-api-key, codex-api-key, claude-api-key, openai-compatibility, vertex-api-key, and ampcode.
 	OAuthModelAlias map[string][]OAuthModelAlias `yaml:"oauth-model-alias,omitempty" json:"oauth-model-alias,omitempty"`
 
+	// ModelFallbacks maps a requested model to an ordered list of models tried when every
+	// credential for the requested model is out of quota or cooling down past max-retry-interval.
+	ModelFallbacks map[string][]string `yaml:"model-fallbacks,omitempty" json:"model-fallbacks,omitempty"`
+
 	// Payload defines default and override rules for provider payload parameters.
 	Payload PayloadConfig `yaml:"payload" json:"payload"`
 
@@ -130,6 +149,19 @@ type ClaudeHeaderDefaults struct {
 	Timeout        string `yaml:"timeout" json:"timeout"`
 }
 
+// UsageStoreConfig configures the persistent usage record store.
+type UsageStoreConfig struct {
+	// Enable toggles writing usage records to the store.
+	Enable bool `yaml:"enable" json:"enable"`
+	// Backend selects the storage backend: "file" (default) or "postgres". The postgres backend
+	// reuses the PGSTORE_DSN connection and falls back to "file" when it is not configured.
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+	// Path is the directory used by the file backend. Defaults to "usage" under the auth directory.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// RetentionDays removes records older than this many days. Set to 0 to keep records forever.
+	RetentionDays int `yaml:"retention-days,omitempty" json:"retention-days,omitempty"`
+}
+
 // TLSConfig holds HTTPS server settings.
 type TLSConfig struct {
 	// Enable toggles HTTPS server mode.
@@ -148,6 +180,31 @@ type PprofConfig struct {
 	Addr string `yaml:"addr" json:"addr"`
 }
 
+// MetricsConfig holds Prometheus metrics endpoint settings.
+type MetricsConfig struct {
+	// Enable toggles the /metrics endpoint.
+	Enable bool `yaml:"enable" json:"enable"`
+	// Addr serves metrics on a separate host:port listener. When empty, /metrics is served
+	// on the main API server behind client API key authentication.
+	Addr string `yaml:"addr,omitempty" json:"addr,omitempty"`
+}
+
+// TracingConfig holds OpenTelemetry trace export settings.
+type TracingConfig struct {
+	// Enable toggles span creation and OTLP export.
+	Enable bool `yaml:"enable" json:"enable"`
+	// Endpoint is the OTLP/HTTP collector URL (e.g. "http://localhost:4318"). When empty, the
+	// standard OTEL_EXPORTER_OTLP_* environment variables apply.
+	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+	// ServiceName overrides the reported service.name resource attribute.
+	ServiceName string `yaml:"service-name,omitempty" json:"service-name,omitempty"`
+	// SampleRatio is the fraction of new traces sampled, in (0, 1]. Other values sample everything.
+	// Traces started by a sampled client traceparent are always kept.
+	SampleRatio float64 `yaml:"sample-ratio,omitempty" json:"sample-ratio,omitempty"`
+	// PropagateUpstream sends the traceparent header on upstream provider requests.
+	PropagateUpstream bool `yaml:"propagate-upstream,omitempty" json:"propagate-upstream,omitempty"`
+}
+
 // RemoteManagement holds management API configuration under 'remote-management'.
 type RemoteManagement struct {
 	// AllowRemote toggles remote (non-localhost) access to management API.
@@ -174,8 +231,48 @@ type QuotaExceeded struct {
 // RoutingConfig configures how credentials are selected for requests.
 type RoutingConfig struct {
 	// Strategy selects the credential selection strategy.
-	// Supported values: "round-robin" (default), "fill-first".
+	// Supported values: "round-robin" (default), "fill-first", "least-latency" (alias "adaptive"), "weighted".
+	// "least-latency" weights picks by per-credential time-to-first-byte and failure rate.
+	// "weighted" picks randomly within the best priority tier in proportion to each credential's weight.
 	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// SessionAffinity pins consecutive turns of a conversation to the same credential.
+	SessionAffinity SessionAffinityConfig `yaml:"session-affinity,omitempty" json:"session-affinity,omitempty"`
+
+	// Hedging races a second credential against a slow first one for matching models.
+	// The first rule whose pattern matches the requested model applies.
+	Hedging []HedgingRule `yaml:"hedging,omitempty" json:"hedging,omitempty"`
+}
+
+// HedgingRule enables hedged requests for a set of models. When the first credential has not
+// answered within the delay, the same request is sent on a second credential and whichever
+// answers first is used; the other is cancelled. Streaming requests answer with their first
+// chunk; non-streaming requests answer only with the complete response, so for them the delay
+// bounds the whole response time rather than the time to first byte.
+type HedgingRule struct {
+	// Models lists model name patterns; "*" matches any run of characters.
+	Models []string `yaml:"models" json:"models"`
+
+	// DelayMS is how long the first credential may go without a first chunk (streaming) or a
+	// complete response (non-streaming) before the hedge is launched. Rules with a delay <= 0
+	// are ignored.
+	DelayMS int `yaml:"delay-ms" json:"delay-ms"`
+}
+
+// SessionAffinityConfig configures sticky credential selection keyed by conversation.
+// The session key is taken from the configured client header, the Claude
+// metadata.user_id field, or the OpenAI prompt_cache_key field, in that order.
+type SessionAffinityConfig struct {
+	// Enable toggles session affinity.
+	Enable bool `yaml:"enable" json:"enable"`
+
+	// TTLSeconds is how long an idle session stays pinned to its credential.
+	// <= 0 uses the default of 3600 seconds.
+	TTLSeconds int `yaml:"ttl-seconds,omitempty" json:"ttl-seconds,omitempty"`
+
+	// Header names the client header carrying the session identifier.
+	// Defaults to "X-Session-ID" when empty.
+	Header string `yaml:"header,omitempty" json:"header,omitempty"`
 }
 
 // OAuthModelAlias defines a model ID alias for a specific channel.
@@ -317,6 +414,10 @@ type ClaudeKey struct {
 	// Higher values are preferred; defaults to 0.
 	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
 
+	// Weight controls the relative traffic share within a priority tier when the
+	// "weighted" routing strategy is active. Values <= 0 are treated as 1.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
 	// Prefix optionally namespaces models for this credential (e.g., "teamA/claude-sonnet-4").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -338,6 +439,9 @@ type ClaudeKey struct {
 
 	// Cloak configures request cloaking for non-Claude-Code clients.
 	Cloak *CloakConfig `yaml:"cloak,omitempty" json:"cloak,omitempty"`
+
+	// Timeouts overrides the global and provider upstream timeouts for this API key.
+	Timeouts *Timeouts `yaml:"timeouts,omitempty" json:"timeouts,omitempty"`
 }
 
 func (k ClaudeKey) GetAPIKey() string  { return k.APIKey }
@@ -365,6 +469,10 @@ type CodexKey struct {
 	// Higher values are preferred; defaults to 0.
 	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
 
+	// Weight controls the relative traffic share within a priority tier when the
+	// "weighted" routing strategy is active. Values <= 0 are treated as 1.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
 	// Prefix optionally namespaces models for this credential (e.g., "teamA/gpt-5-codex").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -386,6 +494,9 @@ type CodexKey struct {
 
 	// ExcludedModels lists model IDs that should be excluded for this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// Timeouts overrides the global and provider upstream timeouts for this API key.
+	Timeouts *Timeouts `yaml:"timeouts,omitempty" json:"timeouts,omitempty"`
 }
 
 func (k CodexKey) GetAPIKey() string  { return k.APIKey }
@@ -413,6 +524,10 @@ type GeminiKey struct {
 	// Higher values are preferred; defaults to 0.
 	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
 
+	// Weight controls the relative traffic share within a priority tier when the
+	// "weighted" routing strategy is active. Values <= 0 are treated as 1.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
 	// Prefix optionally namespaces models for this credential (e.g., "teamA/gemini-3-pro-preview").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -430,6 +545,9 @@ type GeminiKey struct {
 
 	// ExcludedModels lists model IDs that should be excluded for this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// Timeouts overrides the global and provider upstream timeouts for this API key.
+	Timeouts *Timeouts `yaml:"timeouts,omitempty" json:"timeouts,omitempty"`
 }
 
 func (k GeminiKey) GetAPIKey() string  { return k.APIKey }
@@ -457,6 +575,10 @@ type OpenAICompatibility struct {
 	// Higher values are preferred; defaults to 0.
 	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
 
+	// Weight controls the relative traffic share within a priority tier when the
+	// "weighted" routing strategy is active. Values <= 0 are treated as 1.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
 	// Prefix optionally namespaces model aliases for this provider (e.g., "teamA/kimi-k2").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -471,6 +593,9 @@ type OpenAICompatibility struct {
 
 	// Headers optionally adds extra HTTP headers for requests sent to this provider.
 	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// Timeouts overrides the global and provider upstream timeouts for this provider.
+	Timeouts *Timeouts `yaml:"timeouts,omitempty" json:"timeouts,omitempty"`
 }
 
 // OpenAICompatibilityAPIKey represents an API key configuration with optional proxy setting.
@@ -480,6 +605,9 @@ type OpenAICompatibilityAPIKey struct {
 
 	// ProxyURL overrides the global proxy setting for this API key if provided.
 	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
+
+	// Weight overrides the provider-level weight for this API key when > 0.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
 }
 
 // OpenAICompatibilityModel represents a model configuration for OpenAI compatibility,
@@ -495,6 +623,64 @@ type OpenAICompatibilityModel struct {
 func (m OpenAICompatibilityModel) GetName() string  { return m.Name }
 func (m OpenAICompatibilityModel) GetAlias() string { return m.Alias }
 
+// AnthropicCompatibility represents a third-party provider exposing the Anthropic Messages API
+// (/v1/messages). Requests reuse the Claude translators but none of the Claude Code specific
+// behaviour of claude-api-key entries (cloaking, header defaults, built-in model list).
+type AnthropicCompatibility struct {
+	// Name is the identifier for this provider; it is also the provider key models route to.
+	Name string `yaml:"name" json:"name"`
+
+	// Priority controls selection preference when multiple providers or credentials match.
+	// Higher values are preferred; defaults to 0.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// Weight controls the relative traffic share within a priority tier when the
+	// "weighted" routing strategy is active. Values <= 0 are treated as 1.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
+	// Prefix optionally namespaces model aliases for this provider (e.g., "teamA/claude-sonnet-4").
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// BaseURL is the base URL of the provider; requests are sent to BaseURL + "/v1/messages".
+	BaseURL string `yaml:"base-url" json:"base-url"`
+
+	// APIKeyEntries defines API keys with optional per-key proxy configuration.
+	APIKeyEntries []AnthropicCompatibilityAPIKey `yaml:"api-key-entries,omitempty" json:"api-key-entries,omitempty"`
+
+	// Models defines the model configurations including aliases for routing.
+	Models []AnthropicCompatibilityModel `yaml:"models" json:"models"`
+
+	// Headers optionally adds extra HTTP headers for requests sent to this provider.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// Timeouts overrides the global and provider upstream timeouts for this provider.
+	Timeouts *Timeouts `yaml:"timeouts,omitempty" json:"timeouts,omitempty"`
+}
+
+// AnthropicCompatibilityAPIKey represents an API key, sent as x-api-key, with an optional proxy.
+type AnthropicCompatibilityAPIKey struct {
+	// APIKey is the authentication key for accessing the provider.
+	APIKey string `yaml:"api-key" json:"api-key"`
+
+	// ProxyURL overrides the global proxy setting for this API key if provided.
+	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
+
+	// Weight overrides the provider-level weight for this API key when > 0.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+}
+
+// AnthropicCompatibilityModel maps a provider model name to the alias clients use.
+type AnthropicCompatibilityModel struct {
+	// Name is the actual model name used by the provider.
+	Name string `yaml:"name" json:"name"`
+
+	// Alias is the model name alias that clients will use to reference this model.
+	Alias string `yaml:"alias" json:"alias"`
+}
+
+func (m AnthropicCompatibilityModel) GetName() string  { return m.Name }
+func (m AnthropicCompatibilityModel) GetAlias() string { return m.Alias }
+
 // LoadConfig reads a YAML configuration file from the given path,
 // unmarshals it into a Config struct, applies environment variable overrides,
 // and returns it.
@@ -601,6 +787,11 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 		cfg.Pprof.Addr = DefaultPprofAddr
 	}
 
+	cfg.Metrics.Addr = strings.TrimSpace(cfg.Metrics.Addr)
+
+	cfg.Tracing.Endpoint = strings.TrimSpace(cfg.Tracing.Endpoint)
+	cfg.Tracing.ServiceName = strings.TrimSpace(cfg.Tracing.ServiceName)
+
 	if cfg.LogsMaxTotalSizeMB < 0 {
 		cfg.LogsMaxTotalSizeMB = 0
 	}
@@ -609,6 +800,12 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 		cfg.ErrorLogsMaxFiles = 10
 	}
 
+	cfg.UsageStore.Backend = strings.ToLower(strings.TrimSpace(cfg.UsageStore.Backend))
+	cfg.UsageStore.Path = strings.TrimSpace(cfg.UsageStore.Path)
+	if cfg.UsageStore.RetentionDays < 0 {
+		cfg.UsageStore.RetentionDays = 0
+	}
+
 	// Sanitize Gemini API key configuration and migrate legacy entries.
 	cfg.SanitizeGeminiKeys()
 
@@ -624,12 +821,18 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	// Sanitize OpenAI compatibility providers: drop entries without base-url
 	cfg.SanitizeOpenAICompatibility()
 
+	// Sanitize Anthropic compatibility providers: drop entries without base-url
+	cfg.SanitizeAnthropicCompatibility()
+
 	// Normalize OAuth provider model exclusion map.
 	cfg.OAuthExcludedModels = NormalizeOAuthExcludedModels(cfg.OAuthExcludedModels)
 
 	// Normalize global OAuth model name aliases.
 	cfg.SanitizeOAuthModelAlias()
 
+	// Normalize cross-model fallback chains.
+	cfg.SanitizeModelFallbacks()
+
 	// Validate raw payload rules and drop invalid entries.
 	cfg.SanitizePayloadRules()
 
@@ -745,6 +948,39 @@ func (cfg *Config) SanitizeOAuthModelAlias() {
 	cfg.OAuthModelAlias = out
 }
 
+// SanitizeModelFallbacks trims model names, drops empty chains and removes duplicate
+// or self-referencing fallback entries while preserving the configured order.
+func (cfg *Config) SanitizeModelFallbacks() {
+	if cfg == nil || len(cfg.ModelFallbacks) == 0 {
+		return
+	}
+	out := make(map[string][]string, len(cfg.ModelFallbacks))
+	for rawModel, chain := range cfg.ModelFallbacks {
+		model := strings.TrimSpace(rawModel)
+		if model == "" || len(chain) == 0 {
+			continue
+		}
+		seen := map[string]struct{}{strings.ToLower(model): {}}
+		clean := make([]string, 0, len(chain))
+		for _, entry := range chain {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			key := strings.ToLower(entry)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			clean = append(clean, entry)
+		}
+		if len(clean) > 0 {
+			out[model] = clean
+		}
+	}
+	cfg.ModelFallbacks = out
+}
+
 // SanitizeOpenAICompatibility removes OpenAI-compatibility provider entries that are
 // not actionable, specifically those missing a BaseURL. It trims whitespace before
 // evaluation and preserves the relative order of remaining entries.
@@ -768,6 +1004,59 @@ func (cfg *Config) SanitizeOpenAICompatibility() {
 	cfg.OpenAICompatibility = out
 }
 
+// builtinProviderKeys lists the provider keys served by built-in executors. A compatibility
+// provider named after one of them would replace that executor.
+var builtinProviderKeys = map[string]struct{}{
+	"aistudio":    {},
+	"antigravity": {},
+	"claude":      {},
+	"codex":       {},
+	"gemini":      {},
+	"gemini-cli":  {},
+	"iflow":       {},
+	"kimi":        {},
+	"qwen":        {},
+	"vertex":      {},
+}
+
+// SanitizeAnthropicCompatibility removes Anthropic-compatibility provider entries missing a
+// BaseURL or whose name clashes with a built-in provider or an OpenAI-compatibility provider,
+// logging a warning for the latter. It trims whitespace and preserves order for remaining
+// entries. Run it after SanitizeOpenAICompatibility.
+func (cfg *Config) SanitizeAnthropicCompatibility() {
+	if cfg == nil || len(cfg.AnthropicCompatibility) == 0 {
+		return
+	}
+	openAINames := make(map[string]struct{}, len(cfg.OpenAICompatibility))
+	for i := range cfg.OpenAICompatibility {
+		if name := strings.ToLower(strings.TrimSpace(cfg.OpenAICompatibility[i].Name)); name != "" {
+			openAINames[name] = struct{}{}
+		}
+	}
+	out := make([]AnthropicCompatibility, 0, len(cfg.AnthropicCompatibility))
+	for i := range cfg.AnthropicCompatibility {
+		e := cfg.AnthropicCompatibility[i]
+		e.Name = strings.TrimSpace(e.Name)
+		e.Prefix = normalizeModelPrefix(e.Prefix)
+		e.BaseURL = strings.TrimSpace(e.BaseURL)
+		e.Headers = NormalizeHeaders(e.Headers)
+		if e.BaseURL == "" {
+			continue
+		}
+		key := strings.ToLower(e.Name)
+		if _, clash := builtinProviderKeys[key]; clash {
+			log.Warnf("anthropic-compatibility: ignoring provider %q, its name is reserved for a built-in provider", e.Name)
+			continue
+		}
+		if _, clash := openAINames[key]; clash {
+			log.Warnf("anthropic-compatibility: ignoring provider %q, its name is already used by an openai-compatibility provider", e.Name)
+			continue
+		}
+		out = append(out, e)
+	}
+	cfg.AnthropicCompatibility = out
+}
+
 // SanitizeCodexKeys removes Codex API key entries missing a BaseURL.
 // It trims whitespace and preserves order for remaining entries.
 func (cfg *Config) SanitizeCodexKeys() {
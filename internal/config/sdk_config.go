@@ -9,6 +9,9 @@ type SDKConfig struct {
 	// ProxyURL is the URL of an optional proxy server to use for outbound requests.
 	ProxyURL string `yaml:"proxy-url" json:"proxy-url"`
 
+	// Transport sizes the pooled HTTP connections kept per proxy and provider.
+	Transport TransportConfig `yaml:"transport,omitempty" json:"transport,omitempty"`
+
 	// ForceModelPrefix requires explicit model prefixes (e.g., "teamA/gemini-3-pro-preview")
 	// to target prefixed credentials. When false, unprefixed model requests may use prefixed
 	// credentials as well.
@@ -20,6 +23,10 @@ type SDKConfig struct {
 	// APIKeys is a list of keys for authenticating clients to this proxy server.
 	APIKeys []string `yaml:"api-keys" json:"api-keys"`
 
+	// ClientKeys defines structured client API keys with per-key model, credential and budget restrictions.
+	// Entries are accepted alongside APIKeys.
+	ClientKeys []ClientKey `yaml:"client-keys,omitempty" json:"client-keys,omitempty"`
+
 	// PassthroughHeaders controls whether upstream response headers are forwarded to downstream clients.
 	// Default is false (disabled).
 	PassthroughHeaders bool `yaml:"passthrough-headers" json:"passthrough-headers"`
@@ -30,6 +37,107 @@ type SDKConfig struct {
 	// NonStreamKeepAliveInterval controls how often blank lines are emitted for non-streaming responses.
 	// <= 0 disables keep-alives. Value is in seconds.
 	NonStreamKeepAliveInterval int `yaml:"nonstream-keepalive-interval,omitempty" json:"nonstream-keepalive-interval,omitempty"`
+
+	// RateLimit throttles inbound requests per client API key.
+	RateLimit RateLimitConfig `yaml:"rate-limit,omitempty" json:"rate-limit,omitempty"`
+
+	// FanOut controls how requests for several choices (OpenAI n, Gemini candidateCount) are
+	// served by providers that only return one.
+	FanOut FanOutConfig `yaml:"fan-out,omitempty" json:"fan-out,omitempty"`
+
+	// Completions controls how legacy /v1/completions requests are rewritten for chat backends.
+	Completions CompletionsConfig `yaml:"completions,omitempty" json:"completions,omitempty"`
+}
+
+// TransportConfig holds connection pool settings for upstream HTTP transports. Zero values use
+// the defaults noted on each field.
+type TransportConfig struct {
+	// MaxIdleConns caps idle connections kept across all hosts of one transport. Default 100.
+	MaxIdleConns int `yaml:"max-idle-conns,omitempty" json:"max-idle-conns,omitempty"`
+
+	// MaxIdleConnsPerHost caps idle connections kept per upstream host. Default 32.
+	MaxIdleConnsPerHost int `yaml:"max-idle-conns-per-host,omitempty" json:"max-idle-conns-per-host,omitempty"`
+
+	// MaxConnsPerHost caps all connections per upstream host. Zero means unlimited.
+	MaxConnsPerHost int `yaml:"max-conns-per-host,omitempty" json:"max-conns-per-host,omitempty"`
+
+	// IdleConnTimeout is how long, in seconds, an idle connection stays pooled. Default 90.
+	IdleConnTimeout int `yaml:"idle-conn-timeout,omitempty" json:"idle-conn-timeout,omitempty"`
+
+	// DisableHTTP2 keeps upstream connections on HTTP/1.1 even when the upstream offers HTTP/2.
+	DisableHTTP2 bool `yaml:"disable-http2,omitempty" json:"disable-http2,omitempty"`
+}
+
+// ClientKey describes a client API key together with the restrictions applied to it.
+type ClientKey struct {
+	// Name is a human-readable label for the key, surfaced in access metadata.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+
+	// Key is the plaintext client API key. Either Key or KeyHash must be set.
+	Key string `yaml:"key,omitempty" json:"key,omitempty"`
+
+	// KeyHash is the hex-encoded SHA-256 digest of the key, optionally prefixed with "sha256:".
+	KeyHash string `yaml:"key-hash,omitempty" json:"key-hash,omitempty"`
+
+	// AllowedModels lists model name globs ('*' wildcard) the key may call. Empty allows all models.
+	AllowedModels []string `yaml:"allowed-models,omitempty" json:"allowed-models,omitempty"`
+
+	// AllowedCredentialPrefixes restricts routing to credentials with one of these prefixes.
+	// Use "" to include unprefixed credentials. Empty allows all credentials.
+	AllowedCredentialPrefixes []string `yaml:"allowed-credential-prefixes,omitempty" json:"allowed-credential-prefixes,omitempty"`
+
+	// ExpiresAt is the expiry as an RFC 3339 timestamp or YYYY-MM-DD date (end of day, UTC).
+	ExpiresAt string `yaml:"expires-at,omitempty" json:"expires-at,omitempty"`
+
+	// MonthlyTokenBudget caps tokens consumed per calendar month (UTC). Zero means unlimited.
+	MonthlyTokenBudget int64 `yaml:"monthly-token-budget,omitempty" json:"monthly-token-budget,omitempty"`
+}
+
+// RateLimitConfig holds per-client-key throttling limits. Zero disables a limit.
+type RateLimitConfig struct {
+	// RequestsPerMinute caps requests accepted per key within a sliding one-minute window.
+	RequestsPerMinute int `yaml:"requests-per-minute,omitempty" json:"requests-per-minute,omitempty"`
+
+	// TokensPerMinute caps upstream tokens consumed per key within a sliding one-minute window.
+	// Requests are rejected once the window is exhausted; the request that crosses the limit completes.
+	TokensPerMinute int `yaml:"tokens-per-minute,omitempty" json:"tokens-per-minute,omitempty"`
+
+	// MaxConcurrentStreams caps simultaneously open streaming responses per key.
+	MaxConcurrentStreams int `yaml:"max-concurrent-streams,omitempty" json:"max-concurrent-streams,omitempty"`
+
+	// Overrides replaces the limits above for specific client API keys.
+	Overrides []RateLimitOverride `yaml:"overrides,omitempty" json:"overrides,omitempty"`
+}
+
+// RateLimitOverride replaces the global rate limits for a single client API key.
+// Zero inherits the global value; a negative value removes the limit for the key.
+type RateLimitOverride struct {
+	// APIKey is the client API key the override applies to.
+	APIKey string `yaml:"api-key" json:"api-key"`
+
+	RequestsPerMinute    int `yaml:"requests-per-minute,omitempty" json:"requests-per-minute,omitempty"`
+	TokensPerMinute      int `yaml:"tokens-per-minute,omitempty" json:"tokens-per-minute,omitempty"`
+	MaxConcurrentStreams int `yaml:"max-concurrent-streams,omitempty" json:"max-concurrent-streams,omitempty"`
+}
+
+// FanOutConfig configures multiple-choice fan-out, which issues one upstream request per
+// requested choice and merges the results.
+type FanOutConfig struct {
+	// MaxChoices caps the choices a single request may ask for. <= 0 uses the default of 8.
+	MaxChoices int `yaml:"max-choices,omitempty" json:"max-choices,omitempty"`
+
+	// DistinctCredentials routes each choice to a different credential when several can serve
+	// the model, instead of leaving the choice to the routing strategy.
+	DistinctCredentials bool `yaml:"distinct-credentials,omitempty" json:"distinct-credentials,omitempty"`
+}
+
+// CompletionsConfig configures the conversion of legacy completions requests into chat requests.
+type CompletionsConfig struct {
+	// RawPromptModels lists model patterns ('*' wildcards) that receive the prompt as the only
+	// message, without the system instruction that makes chat models continue text. Use it for
+	// base or completion models behind chat endpoints. Requests with a suffix keep the
+	// instruction, since chat requests have no other way to describe the gap.
+	RawPromptModels []string `yaml:"raw-prompt-models,omitempty" json:"raw-prompt-models,omitempty"`
 }
 
 // StreamingConfig holds server streaming behavior configuration.
@@ -42,4 +150,11 @@ type StreamingConfig struct {
 	// to allow auth rotation / transient recovery.
 	// <= 0 disables bootstrap retries. Default is 0.
 	BootstrapRetries int `yaml:"bootstrap-retries,omitempty" json:"bootstrap-retries,omitempty"`
+
+	// ContinuationRetries controls how many times a stream that fails after bytes were sent may be
+	// continued on another credential, with the text already emitted handed back to the model.
+	// Only Chat Completions, Claude Messages and Gemini generateContent streams are continued;
+	// Responses API streams and streams that emitted no text or a tool call are not.
+	// <= 0 disables continuation. Default is 0.
+	ContinuationRetries int `yaml:"continuation-retries,omitempty" json:"continuation-retries,omitempty"`
 }
@@ -0,0 +1,73 @@
+package config
+
+import "strings"
+
+// Timeouts bounds the phases of an upstream request. Every value is in seconds; 0 leaves the
+// phase to the next level (credential, then provider, then global) or unbounded when no level
+// sets it.
+type Timeouts struct {
+	// Connect bounds establishing the TCP connection (including the proxy hop).
+	Connect int `yaml:"connect,omitempty" json:"connect,omitempty"`
+
+	// TLSHandshake bounds the TLS handshake with the upstream.
+	TLSHandshake int `yaml:"tls-handshake,omitempty" json:"tls-handshake,omitempty"`
+
+	// FirstByte bounds the time from sending the request to receiving the first response body byte.
+	FirstByte int `yaml:"first-byte,omitempty" json:"first-byte,omitempty"`
+
+	// Idle bounds the gap between two chunks of a response body. A stream that stalls longer
+	// fails with a retriable timeout error.
+	Idle int `yaml:"idle,omitempty" json:"idle,omitempty"`
+
+	// Total bounds the whole request, including reading the response body.
+	Total int `yaml:"total,omitempty" json:"total,omitempty"`
+}
+
+// TimeoutsConfig holds the global upstream timeouts and their per-provider overrides.
+type TimeoutsConfig struct {
+	Timeouts `yaml:",inline"`
+
+	// Providers overrides the global timeouts for a provider key such as "claude", "gemini-cli"
+	// or the lowercased name of an openai-compatibility provider.
+	Providers map[string]Timeouts `yaml:"providers,omitempty" json:"providers,omitempty"`
+}
+
+// Override returns t with every phase that o sets replaced by o's value.
+func (t Timeouts) Override(o Timeouts) Timeouts {
+	if o.Connect > 0 {
+		t.Connect = o.Connect
+	}
+	if o.TLSHandshake > 0 {
+		t.TLSHandshake = o.TLSHandshake
+	}
+	if o.FirstByte > 0 {
+		t.FirstByte = o.FirstByte
+	}
+	if o.Idle > 0 {
+		t.Idle = o.Idle
+	}
+	if o.Total > 0 {
+		t.Total = o.Total
+	}
+	return t
+}
+
+// IsZero reports whether no phase is bounded.
+func (t Timeouts) IsZero() bool {
+	return t == Timeouts{}
+}
+
+// ForProvider returns the global timeouts overridden by the entry for provider, if any.
+func (c TimeoutsConfig) ForProvider(provider string) Timeouts {
+	out := c.Timeouts
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if provider == "" {
+		return out
+	}
+	for name, override := range c.Providers {
+		if strings.ToLower(strings.TrimSpace(name)) == provider {
+			return out.Override(override)
+		}
+	}
+	return out
+}
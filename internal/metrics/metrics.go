@@ -0,0 +1,242 @@
+// Package metrics exposes Prometheus metrics for the proxy: inbound HTTP traffic, upstream
+// executions, token usage, credential health and the usage queue.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+const namespace = "cliproxy"
+
+// Auth states reported by the credential gauges.
+const (
+	AuthStateActive   = "active"
+	AuthStateCooldown = "cooldown"
+	AuthStateDisabled = "disabled"
+)
+
+// AuthStateCount is the number of credentials (or credential models) in a state for a provider.
+type AuthStateCount struct {
+	Provider string
+	State    string
+	Count    int
+}
+
+// AuthStateSource reports the current credential states. Auths holds one entry per credential and
+// Models one entry per credential model state.
+type AuthStateSource func() (auths []AuthStateCount, models []AuthStateCount)
+
+var (
+	registry = prometheus.NewRegistry()
+	enabled  atomic.Bool
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Inbound HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "code"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Inbound HTTP request duration, including the full body of streamed responses.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"method", "route"})
+	upstreamRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_requests_total",
+		Help:      "Upstream execution attempts by provider, model and result.",
+	}, []string{"provider", "model", "result"})
+	upstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Duration of non-streaming upstream executions.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"provider", "model"})
+	timeToFirstToken = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "stream_time_to_first_token_seconds",
+		Help:      "Time from dispatching a streaming upstream request to its first chunk.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 4, 8, 15, 30, 60},
+	}, []string{"provider", "model"})
+	tokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tokens_total",
+		Help:      "Tokens consumed by provider, model, credential index and token type.",
+	}, []string{"provider", "model", "auth_index", "type"})
+	refreshFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_refresh_failures_total",
+		Help:      "Failed credential refresh attempts by provider.",
+	}, []string{"provider"})
+
+	authStates = &authStateCollector{
+		auths: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "auths"),
+			"Credentials by provider and state.", []string{"provider", "state"}, nil),
+		models: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "auth_model_states"),
+			"Per-model credential states by provider.", []string{"provider", "state"}, nil),
+	}
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		upstreamRequests,
+		upstreamDuration,
+		timeToFirstToken,
+		tokens,
+		refreshFailures,
+		authStates,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "usage_queue_depth",
+			Help:      "Usage records waiting to be delivered to usage plugins.",
+		}, func() float64 { return float64(coreusage.DefaultManager().QueueDepth()) }),
+	)
+	coreusage.RegisterPlugin(usagePlugin{})
+}
+
+// SetEnabled toggles serving metrics on the main API listener.
+func SetEnabled(value bool) { enabled.Store(value) }
+
+// Enabled reports whether metrics are served on the main API listener.
+func Enabled() bool { return enabled.Load() }
+
+// Handler returns the HTTP handler exposing the metrics registry.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// GinHandler serves metrics on the main API listener while they are enabled there.
+func GinHandler(c *gin.Context) {
+	if !enabled.Load() {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	Handler().ServeHTTP(c.Writer, c.Request)
+}
+
+// GinMiddleware records inbound request counts and durations. Routes are labelled by their
+// registered pattern so path parameters do not inflate cardinality.
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ResultSuccess is the upstream result label of successful executions.
+const ResultSuccess = "success"
+
+// ObserveUpstream records one upstream execution attempt. result is ResultSuccess or the HTTP
+// status (or "error" when unknown) of the failure. Latency is only observed for successful
+// attempts; for streams it is the time to first chunk.
+func ObserveUpstream(provider, model, result string, stream bool, latency time.Duration) {
+	provider = labelOrUnknown(provider)
+	model = labelOrUnknown(model)
+	upstreamRequests.WithLabelValues(provider, model, labelOrUnknown(result)).Inc()
+	if result != ResultSuccess || latency <= 0 {
+		return
+	}
+	if stream {
+		timeToFirstToken.WithLabelValues(provider, model).Observe(latency.Seconds())
+		return
+	}
+	upstreamDuration.WithLabelValues(provider, model).Observe(latency.Seconds())
+}
+
+// IncRefreshFailure counts a failed credential refresh.
+func IncRefreshFailure(provider string) {
+	refreshFailures.WithLabelValues(labelOrUnknown(provider)).Inc()
+}
+
+// SetAuthStateSource installs the function queried for credential state gauges on each scrape.
+func SetAuthStateSource(source AuthStateSource) {
+	authStates.mu.Lock()
+	authStates.source = source
+	authStates.mu.Unlock()
+}
+
+type authStateCollector struct {
+	mu     sync.Mutex
+	source AuthStateSource
+	auths  *prometheus.Desc
+	models *prometheus.Desc
+}
+
+func (c *authStateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.auths
+	ch <- c.models
+}
+
+func (c *authStateCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	source := c.source
+	c.mu.Unlock()
+	if source == nil {
+		return
+	}
+	auths, models := source()
+	emitStateCounts(ch, c.auths, auths)
+	emitStateCounts(ch, c.models, models)
+}
+
+func emitStateCounts(ch chan<- prometheus.Metric, desc *prometheus.Desc, counts []AuthStateCount) {
+	merged := make(map[[2]string]int, len(counts))
+	for _, count := range counts {
+		merged[[2]string{labelOrUnknown(count.Provider), count.State}] += count.Count
+	}
+	for key, value := range merged {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(value), key[0], key[1])
+	}
+}
+
+type usagePlugin struct{}
+
+// HandleUsage implements coreusage.Plugin.
+func (usagePlugin) HandleUsage(_ context.Context, record coreusage.Record) {
+	if record.Hedged {
+		return
+	}
+	provider := labelOrUnknown(record.Provider)
+	model := labelOrUnknown(record.Model)
+	authIndex := record.AuthIndex
+	add := func(kind string, value int64) {
+		if value > 0 {
+			tokens.WithLabelValues(provider, model, authIndex, kind).Add(float64(value))
+		}
+	}
+	add("input", record.Detail.InputTokens)
+	add("output", record.Detail.OutputTokens)
+	add("reasoning", record.Detail.ReasoningTokens)
+	add("cached", record.Detail.CachedTokens)
+}
+
+func labelOrUnknown(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
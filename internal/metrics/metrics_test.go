@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("scrape status = %d, want 200", rec.Code)
+	}
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatalf("read scrape body: %v", err)
+	}
+	return string(body)
+}
+
+func TestObserveUpstreamRecordsCountsAndLatency(t *testing.T) {
+	ObserveUpstream("claude", "claude-test-model", ResultSuccess, true, 300*time.Millisecond)
+	ObserveUpstream("claude", "claude-test-model", "429", false, time.Second)
+
+	body := scrape(t)
+	for _, want := range []string{
+		`cliproxy_upstream_requests_total{model="claude-test-model",provider="claude",result="success"} 1`,
+		`cliproxy_upstream_requests_total{model="claude-test-model",provider="claude",result="429"} 1`,
+		`cliproxy_stream_time_to_first_token_seconds_count{model="claude-test-model",provider="claude"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("scrape output missing %q", want)
+		}
+	}
+	if strings.Contains(body, `cliproxy_upstream_request_duration_seconds_count{model="claude-test-model"`) {
+		t.Fatalf("failed attempts must not be observed in the duration histogram")
+	}
+}
+
+func TestAuthStateSourceIsCollectedOnScrape(t *testing.T) {
+	SetAuthStateSource(func() ([]AuthStateCount, []AuthStateCount) {
+		return []AuthStateCount{
+			{Provider: "gemini", State: AuthStateActive, Count: 1},
+			{Provider: "gemini", State: AuthStateActive, Count: 1},
+			{Provider: "gemini", State: AuthStateDisabled, Count: 1},
+		}, []AuthStateCount{
+			{Provider: "gemini", State: AuthStateCooldown, Count: 1},
+		}
+	})
+	defer SetAuthStateSource(nil)
+
+	body := scrape(t)
+	for _, want := range []string{
+		`cliproxy_auths{provider="gemini",state="active"} 2`,
+		`cliproxy_auths{provider="gemini",state="disabled"} 1`,
+		`cliproxy_auth_model_states{provider="gemini",state="cooldown"} 1`,
+		`cliproxy_usage_queue_depth`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("scrape output missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package misc
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"strconv"
+	"strings"
+)
+
+// audioFormatMimeTypes maps OpenAI input_audio formats (and common file extensions) to the MIME
+// types Gemini accepts for inline audio.
+var audioFormatMimeTypes = map[string]string{
+	"wav":   "audio/wav",
+	"mp3":   "audio/mp3",
+	"mpga":  "audio/mp3",
+	"mpeg":  "audio/mp3",
+	"aac":   "audio/aac",
+	"m4a":   "audio/aac",
+	"ogg":   "audio/ogg",
+	"opus":  "audio/ogg",
+	"flac":  "audio/flac",
+	"aiff":  "audio/aiff",
+	"webm":  "audio/webm",
+	"pcm16": PCM16MimeType,
+}
+
+// PCM16MimeType describes the 24 kHz, 16-bit little-endian mono PCM that Gemini speech models
+// produce and that OpenAI calls "pcm16".
+const PCM16MimeType = "audio/L16;codec=pcm;rate=24000"
+
+// AudioMimeType returns the MIME type for an OpenAI input_audio format such as "wav" or "mp3".
+// Unknown formats are passed on as audio/<format>.
+func AudioMimeType(format string) string {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if mimeType, ok := audioFormatMimeTypes[format]; ok {
+		return mimeType
+	}
+	return "audio/" + format
+}
+
+// AudioFormat returns the OpenAI input_audio format for an audio MIME type, or "" when the MIME
+// type is not audio.
+func AudioFormat(mimeType string) string {
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	base, _, _ := strings.Cut(mimeType, ";")
+	switch base {
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		return "wav"
+	case "audio/mp3", "audio/mpeg":
+		return "mp3"
+	case "audio/aac", "audio/x-aac", "audio/mp4":
+		return "aac"
+	case "audio/flac", "audio/x-flac":
+		return "flac"
+	case "audio/aiff", "audio/x-aiff":
+		return "aiff"
+	case "audio/l16", "audio/pcm":
+		return "pcm16"
+	}
+	if format, ok := strings.CutPrefix(base, "audio/"); ok {
+		return format
+	}
+	return ""
+}
+
+// IsAudioMimeType reports whether mimeType names an audio format.
+func IsAudioMimeType(mimeType string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(mimeType)), "audio/")
+}
+
+// PCMSampleRate reads the rate parameter of a raw PCM MIME type such as PCM16MimeType. It
+// returns 0 when mimeType is not raw PCM.
+func PCMSampleRate(mimeType string) int {
+	if AudioFormat(mimeType) != "pcm16" {
+		return 0
+	}
+	_, params, _ := strings.Cut(mimeType, ";")
+	for _, param := range strings.Split(params, ";") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(param), "rate="); ok {
+			if rate, err := strconv.Atoi(value); err == nil && rate > 0 {
+				return rate
+			}
+		}
+	}
+	return 24000
+}
+
+// WAVFromPCM wraps 16-bit little-endian mono PCM samples in a WAV container.
+func WAVFromPCM(pcm []byte, sampleRate int) []byte {
+	const (
+		channels      = 1
+		bitsPerSample = 16
+	)
+	blockAlign := channels * bitsPerSample / 8
+	out := make([]byte, 44, 44+len(pcm))
+	copy(out[0:], "RIFF")
+	binary.LittleEndian.PutUint32(out[4:], uint32(36+len(pcm)))
+	copy(out[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(out[16:], 16)
+	binary.LittleEndian.PutUint16(out[20:], 1)
+	binary.LittleEndian.PutUint16(out[22:], channels)
+	binary.LittleEndian.PutUint32(out[24:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(out[28:], uint32(sampleRate*blockAlign))
+	binary.LittleEndian.PutUint16(out[32:], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(out[34:], bitsPerSample)
+	copy(out[36:], "data")
+	binary.LittleEndian.PutUint32(out[40:], uint32(len(pcm)))
+	return append(out, pcm...)
+}
+
+// openAIVoices maps the OpenAI voice names to Gemini prebuilt voices of a similar character.
+var openAIVoices = map[string]string{
+	"alloy":   "Zephyr",
+	"ash":     "Charon",
+	"ballad":  "Orus",
+	"coral":   "Leda",
+	"echo":    "Puck",
+	"fable":   "Aoede",
+	"nova":    "Kore",
+	"onyx":    "Fenrir",
+	"sage":    "Sulafat",
+	"shimmer": "Achernar",
+	"verse":   "Iapetus",
+}
+
+// GeminiVoice returns the Gemini prebuilt voice for an OpenAI voice name. Other names are
+// assumed to be Gemini voices already and are returned unchanged.
+func GeminiVoice(voice string) string {
+	if mapped, ok := openAIVoices[strings.ToLower(strings.TrimSpace(voice))]; ok {
+		return mapped
+	}
+	return voice
+}
+
+// OpenAIAudioData joins the base64 audio parts of one Gemini answer into the data of an OpenAI
+// message.audio object. Raw PCM is wrapped in a WAV container when the client asked for "wav".
+func OpenAIAudioData(parts []string, mimeType, format string) string {
+	if len(parts) == 1 && (format != "wav" || PCMSampleRate(mimeType) == 0) {
+		return parts[0]
+	}
+	var audio []byte
+	for _, part := range parts {
+		decoded, err := base64.StdEncoding.DecodeString(part)
+		if err != nil {
+			continue
+		}
+		audio = append(audio, decoded...)
+	}
+	if rate := PCMSampleRate(mimeType); rate > 0 && format == "wav" {
+		audio = WAVFromPCM(audio, rate)
+	}
+	return base64.StdEncoding.EncodeToString(audio)
+}
+
+// OpenAIVoice returns the OpenAI voice matching a Gemini prebuilt voice, falling back to "alloy"
+// for voices without a counterpart.
+func OpenAIVoice(voice string) string {
+	for openAI, gemini := range openAIVoices {
+		if strings.EqualFold(gemini, strings.TrimSpace(voice)) {
+			return openAI
+		}
+	}
+	return "alloy"
+}
@@ -0,0 +1,121 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestAnthropicCompatExecutor_ExecuteSendsPlainMessagesRequest(t *testing.T) {
+	var gotPath string
+	var gotHeader http.Header
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotHeader = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","model":"relay-model","role":"assistant","content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	executor := NewAnthropicCompatExecutor("relay", &config.Config{})
+	auth := &cliproxyauth.Auth{Provider: "relay", Attributes: map[string]string{
+		"api_key":         "key-123",
+		"base_url":        server.URL + "/",
+		"header:X-Tenant": "acme",
+	}}
+	payload := []byte(`{"model":"relay-model","system":"be brief","messages":[{"role":"user","content":[{"type":"text","text":"hi"}]}]}`)
+	resp, err := executor.Execute(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "relay-model",
+		Payload: payload,
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("claude")})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if gotPath != "/v1/messages" {
+		t.Fatalf("path = %q, want /v1/messages", gotPath)
+	}
+	if got := gotHeader.Get("x-api-key"); got != "key-123" {
+		t.Fatalf("x-api-key = %q, want key-123", got)
+	}
+	if got := gotHeader.Get("anthropic-version"); got != anthropicAPIVersion {
+		t.Fatalf("anthropic-version = %q, want %q", got, anthropicAPIVersion)
+	}
+	if got := gotHeader.Get("X-Tenant"); got != "acme" {
+		t.Fatalf("X-Tenant = %q, want the configured header", got)
+	}
+	if got := gotHeader.Get("Authorization"); got != "" {
+		t.Fatalf("Authorization = %q, want none", got)
+	}
+	if got := gjson.GetBytes(gotBody, "system").String(); got != "be brief" {
+		t.Fatalf("system = %s, want the client's system prompt untouched", gjson.GetBytes(gotBody, "system").Raw)
+	}
+	if gjson.GetBytes(gotBody, "metadata.user_id").Exists() {
+		t.Fatalf("request carries a Claude Code user id: %s", gotBody)
+	}
+	if got := gjson.GetBytes(resp.Payload, "content.0.text").String(); got != "ok" {
+		t.Fatalf("response text = %q, want ok", got)
+	}
+}
+
+func TestAnthropicCompatExecutor_ExecuteStreamPassesClaudeEventsThrough(t *testing.T) {
+	const events = "event: message_start\n" +
+		"data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"usage\":{\"input_tokens\":1,\"output_tokens\":0}}}\n" +
+		"\n" +
+		"event: message_stop\n" +
+		"data: {\"type\":\"message_stop\"}\n" +
+		"\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !gjson.GetBytes(body, "stream").Bool() {
+			t.Errorf("upstream request is not streamed")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, events)
+	}))
+	defer server.Close()
+
+	executor := NewAnthropicCompatExecutor("relay", &config.Config{})
+	auth := &cliproxyauth.Auth{Provider: "relay", Attributes: map[string]string{"base_url": server.URL}}
+	result, err := executor.ExecuteStream(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "relay-model",
+		Payload: []byte(`{"model":"relay-model","stream":true,"messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("claude"), Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	var got strings.Builder
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("stream chunk error = %v", chunk.Err)
+		}
+		got.Write(chunk.Payload)
+	}
+	if got.String() != events {
+		t.Fatalf("stream =\n%s\nwant\n%s", got.String(), events)
+	}
+}
+
+func TestAnthropicCompatExecutor_ExecuteRejectsEmbeddings(t *testing.T) {
+	executor := NewAnthropicCompatExecutor("relay", &config.Config{})
+	_, err := executor.Execute(context.Background(), &cliproxyauth.Auth{Attributes: map[string]string{}}, cliproxyexecutor.Request{
+		Model:   "relay-model",
+		Payload: []byte(`{"input":"hi"}`),
+	}, cliproxyexecutor.Options{Alt: "embeddings", SourceFormat: sdktranslator.FromString("openai")})
+	var status statusErr
+	if !errors.As(err, &status) || status.StatusCode() != http.StatusNotImplemented {
+		t.Fatalf("Execute() error = %v, want 501", err)
+	}
+}
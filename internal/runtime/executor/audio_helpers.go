@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Audio requests reach the OpenAI-compatible executor as non-streaming executions with Alt set to
+// "audio/transcriptions" or "audio/speech". Transcription uploads travel through the auth manager
+// as JSON, {"file":{"filename","content_type","data"},"fields":[{"name","value"}]} with the file
+// base64-encoded, and are rebuilt into a multipart form before they go upstream. Every other
+// executor rejects audio alts with 501, as it does embeddings it cannot serve.
+
+func isAudioAlt(alt string) bool {
+	return strings.HasPrefix(alt, "audio/")
+}
+
+// openAIAudioRequest builds the upstream body for an audio alt and returns it with its content type.
+func openAIAudioRequest(alt, model string, payload []byte) (body []byte, contentType string, err error) {
+	if alt != "audio/transcriptions" {
+		body, _ = sjson.SetBytes(bytes.Clone(payload), "model", model)
+		return body, "application/json", nil
+	}
+
+	root := gjson.ParseBytes(payload)
+	file := root.Get("file")
+	data, errDecode := base64.StdEncoding.DecodeString(file.Get("data").String())
+	if errDecode != nil || len(data) == 0 {
+		return nil, "", statusErr{code: http.StatusBadRequest, msg: "invalid transcription request: file is required"}
+	}
+
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	_ = form.WriteField("model", model)
+	for _, field := range root.Get("fields").Array() {
+		name := field.Get("name").String()
+		if name == "" || name == "model" || name == "file" {
+			continue
+		}
+		_ = form.WriteField(name, field.Get("value").String())
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": "file", "filename": file.Get("filename").String()}))
+	fileType := file.Get("content_type").String()
+	if fileType == "" {
+		fileType = "application/octet-stream"
+	}
+	header.Set("Content-Type", fileType)
+	part, errPart := form.CreatePart(header)
+	if errPart != nil {
+		return nil, "", errPart
+	}
+	if _, errWrite := part.Write(data); errWrite != nil {
+		return nil, "", errWrite
+	}
+	if errClose := form.Close(); errClose != nil {
+		return nil, "", errClose
+	}
+	return buf.Bytes(), form.FormDataContentType(), nil
+}
@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+func TestOpenAICompatExecutorTranscriptionRebuildsMultipart(t *testing.T) {
+	var gotPath, gotModel, gotLanguage, gotFilename, gotFile string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm: %v", err)
+			return
+		}
+		gotModel = r.FormValue("model")
+		gotLanguage = r.FormValue("language")
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("FormFile: %v", err)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		gotFilename, gotFile = header.Filename, string(data)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"text":"hello","usage":{"type":"tokens","input_tokens":5,"output_tokens":1,"total_tokens":6}}`))
+	}))
+	defer server.Close()
+
+	executor := NewOpenAICompatExecutor("openai-compatibility", &config.Config{})
+	auth := &cliproxyauth.Auth{Attributes: map[string]string{
+		"base_url": server.URL + "/v1",
+		"api_key":  "test",
+	}}
+	payload := []byte(`{"model":"whisper-alias","file":{"filename":"note.mp3","content_type":"audio/mpeg","data":"YXVkaW8="},"fields":[{"name":"model","value":"whisper-alias"},{"name":"language","value":"de"}]}`)
+	resp, err := executor.Execute(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "whisper-1",
+		Payload: payload,
+	}, cliproxyexecutor.Options{
+		SourceFormat: sdktranslator.FromString("openai"),
+		Alt:          "audio/transcriptions",
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if gotPath != "/v1/audio/transcriptions" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if gotModel != "whisper-1" || gotLanguage != "de" {
+		t.Fatalf("form model = %q, language = %q", gotModel, gotLanguage)
+	}
+	if gotFilename != "note.mp3" || gotFile != "audio" {
+		t.Fatalf("file = %q %q", gotFilename, gotFile)
+	}
+	if string(resp.Payload) != `{"text":"hello","usage":{"type":"tokens","input_tokens":5,"output_tokens":1,"total_tokens":6}}` {
+		t.Fatalf("payload = %s", resp.Payload)
+	}
+}
+
+func TestExecutorsRejectAudioRequests(t *testing.T) {
+	cfg := &config.Config{}
+	executors := map[string]cliproxyauth.ProviderExecutor{
+		"aistudio":         NewAIStudioExecutor(cfg, "aistudio", nil),
+		"anthropic-compat": NewAnthropicCompatExecutor("gateway", cfg),
+		"antigravity":      NewAntigravityExecutor(cfg),
+		"claude":           NewClaudeExecutor(cfg),
+		"codex":            NewCodexExecutor(cfg),
+		"codex-websockets": NewCodexWebsocketsExecutor(cfg),
+		"gemini":           NewGeminiExecutor(cfg),
+		"gemini-cli":       NewGeminiCLIExecutor(cfg),
+		"vertex":           NewGeminiVertexExecutor(cfg),
+		"iflow":            NewIFlowExecutor(cfg),
+		"kimi":             NewKimiExecutor(cfg),
+		"qwen":             NewQwenExecutor(cfg),
+	}
+	for name, executor := range executors {
+		for _, alt := range []string{"audio/speech", "audio/transcriptions"} {
+			_, err := executor.Execute(context.Background(), &cliproxyauth.Auth{Attributes: map[string]string{}}, cliproxyexecutor.Request{
+				Model:   "model",
+				Payload: []byte(`{"input":"hi"}`),
+			}, cliproxyexecutor.Options{Alt: alt, SourceFormat: sdktranslator.FromString("openai")})
+			var status statusErr
+			if !errors.As(err, &status) || status.StatusCode() != http.StatusNotImplemented {
+				t.Errorf("%s %s error = %v, want 501", name, alt, err)
+			}
+		}
+	}
+}
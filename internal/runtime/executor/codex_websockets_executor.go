@@ -152,6 +152,12 @@ func (e *CodexWebsocketsExecutor) Execute(ctx context.Context, auth *cliproxyaut
 	if opts.Alt == "responses/compact" {
 		return e.CodexExecutor.executeCompact(ctx, auth, req, opts)
 	}
+	if opts.Alt == "embeddings" {
+		return resp, statusErr{code: http.StatusNotImplemented, msg: "/embeddings not supported"}
+	}
+	if isAudioAlt(opts.Alt) {
+		return resp, statusErr{code: http.StatusNotImplemented, msg: "/" + opts.Alt + " not supported"}
+	}
 
 	baseModel := thinking.ParseSuffix(req.Model).ModelName
 	apiKey, baseURL := codexCreds(auth)
@@ -170,7 +176,10 @@ func (e *CodexWebsocketsExecutor) Execute(ctx context.Context, auth *cliproxyaut
 	}
 	originalPayload := originalPayloadSource
 	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayload, false)
-	body := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, false)
+	body, err := translateRequest(ctx, from, to, baseModel, req.Payload, false)
+	if err != nil {
+		return resp, err
+	}
 
 	body, err = thinking.ApplyThinking(body, req.Model, from.String(), to.String(), e.Identifier())
 	if err != nil {
@@ -356,7 +365,10 @@ func (e *CodexWebsocketsExecutor) Execute(ctx context.Context, auth *cliproxyaut
 				reporter.publish(ctx, detail)
 			}
 			var param any
-			out := sdktranslator.TranslateNonStream(ctx, to, from, req.Model, originalPayload, body, payload, &param)
+			out, err := translateNonStream(ctx, to, from, req.Model, originalPayload, body, payload, &param)
+			if err != nil {
+				return resp, err
+			}
 			resp = cliproxyexecutor.Response{Payload: []byte(out)}
 			return resp, nil
 		}
@@ -614,7 +626,14 @@ func (e *CodexWebsocketsExecutor) ExecuteStream(ctx context.Context, auth *clipr
 			}
 
 			line := encodeCodexWebsocketAsSSE(payload)
-			chunks := sdktranslator.TranslateStream(ctx, to, from, req.Model, body, body, line, &param)
+			chunks, errTranslate := translateStream(ctx, to, from, req.Model, body, body, line, &param)
+			if errTranslate != nil {
+				terminateReason = "translation_error"
+				terminateErr = errTranslate
+				reporter.publishFailure(ctx)
+				_ = send(cliproxyexecutor.StreamChunk{Err: errTranslate})
+				return
+			}
 			for i := range chunks {
 				if !send(cliproxyexecutor.StreamChunk{Payload: []byte(chunks[i])}) {
 					terminateReason = "context_done"
@@ -633,7 +652,6 @@ func (e *CodexWebsocketsExecutor) ExecuteStream(ctx context.Context, auth *clipr
 
 func (e *CodexWebsocketsExecutor) dialCodexWebsocket(ctx context.Context, auth *cliproxyauth.Auth, wsURL string, headers http.Header) (*websocket.Conn, *http.Response, error) {
 	dialer := newProxyAwareWebsocketDialer(e.cfg, auth)
-	dialer.HandshakeTimeout = codexResponsesWebsocketHandshakeTO
 	dialer.EnableCompression = true
 	if ctx == nil {
 		ctx = context.Background()
@@ -741,12 +759,26 @@ func markCodexWebsocketCreateSent(sess *codexWebsocketSession, conn *websocket.C
 }
 
 func newProxyAwareWebsocketDialer(cfg *config.Config, auth *cliproxyauth.Auth) *websocket.Dialer {
+	dialTimeout := 30 * time.Second
+	handshakeTimeout := codexResponsesWebsocketHandshakeTO
+	// The websocket handshake covers dialing, TLS and the upgrade, so configured connect and TLS
+	// handshake timeouts bound it together.
+	if timeouts := resolveTimeouts(cfg, auth); timeouts.Connect > 0 || timeouts.TLSHandshake > 0 {
+		if timeouts.Connect > 0 {
+			dialTimeout = secondsDuration(timeouts.Connect)
+		}
+		tlsTimeout := 10 * time.Second
+		if timeouts.TLSHandshake > 0 {
+			tlsTimeout = secondsDuration(timeouts.TLSHandshake)
+		}
+		handshakeTimeout = dialTimeout + tlsTimeout
+	}
 	dialer := &websocket.Dialer{
 		Proxy:             http.ProxyFromEnvironment,
-		HandshakeTimeout:  codexResponsesWebsocketHandshakeTO,
+		HandshakeTimeout:  handshakeTimeout,
 		EnableCompression: true,
 		NetDialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
+			Timeout:   dialTimeout,
 			KeepAlive: 30 * time.Second,
 		}).DialContext,
 	}
@@ -0,0 +1,164 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	geminiembeddings "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/openai/embeddings"
+	openaiembeddings "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/openai/gemini/embeddings"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Embedding requests reach executors as non-streaming executions with Alt set to "embeddings".
+// Gemini-family executors always call batchEmbedContents (or its Vertex predict equivalent) and
+// unwrap the single result again for embedContent clients.
+
+var (
+	formatOpenAI = sdktranslator.FromString("openai")
+	formatGemini = sdktranslator.FromString("gemini")
+)
+
+func embeddingsUnsupportedFormat(from sdktranslator.Format) error {
+	return statusErr{code: http.StatusBadRequest, msg: "embeddings are not supported for " + from.String() + " requests"}
+}
+
+func embeddingsInvalidRequest(err error) error {
+	return statusErr{code: http.StatusBadRequest, msg: "invalid embeddings request: " + err.Error()}
+}
+
+// geminiEmbeddingsRequest converts an inbound embeddings payload into a batchEmbedContents body.
+// single reports whether the client sent a Gemini embedContent request.
+func geminiEmbeddingsRequest(from sdktranslator.Format, model string, payload []byte) (body []byte, single bool, err error) {
+	switch from {
+	case formatOpenAI:
+		body, err = geminiembeddings.ConvertOpenAIEmbeddingsRequestToGemini(model, payload)
+		if err != nil {
+			return nil, false, embeddingsInvalidRequest(err)
+		}
+		return body, false, nil
+	case formatGemini:
+		modelRef := "models/" + model
+		root := gjson.ParseBytes(payload)
+		if requests := root.Get("requests"); requests.IsArray() {
+			if len(requests.Array()) == 0 {
+				return nil, false, statusErr{code: http.StatusBadRequest, msg: "invalid embeddings request: requests must not be empty"}
+			}
+			body = bytes.Clone(payload)
+			for i := range requests.Array() {
+				body, _ = sjson.SetBytes(body, fmt.Sprintf("requests.%d.model", i), modelRef)
+			}
+			return body, false, nil
+		}
+		if !root.Get("content").Exists() {
+			return nil, false, statusErr{code: http.StatusBadRequest, msg: "invalid embeddings request: content is required"}
+		}
+		entry, _ := sjson.SetBytes(bytes.Clone(payload), "model", modelRef)
+		body, _ = sjson.SetRawBytes([]byte(`{"requests":[]}`), "requests.-1", entry)
+		return body, true, nil
+	default:
+		return nil, false, embeddingsUnsupportedFormat(from)
+	}
+}
+
+// geminiEmbeddingsResponse converts a batchEmbedContents response into the client format.
+func geminiEmbeddingsResponse(from sdktranslator.Format, model string, originalRequest, data []byte, single bool, promptTokens int64) []byte {
+	if from == formatOpenAI {
+		return geminiembeddings.ConvertGeminiEmbeddingsResponseToOpenAI(model, originalRequest, data, promptTokens)
+	}
+	if !single {
+		return data
+	}
+	values := gjson.GetBytes(data, "embeddings.0.values")
+	out := []byte(`{"embedding":{"values":[]}}`)
+	if values.IsArray() {
+		out, _ = sjson.SetRawBytes(out, "embedding.values", []byte(values.Raw))
+	}
+	return out
+}
+
+// vertexEmbeddingsRequest rewrites a batchEmbedContents body as a Vertex predict request.
+func vertexEmbeddingsRequest(batch []byte) []byte {
+	out := []byte(`{"instances":[]}`)
+	requests := gjson.GetBytes(batch, "requests").Array()
+	for _, request := range requests {
+		instance := []byte(`{"content":""}`)
+		var texts []string
+		for _, part := range request.Get("content.parts").Array() {
+			if text := part.Get("text"); text.Exists() {
+				texts = append(texts, text.String())
+			}
+		}
+		instance, _ = sjson.SetBytes(instance, "content", strings.Join(texts, "\n"))
+		if taskType := request.Get("taskType").String(); taskType != "" {
+			instance, _ = sjson.SetBytes(instance, "task_type", taskType)
+		}
+		if title := request.Get("title").String(); title != "" {
+			instance, _ = sjson.SetBytes(instance, "title", title)
+		}
+		out, _ = sjson.SetRawBytes(out, "instances.-1", instance)
+	}
+	if len(requests) > 0 {
+		if dims := requests[0].Get("outputDimensionality"); dims.Exists() && dims.Int() > 0 {
+			out, _ = sjson.SetBytes(out, "parameters.outputDimensionality", dims.Int())
+		}
+	}
+	return out
+}
+
+// vertexEmbeddingsResponse rewrites a Vertex predict response as a batchEmbedContents response
+// and returns the input token count reported in the prediction statistics.
+func vertexEmbeddingsResponse(data []byte) ([]byte, int64) {
+	var (
+		buf    bytes.Buffer
+		tokens int64
+	)
+	buf.WriteString(`{"embeddings":[`)
+	for i, prediction := range gjson.GetBytes(data, "predictions").Array() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`{"values":`)
+		if values := prediction.Get("embeddings.values"); values.IsArray() {
+			buf.WriteString(values.Raw)
+		} else {
+			buf.WriteString("[]")
+		}
+		buf.WriteByte('}')
+		tokens += prediction.Get("embeddings.statistics.token_count").Int()
+	}
+	buf.WriteString(`]}`)
+	return buf.Bytes(), tokens
+}
+
+// openAIEmbeddingsRequest converts an inbound embeddings payload into an OpenAI embeddings body.
+// single reports whether the client sent a Gemini embedContent request.
+func openAIEmbeddingsRequest(from sdktranslator.Format, model string, payload []byte) (body []byte, single bool, err error) {
+	switch from {
+	case formatOpenAI:
+		if !gjson.GetBytes(payload, "input").Exists() {
+			return nil, false, statusErr{code: http.StatusBadRequest, msg: "invalid embeddings request: input is required"}
+		}
+		body, _ = sjson.SetBytes(bytes.Clone(payload), "model", model)
+		return body, false, nil
+	case formatGemini:
+		body, err = openaiembeddings.ConvertGeminiEmbeddingsRequestToOpenAI(model, payload)
+		if err != nil {
+			return nil, false, embeddingsInvalidRequest(err)
+		}
+		return body, !gjson.GetBytes(payload, "requests").Exists(), nil
+	default:
+		return nil, false, embeddingsUnsupportedFormat(from)
+	}
+}
+
+// openAIEmbeddingsResponse converts an OpenAI embeddings response into the client format.
+func openAIEmbeddingsResponse(from sdktranslator.Format, data []byte, single bool) []byte {
+	if from == formatGemini {
+		return openaiembeddings.ConvertOpenAIEmbeddingsResponseToGemini(data, single)
+	}
+	return data
+}
@@ -0,0 +1,152 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAICompatExecutorEmbeddingsFromGemini(t *testing.T) {
+	var gotPath string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1,0.2]}],"usage":{"prompt_tokens":3,"total_tokens":3}}`))
+	}))
+	defer server.Close()
+
+	executor := NewOpenAICompatExecutor("openai-compatibility", &config.Config{})
+	auth := &cliproxyauth.Auth{Attributes: map[string]string{
+		"base_url": server.URL + "/v1",
+		"api_key":  "test",
+	}}
+	payload := []byte(`{"content":{"parts":[{"text":"hello"}]},"outputDimensionality":2}`)
+	resp, err := executor.Execute(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "text-embedding-3-small",
+		Payload: payload,
+	}, cliproxyexecutor.Options{
+		SourceFormat: sdktranslator.FromString("gemini"),
+		Alt:          "embeddings",
+	})
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if gotPath != "/v1/embeddings" {
+		t.Fatalf("path = %q, want %q", gotPath, "/v1/embeddings")
+	}
+	if got := gjson.GetBytes(gotBody, "input").String(); got != "hello" {
+		t.Fatalf("input = %q, want %q", got, "hello")
+	}
+	if got := gjson.GetBytes(gotBody, "dimensions").Int(); got != 2 {
+		t.Fatalf("dimensions = %d, want 2", got)
+	}
+	if got := gjson.GetBytes(resp.Payload, "embedding.values").Raw; got != "[0.1,0.2]" {
+		t.Fatalf("payload = %s", string(resp.Payload))
+	}
+}
+
+func TestGeminiExecutorEmbeddingsFromOpenAI(t *testing.T) {
+	var gotPath string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"embeddings":[{"values":[1,2]},{"values":[3,4]}]}`))
+	}))
+	defer server.Close()
+
+	executor := NewGeminiExecutor(&config.Config{})
+	auth := &cliproxyauth.Auth{Attributes: map[string]string{
+		"base_url": server.URL,
+		"api_key":  "test",
+	}}
+	payload := []byte(`{"model":"gemini-embedding-001","input":["a","b"]}`)
+	resp, err := executor.Execute(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "gemini-embedding-001",
+		Payload: payload,
+	}, cliproxyexecutor.Options{
+		SourceFormat:    sdktranslator.FromString("openai"),
+		Alt:             "embeddings",
+		OriginalRequest: payload,
+	})
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if gotPath != "/v1beta/models/gemini-embedding-001:batchEmbedContents" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if got := gjson.GetBytes(gotBody, "requests.1.content.parts.0.text").String(); got != "b" {
+		t.Fatalf("second request text = %q, want %q", got, "b")
+	}
+	if got := gjson.GetBytes(gotBody, "requests.0.model").String(); got != "models/gemini-embedding-001" {
+		t.Fatalf("request model = %q", got)
+	}
+	if got := gjson.GetBytes(resp.Payload, "data.1.embedding").Raw; got != "[3,4]" {
+		t.Fatalf("payload = %s", string(resp.Payload))
+	}
+	if got := gjson.GetBytes(resp.Payload, "model").String(); got != "gemini-embedding-001" {
+		t.Fatalf("model = %q", got)
+	}
+}
+
+func TestGeminiEmbeddingsRequestWrapsSingleContent(t *testing.T) {
+	body, single, err := geminiEmbeddingsRequest(formatGemini, "gemini-embedding-001", []byte(`{"content":{"parts":[{"text":"x"}]},"taskType":"RETRIEVAL_QUERY"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !single {
+		t.Fatal("expected single embedContent request")
+	}
+	if got := gjson.GetBytes(body, "requests.0.taskType").String(); got != "RETRIEVAL_QUERY" {
+		t.Fatalf("taskType = %q", got)
+	}
+	out := geminiEmbeddingsResponse(formatGemini, "gemini-embedding-001", nil, []byte(`{"embeddings":[{"values":[0.5]}]}`), true, 0)
+	if got := gjson.GetBytes(out, "embedding.values").Raw; got != "[0.5]" {
+		t.Fatalf("unwrapped response = %s", string(out))
+	}
+}
+
+func TestVertexEmbeddingsRoundTrip(t *testing.T) {
+	batch := []byte(`{"requests":[{"model":"models/m","content":{"parts":[{"text":"a"},{"text":"b"}]},"taskType":"RETRIEVAL_DOCUMENT","outputDimensionality":8}]}`)
+	req := vertexEmbeddingsRequest(batch)
+	if got := gjson.GetBytes(req, "instances.0.content").String(); got != "a\nb" {
+		t.Fatalf("instance content = %q", got)
+	}
+	if got := gjson.GetBytes(req, "instances.0.task_type").String(); got != "RETRIEVAL_DOCUMENT" {
+		t.Fatalf("task_type = %q", got)
+	}
+	if got := gjson.GetBytes(req, "parameters.outputDimensionality").Int(); got != 8 {
+		t.Fatalf("outputDimensionality = %d", got)
+	}
+
+	resp, tokens := vertexEmbeddingsResponse([]byte(`{"predictions":[{"embeddings":{"values":[1,2],"statistics":{"token_count":4}}},{"embeddings":{"values":[3],"statistics":{"token_count":2}}}]}`))
+	if tokens != 6 {
+		t.Fatalf("tokens = %d, want 6", tokens)
+	}
+	if got := gjson.GetBytes(resp, "embeddings.1.values").Raw; got != "[3]" {
+		t.Fatalf("converted response = %s", string(resp))
+	}
+}
+
+func TestEmbeddingsUnsupportedExecutor(t *testing.T) {
+	executor := NewClaudeExecutor(&config.Config{})
+	_, err := executor.Execute(context.Background(), &cliproxyauth.Auth{}, cliproxyexecutor.Request{Model: "claude-sonnet-4-5"}, cliproxyexecutor.Options{
+		SourceFormat: sdktranslator.FromString("openai"),
+		Alt:          "embeddings",
+	})
+	se, ok := err.(statusErr)
+	if !ok || se.StatusCode() != http.StatusNotImplemented {
+		t.Fatalf("expected 501 status error, got %v", err)
+	}
+}
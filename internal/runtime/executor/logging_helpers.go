@@ -14,6 +14,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 )
@@ -49,8 +50,10 @@ type upstreamAttempt struct {
 	errorWritten         bool
 }
 
-// recordAPIRequest stores the upstream request metadata in Gin context for request logging.
+// recordAPIRequest stores the upstream request metadata in Gin context for request logging and
+// reports the payload to execution hooks tracking the call.
 func recordAPIRequest(ctx context.Context, cfg *config.Config, info upstreamRequestLog) {
+	cliproxyexecutor.RecordUpstreamRequest(ctx, info.Body)
 	if cfg == nil || !cfg.RequestLog {
 		return
 	}
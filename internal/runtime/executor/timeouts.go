@@ -0,0 +1,234 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// resolveTimeouts returns the upstream timeouts for auth: the global block, overridden by the
+// block of the auth's provider, overridden by the credential's own "timeout:" attributes.
+func resolveTimeouts(cfg *config.Config, auth *cliproxyauth.Auth) config.Timeouts {
+	var out config.Timeouts
+	provider := ""
+	if auth != nil {
+		provider = auth.Provider
+	}
+	if cfg != nil {
+		out = cfg.Timeouts.ForProvider(provider)
+	}
+	if auth != nil {
+		out = out.Override(timeoutsFromAttributes(auth.Attributes))
+	}
+	return out
+}
+
+func timeoutsFromAttributes(attrs map[string]string) config.Timeouts {
+	seconds := func(key string) int {
+		v, err := strconv.Atoi(strings.TrimSpace(attrs["timeout:"+key]))
+		if err != nil || v < 0 {
+			return 0
+		}
+		return v
+	}
+	return config.Timeouts{
+		Connect:      seconds("connect"),
+		TLSHandshake: seconds("tls-handshake"),
+		FirstByte:    seconds("first-byte"),
+		Idle:         seconds("idle"),
+		Total:        seconds("total"),
+	}
+}
+
+func secondsDuration(seconds int) time.Duration {
+	return time.Duration(seconds) * time.Second
+}
+
+// timeoutRoundTripper enforces the first-byte, idle and total timeouts of a request. Connect and
+// TLS handshake timeouts belong to the pooled transport underneath.
+type timeoutRoundTripper struct {
+	base      http.RoundTripper
+	firstByte time.Duration
+	idle      time.Duration
+	total     time.Duration
+}
+
+// withTimeouts wraps base when any of the response phases of t is bounded.
+func withTimeouts(base http.RoundTripper, t config.Timeouts) http.RoundTripper {
+	if base == nil || (t.FirstByte <= 0 && t.Idle <= 0 && t.Total <= 0) {
+		return base
+	}
+	return &timeoutRoundTripper{
+		base:      base,
+		firstByte: secondsDuration(t.FirstByte),
+		idle:      secondsDuration(t.Idle),
+		total:     secondsDuration(t.Total),
+	}
+}
+
+func (t *timeoutRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancelCause(req.Context())
+	w := &phaseWatch{cancel: cancel}
+	if t.total > 0 {
+		w.total = time.AfterFunc(t.total, func() {
+			cancel(&cliproxyexecutor.TimeoutError{Phase: "total", Timeout: t.total})
+		})
+	}
+	if t.firstByte > 0 {
+		w.arm("first-byte", t.firstByte)
+	}
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		w.stop()
+		if errTimeout := timeoutCause(ctx); errTimeout != nil {
+			err = errTimeout
+		}
+		cancel(nil)
+		return nil, err
+	}
+	if t.firstByte <= 0 && t.idle > 0 {
+		w.arm("idle", t.idle)
+	}
+	resp.Body = &watchedBody{ReadCloser: resp.Body, ctx: ctx, watch: w, idle: t.idle}
+	return resp, nil
+}
+
+// phaseWatch cancels the request with a TimeoutError once the armed phase runs out.
+type phaseWatch struct {
+	mu     sync.Mutex
+	cancel context.CancelCauseFunc
+	phase  string
+	timer  *time.Timer
+	total  *time.Timer
+}
+
+func (w *phaseWatch) arm(phase string, d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil && w.phase == phase {
+		w.timer.Reset(d)
+		return
+	}
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.phase = phase
+	w.timer = time.AfterFunc(d, func() {
+		w.cancel(&cliproxyexecutor.TimeoutError{Phase: phase, Timeout: d})
+	})
+}
+
+func (w *phaseWatch) disarm() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.phase = ""
+}
+
+func (w *phaseWatch) stop() {
+	w.disarm()
+	if w.total != nil {
+		w.total.Stop()
+	}
+}
+
+// watchedBody re-arms the idle timeout on every chunk and reports a tripped timeout instead of
+// the bare cancellation error the transport returns.
+type watchedBody struct {
+	io.ReadCloser
+	ctx   context.Context
+	watch *phaseWatch
+	idle  time.Duration
+}
+
+func (b *watchedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if b.idle > 0 {
+			b.watch.arm("idle", b.idle)
+		} else {
+			b.watch.disarm()
+		}
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		if errTimeout := timeoutCause(b.ctx); errTimeout != nil {
+			err = errTimeout
+		}
+	}
+	return n, err
+}
+
+func (b *watchedBody) Close() error {
+	b.watch.stop()
+	err := b.ReadCloser.Close()
+	b.watch.cancel(nil)
+	return err
+}
+
+func timeoutCause(ctx context.Context) error {
+	if errTimeout, ok := errors.AsType[*cliproxyexecutor.TimeoutError](context.Cause(ctx)); ok {
+		return errTimeout
+	}
+	return nil
+}
+
+// relayWatch enforces the first-byte, idle and total timeouts of a request relayed over the AI
+// Studio websocket. The connected browser dials the upstream itself, so connect and TLS
+// handshake timeouts do not apply there.
+type relayWatch struct {
+	ctx   context.Context
+	watch *phaseWatch
+	idle  time.Duration
+}
+
+// watchRelay starts the timeouts of t; the relay request must run with the returned watch's ctx.
+func watchRelay(ctx context.Context, t config.Timeouts) *relayWatch {
+	ctx, cancel := context.WithCancelCause(ctx)
+	r := &relayWatch{ctx: ctx, watch: &phaseWatch{cancel: cancel}, idle: secondsDuration(t.Idle)}
+	if total := secondsDuration(t.Total); total > 0 {
+		r.watch.total = time.AfterFunc(total, func() {
+			cancel(&cliproxyexecutor.TimeoutError{Phase: "total", Timeout: total})
+		})
+	}
+	switch {
+	case t.FirstByte > 0:
+		r.watch.arm("first-byte", secondsDuration(t.FirstByte))
+	case r.idle > 0:
+		r.watch.arm("idle", r.idle)
+	}
+	return r
+}
+
+// progress records a relay event and restarts the idle timeout.
+func (r *relayWatch) progress() {
+	if r.idle > 0 {
+		r.watch.arm("idle", r.idle)
+	} else {
+		r.watch.disarm()
+	}
+}
+
+// err returns the timeout that cancelled the request, or fallback when none did.
+func (r *relayWatch) err(fallback error) error {
+	if errTimeout := timeoutCause(r.ctx); errTimeout != nil {
+		return errTimeout
+	}
+	return fallback
+}
+
+func (r *relayWatch) stop() {
+	r.watch.stop()
+	r.watch.cancel(nil)
+}
@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestResolveTimeoutsLayersCredentialOverProviderOverGlobal(t *testing.T) {
+	cfg := &config.Config{Timeouts: config.TimeoutsConfig{
+		Timeouts: config.Timeouts{Connect: 5, FirstByte: 30, Idle: 60},
+		Providers: map[string]config.Timeouts{
+			"Claude": {FirstByte: 20, Total: 600},
+		},
+	}}
+	auth := &cliproxyauth.Auth{Provider: "claude", Attributes: map[string]string{"timeout:idle": "15"}}
+
+	got := resolveTimeouts(cfg, auth)
+	want := config.Timeouts{Connect: 5, FirstByte: 20, Idle: 15, Total: 600}
+	if got != want {
+		t.Fatalf("resolveTimeouts() = %+v, want %+v", got, want)
+	}
+	if got := resolveTimeouts(cfg, &cliproxyauth.Auth{Provider: "codex"}); got != cfg.Timeouts.Timeouts {
+		t.Fatalf("resolveTimeouts(codex) = %+v, want global %+v", got, cfg.Timeouts.Timeouts)
+	}
+}
+
+func TestProxyAwareHTTPClientReportsStalledStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	auth := &cliproxyauth.Auth{Provider: "stall", Attributes: map[string]string{"timeout:idle": "1"}}
+	client := newProxyAwareHTTPClient(context.Background(), &config.Config{}, auth, 0)
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	started := time.Now()
+	body, err := io.ReadAll(resp.Body)
+	var errTimeout *cliproxyexecutor.TimeoutError
+	if !errors.As(err, &errTimeout) || errTimeout.Phase != "idle" {
+		t.Fatalf("ReadAll() error = %v, want idle TimeoutError", err)
+	}
+	if string(body) != "data: first\n\n" {
+		t.Fatalf("body = %q, want the chunk sent before the stall", body)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("stall detected after %s, want about 1s", elapsed)
+	}
+}
+
+func TestProxyAwareHTTPClientEnforcesFirstByteTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Timeouts: config.TimeoutsConfig{Timeouts: config.Timeouts{FirstByte: 1}}}
+	client := newProxyAwareHTTPClient(context.Background(), cfg, &cliproxyauth.Auth{Provider: "slow"}, 0)
+	_, err := client.Get(server.URL)
+	var errTimeout *cliproxyexecutor.TimeoutError
+	if !errors.As(err, &errTimeout) || errTimeout.Phase != "first-byte" {
+		t.Fatalf("Get() error = %v, want first-byte TimeoutError", err)
+	}
+}
+
+func TestWatchRelayEnforcesFirstByteThenIdle(t *testing.T) {
+	watch := watchRelay(context.Background(), config.Timeouts{FirstByte: 1, Idle: 60})
+	defer watch.stop()
+
+	select {
+	case <-watch.ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("relay request without events was not cancelled")
+	}
+	var errTimeout *cliproxyexecutor.TimeoutError
+	if err := watch.err(errors.New("stream closed")); !errors.As(err, &errTimeout) || errTimeout.Phase != "first-byte" {
+		t.Fatalf("err() = %v, want first-byte TimeoutError", err)
+	}
+
+	progressed := watchRelay(context.Background(), config.Timeouts{FirstByte: 1, Idle: 60})
+	progressed.progress()
+	time.Sleep(1500 * time.Millisecond)
+	if err := progressed.ctx.Err(); err != nil {
+		t.Fatalf("relay request with an event was cancelled: %v", context.Cause(progressed.ctx))
+	}
+	progressed.stop()
+	if err := progressed.err(io.EOF); err != io.EOF {
+		t.Fatalf("err() after stop = %v, want the fallback", err)
+	}
+}
@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/sjson"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// translateRequest converts an inbound payload into the upstream format inside a translation span.
+// When the request context carries a translator pipeline its request middleware wraps the conversion.
+func translateRequest(ctx context.Context, from, to sdktranslator.Format, model string, payload []byte, stream bool) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "cliproxy.translator.request", translationSpanAttributes(from, to, model)...)
+	defer span.End()
+	pipeline := sdktranslator.PipelineFromContext(ctx)
+	if pipeline == nil {
+		return sdktranslator.TranslateRequest(from, to, model, payload, stream), nil
+	}
+	out, err := pipeline.TranslateRequest(ctx, from, to, sdktranslator.RequestEnvelope{
+		Format: from,
+		Model:  model,
+		Stream: stream,
+		Body:   payload,
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, translationError(err)
+	}
+	return out.Body, nil
+}
+
+// translateNonStream converts a complete upstream response back into the client format inside a
+// translation span. Streamed chunks are not traced individually; they fall under the stream span.
+func translateNonStream(ctx context.Context, from, to sdktranslator.Format, model string, originalRequest, translatedRequest, payload []byte, param *any) (string, error) {
+	ctx, span := tracing.Start(ctx, "cliproxy.translator.response", translationSpanAttributes(from, to, model)...)
+	defer span.End()
+	pipeline := sdktranslator.PipelineFromContext(ctx)
+	if pipeline == nil {
+		return sdktranslator.TranslateNonStream(ctx, from, to, model, originalRequest, translatedRequest, payload, param), nil
+	}
+	out, err := pipeline.TranslateResponse(ctx, from, to, sdktranslator.ResponseEnvelope{
+		Format: from,
+		Model:  model,
+		Body:   payload,
+	}, originalRequest, translatedRequest, param)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return "", translationError(err)
+	}
+	return string(out.Body), nil
+}
+
+// translateStream converts one upstream stream chunk into client chunks, running the response
+// middleware of the context's translator pipeline once per upstream chunk.
+func translateStream(ctx context.Context, from, to sdktranslator.Format, model string, originalRequest, translatedRequest, payload []byte, param *any) ([]string, error) {
+	pipeline := sdktranslator.PipelineFromContext(ctx)
+	if pipeline == nil {
+		return sdktranslator.TranslateStream(ctx, from, to, model, originalRequest, translatedRequest, payload, param), nil
+	}
+	out, err := pipeline.TranslateResponse(ctx, from, to, sdktranslator.ResponseEnvelope{
+		Format: from,
+		Model:  model,
+		Stream: true,
+		Body:   payload,
+	}, originalRequest, translatedRequest, param)
+	if err != nil {
+		return nil, translationError(err)
+	}
+	return out.Chunks, nil
+}
+
+// translationError reports a middleware failure to the conductor. Errors that carry their own
+// status pass through; anything else is a rejected request, which must not cool the credential down.
+func translationError(err error) error {
+	var withStatus interface{ StatusCode() int }
+	if errors.As(err, &withStatus) && withStatus.StatusCode() > 0 {
+		return err
+	}
+	body, _ := sjson.SetBytes([]byte(`{"error":{"type":"invalid_request_error"}}`), "error.message", err.Error())
+	return statusErr{code: http.StatusBadRequest, msg: string(body)}
+}
+
+func translationSpanAttributes(from, to sdktranslator.Format, model string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("cliproxy.translator.from", from.String()),
+		attribute.String("cliproxy.translator.to", to.String()),
+		attribute.String("cliproxy.model", model),
+	}
+}
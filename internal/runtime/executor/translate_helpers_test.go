@@ -0,0 +1,150 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+func newPipelineTestExecutor(t *testing.T, handler http.HandlerFunc) (*OpenAICompatExecutor, *cliproxyauth.Auth) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	auth := &cliproxyauth.Auth{Attributes: map[string]string{
+		"base_url": server.URL + "/v1",
+		"api_key":  "test",
+	}}
+	return NewOpenAICompatExecutor("openai-compatibility", &config.Config{}), auth
+}
+
+func scrubMiddleware(ctx context.Context, resp sdktranslator.ResponseEnvelope, next sdktranslator.ResponseHandler) (sdktranslator.ResponseEnvelope, error) {
+	out, err := next(ctx, resp)
+	if err != nil {
+		return out, err
+	}
+	out.Body = bytes.ReplaceAll(out.Body, []byte("secret"), []byte("[redacted]"))
+	for i := range out.Chunks {
+		out.Chunks[i] = strings.ReplaceAll(out.Chunks[i], "secret", "[redacted]")
+	}
+	return out, nil
+}
+
+func TestTranslatorPipelineRewritesRequestAndResponse(t *testing.T) {
+	var gotBody []byte
+	executor, auth := newPipelineTestExecutor(t, func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"c1","object":"chat.completion","model":"m","choices":[{"index":0,"message":{"role":"assistant","content":"the secret is 42"},"finish_reason":"stop"}]}`))
+	})
+
+	pipeline := sdktranslator.NewPipeline(nil)
+	pipeline.UseRequest(func(ctx context.Context, req sdktranslator.RequestEnvelope, next sdktranslator.RequestHandler) (sdktranslator.RequestEnvelope, error) {
+		if req.Format != sdktranslator.FormatOpenAI {
+			t.Errorf("request format = %q, want %q", req.Format, sdktranslator.FormatOpenAI)
+		}
+		req.Body, _ = sjson.SetBytes(req.Body, "messages.0.content", "rewritten")
+		return next(ctx, req)
+	})
+	pipeline.UseResponse(scrubMiddleware)
+	ctx := sdktranslator.WithPipeline(context.Background(), pipeline)
+
+	resp, err := executor.Execute(ctx, auth, cliproxyexecutor.Request{
+		Model:   "m",
+		Payload: []byte(`{"model":"m","messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FormatOpenAI})
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if got := gjson.GetBytes(gotBody, "messages.0.content").String(); got != "rewritten" {
+		t.Fatalf("upstream content = %q, want %q", got, "rewritten")
+	}
+	if got := gjson.GetBytes(resp.Payload, "choices.0.message.content").String(); got != "the [redacted] is 42" {
+		t.Fatalf("response content = %q", got)
+	}
+}
+
+func TestTranslatorPipelineRunsPerStreamChunk(t *testing.T) {
+	executor, auth := newPipelineTestExecutor(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: {\"id\":\"c1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"secret\"}}]}\n\n")
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	})
+
+	var calls int
+	pipeline := sdktranslator.NewPipeline(nil)
+	pipeline.UseResponse(func(ctx context.Context, resp sdktranslator.ResponseEnvelope, next sdktranslator.ResponseHandler) (sdktranslator.ResponseEnvelope, error) {
+		if !resp.Stream {
+			t.Errorf("expected stream envelope")
+		}
+		calls++
+		return scrubMiddleware(ctx, resp, next)
+	})
+	ctx := sdktranslator.WithPipeline(context.Background(), pipeline)
+
+	result, err := executor.ExecuteStream(ctx, auth, cliproxyexecutor.Request{
+		Model:   "m",
+		Payload: []byte(`{"model":"m","stream":true,"messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FormatOpenAI, Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream error: %v", err)
+	}
+	var joined strings.Builder
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("stream error: %v", chunk.Err)
+		}
+		joined.Write(chunk.Payload)
+	}
+	if calls == 0 {
+		t.Fatal("response middleware was not invoked for stream chunks")
+	}
+	if strings.Contains(joined.String(), "secret") || !strings.Contains(joined.String(), "[redacted]") {
+		t.Fatalf("stream output = %s", joined.String())
+	}
+}
+
+func TestTranslatorPipelineRequestErrorIsInvalidRequest(t *testing.T) {
+	called := false
+	executor, auth := newPipelineTestExecutor(t, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	pipeline := sdktranslator.NewPipeline(nil)
+	pipeline.UseRequest(func(ctx context.Context, req sdktranslator.RequestEnvelope, next sdktranslator.RequestHandler) (sdktranslator.RequestEnvelope, error) {
+		return req, errors.New("prompt rejected")
+	})
+	ctx := sdktranslator.WithPipeline(context.Background(), pipeline)
+
+	_, err := executor.Execute(ctx, auth, cliproxyexecutor.Request{
+		Model:   "m",
+		Payload: []byte(`{"model":"m","messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FormatOpenAI})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if called {
+		t.Fatal("upstream must not be called when request middleware fails")
+	}
+	var se statusErr
+	if !errors.As(err, &se) || se.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("error = %#v, want 400 statusErr", err)
+	}
+	if got := gjson.Get(se.Error(), "error.message").String(); got != "prompt rejected" {
+		t.Fatalf("error message = %q", got)
+	}
+	if got := gjson.Get(se.Error(), "error.type").String(); got != "invalid_request_error" {
+		t.Fatalf("error type = %q", got)
+	}
+}
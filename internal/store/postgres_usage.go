@@ -0,0 +1,141 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+)
+
+const defaultUsageTable = "usage_records"
+
+// PostgresUsageStore persists usage records in the database backing a PostgresStore.
+// The connection is owned by the PostgresStore, so Close does not release it.
+type PostgresUsageStore struct {
+	db    *sql.DB
+	table string
+}
+
+// UsageStore prepares the usage table and returns a usage.Store sharing this store's connection.
+func (s *PostgresStore) UsageStore(ctx context.Context) (*PostgresUsageStore, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("postgres store: not initialized")
+	}
+	if schema := strings.TrimSpace(s.cfg.Schema); schema != "" {
+		query := fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", quoteIdentifier(schema))
+		if _, err := s.db.ExecContext(ctx, query); err != nil {
+			return nil, fmt.Errorf("postgres store: create schema: %w", err)
+		}
+	}
+	table := s.fullTableName(defaultUsageTable)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id BIGSERIAL PRIMARY KEY,
+			requested_at TIMESTAMPTZ NOT NULL,
+			api TEXT NOT NULL,
+			api_key_hash TEXT NOT NULL DEFAULT '',
+			model TEXT NOT NULL,
+			source TEXT NOT NULL DEFAULT '',
+			auth_index TEXT NOT NULL DEFAULT '',
+			failed BOOLEAN NOT NULL DEFAULT FALSE,
+			hedged BOOLEAN NOT NULL DEFAULT FALSE,
+			input_tokens BIGINT NOT NULL DEFAULT 0,
+			output_tokens BIGINT NOT NULL DEFAULT 0,
+			reasoning_tokens BIGINT NOT NULL DEFAULT 0,
+			cached_tokens BIGINT NOT NULL DEFAULT 0,
+			total_tokens BIGINT NOT NULL DEFAULT 0
+		)
+	`, table)); err != nil {
+		return nil, fmt.Errorf("postgres store: create usage table: %w", err)
+	}
+	// Tables created before client keys were hashed or requests were hedged lack those columns.
+	for _, column := range []string{"api_key_hash TEXT NOT NULL DEFAULT ''", "hedged BOOLEAN NOT NULL DEFAULT FALSE"} {
+		if _, err := s.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s", table, column)); err != nil {
+			return nil, fmt.Errorf("postgres store: migrate usage table: %w", err)
+		}
+	}
+	index := quoteIdentifier(defaultUsageTable + "_requested_at_idx")
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (requested_at)", index, table)); err != nil {
+		return nil, fmt.Errorf("postgres store: create usage index: %w", err)
+	}
+	return &PostgresUsageStore{db: s.db, table: table}, nil
+}
+
+// Append implements usage.Store.
+func (u *PostgresUsageStore) Append(ctx context.Context, record usage.StoredRecord) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (requested_at, api, api_key_hash, model, source, auth_index, failed, hedged,
+			input_tokens, output_tokens, reasoning_tokens, cached_tokens, total_tokens)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, u.table)
+	tokens := record.Tokens
+	if _, err := u.db.ExecContext(ctx, query,
+		record.Timestamp.UTC(), record.API, record.APIKeyHash, record.Model, record.Source, record.AuthIndex, record.Failed, record.Hedged,
+		tokens.InputTokens, tokens.OutputTokens, tokens.ReasoningTokens, tokens.CachedTokens, tokens.TotalTokens,
+	); err != nil {
+		return fmt.Errorf("postgres store: insert usage record: %w", err)
+	}
+	return nil
+}
+
+// Query implements usage.Store.
+func (u *PostgresUsageStore) Query(ctx context.Context, from, to time.Time) ([]usage.StoredRecord, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if !from.IsZero() {
+		args = append(args, from.UTC())
+		conditions = append(conditions, fmt.Sprintf("requested_at >= $%d", len(args)))
+	}
+	if !to.IsZero() {
+		args = append(args, to.UTC())
+		conditions = append(conditions, fmt.Sprintf("requested_at < $%d", len(args)))
+	}
+	query := fmt.Sprintf(`
+		SELECT requested_at, api, api_key_hash, model, source, auth_index, failed, hedged,
+			input_tokens, output_tokens, reasoning_tokens, cached_tokens, total_tokens
+		FROM %s`, u.table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY requested_at, id"
+
+	rows, err := u.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("postgres store: query usage records: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []usage.StoredRecord
+	for rows.Next() {
+		var record usage.StoredRecord
+		tokens := &record.Tokens
+		if err = rows.Scan(
+			&record.Timestamp, &record.API, &record.APIKeyHash, &record.Model, &record.Source, &record.AuthIndex, &record.Failed, &record.Hedged,
+			&tokens.InputTokens, &tokens.OutputTokens, &tokens.ReasoningTokens, &tokens.CachedTokens, &tokens.TotalTokens,
+		); err != nil {
+			return nil, fmt.Errorf("postgres store: scan usage record: %w", err)
+		}
+		out = append(out, record)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres store: iterate usage records: %w", err)
+	}
+	return out, nil
+}
+
+// Prune implements usage.Store.
+func (u *PostgresUsageStore) Prune(ctx context.Context, before time.Time) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE requested_at < $1", u.table)
+	if _, err := u.db.ExecContext(ctx, query, before.UTC()); err != nil {
+		return fmt.Errorf("postgres store: prune usage records: %w", err)
+	}
+	return nil
+}
+
+// Close implements usage.Store. The shared connection stays open.
+func (u *PostgresUsageStore) Close() error { return nil }
@@ -0,0 +1,235 @@
+// Package tracing wires OpenTelemetry trace export for the proxy. Spans cover the inbound HTTP
+// request, auth selection, retry attempts, translation, upstream calls and stream lifetimes, and
+// carry the request ID assigned by the logging middleware.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	instrumentationName = "github.com/router-for-me/CLIProxyAPI/v6"
+	defaultServiceName  = "cli-proxy-api"
+)
+
+// RequestIDKey is the span attribute carrying the proxy request ID.
+const RequestIDKey = attribute.Key("cliproxy.request_id")
+
+var (
+	mu        sync.Mutex
+	provider  *sdktrace.TracerProvider
+	applied   config.TracingConfig
+	enabled   atomic.Bool
+	propagate atomic.Bool
+
+	propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+)
+
+// Configure installs or replaces the global tracer provider according to cfg. Reapplying an
+// unchanged configuration is a no-op; a disabled configuration shuts the exporter down.
+func Configure(ctx context.Context, cfg config.TracingConfig) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	propagate.Store(cfg.Enable && cfg.PropagateUpstream)
+	if provider != nil && applied == cfg {
+		return nil
+	}
+	previous := provider
+	provider = nil
+	applied = cfg
+	enabled.Store(false)
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	if previous != nil {
+		shutdownProvider(ctx, previous)
+	}
+	if !cfg.Enable {
+		return nil
+	}
+
+	var opts []otlptracehttp.Option
+	if endpoint := strings.TrimSpace(cfg.Endpoint); endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("tracing: create OTLP exporter: %w", err)
+	}
+	serviceName := strings.TrimSpace(cfg.ServiceName)
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		res = resource.Default()
+	}
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	installProvider(sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	))
+	return nil
+}
+
+// installProvider makes tp the active provider. Callers must hold mu.
+func installProvider(tp *sdktrace.TracerProvider) {
+	provider = tp
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagator)
+	enabled.Store(true)
+}
+
+// Shutdown flushes pending spans and stops the exporter.
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	previous := provider
+	provider = nil
+	applied = config.TracingConfig{}
+	enabled.Store(false)
+	propagate.Store(false)
+	mu.Unlock()
+	if previous == nil {
+		return nil
+	}
+	return previous.Shutdown(ctx)
+}
+
+func shutdownProvider(ctx context.Context, tp *sdktrace.TracerProvider) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	_ = tp.Shutdown(shutdownCtx)
+}
+
+// Enabled reports whether spans are currently exported.
+func Enabled() bool { return enabled.Load() }
+
+// Start opens a span named name as a child of the span in ctx, tagging it with the request ID.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !enabled.Load() {
+		// A non-recording span keeps callers from ending the parent span in ctx.
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	if requestID := logging.GetRequestID(ctx); requestID != "" {
+		attrs = append(attrs, RequestIDKey.String(requestID))
+	}
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// RecordError marks span as failed with err. A nil err is ignored.
+func RecordError(span trace.Span, err error) {
+	if span == nil || err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	RecordError(span, err)
+	span.End()
+}
+
+// GinMiddleware starts the server span for each inbound request, continuing any trace context
+// sent by the client. It must run after the logging middleware so the request ID is available.
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled.Load() {
+			c.Next()
+			return
+		}
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		ctx, span := Start(ctx, c.Request.Method+" "+route,
+			semconv.HTTPRequestMethodKey.String(c.Request.Method),
+			semconv.HTTPRoute(route),
+		)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		span.End()
+	}
+}
+
+// WrapTransport instruments upstream HTTP calls with a client span and, when upstream
+// propagation is enabled, a traceparent header. base is returned unchanged while tracing is off.
+func WrapTransport(base http.RoundTripper) http.RoundTripper {
+	if !enabled.Load() {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := Start(req.Context(), "upstream "+req.Method+" "+req.URL.Host,
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.ServerAddress(req.URL.Hostname()),
+		semconv.URLPath(req.URL.Path),
+	)
+	if propagate.Load() {
+		req = req.Clone(ctx)
+		propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	} else {
+		req = req.WithContext(ctx)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		End(span, err)
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	// The span covers the time to response headers; body streaming is covered by the
+	// execution span that owns the response.
+	span.End()
+	return resp, nil
+}
@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func useRecorder(t *testing.T, propagateUpstream bool) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	mu.Lock()
+	applied = config.TracingConfig{Enable: true, PropagateUpstream: propagateUpstream}
+	propagate.Store(propagateUpstream)
+	installProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	mu.Unlock()
+	t.Cleanup(func() { _ = Shutdown(context.Background()) })
+	return recorder
+}
+
+func TestStartDisabledReturnsNonRecordingSpan(t *testing.T) {
+	_ = Shutdown(context.Background())
+	ctx, span := Start(context.Background(), "noop")
+	if span.IsRecording() {
+		t.Fatal("expected a non-recording span while tracing is disabled")
+	}
+	if ctx == nil {
+		t.Fatal("expected a context")
+	}
+	if WrapTransport(http.DefaultTransport) != http.DefaultTransport {
+		t.Fatal("expected the base transport to be returned unchanged while tracing is disabled")
+	}
+}
+
+func TestStartTagsRequestID(t *testing.T) {
+	recorder := useRecorder(t, false)
+	ctx := logging.WithRequestID(context.Background(), "req-123")
+	_, span := Start(ctx, "cliproxy.test")
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	var found bool
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == RequestIDKey && attr.Value.AsString() == "req-123" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected request id attribute, got %v", spans[0].Attributes())
+	}
+}
+
+func TestWrapTransportPropagation(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		propagate bool
+	}{
+		{name: "propagate", propagate: true},
+		{name: "no propagate", propagate: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := useRecorder(t, tc.propagate)
+			var traceparent string
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				traceparent = r.Header.Get("traceparent")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+			defer upstream.Close()
+
+			ctx, parent := Start(context.Background(), "parent")
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, upstream.URL+"/v1/messages", nil)
+			if err != nil {
+				t.Fatalf("new request: %v", err)
+			}
+			client := &http.Client{Transport: WrapTransport(http.DefaultTransport)}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("do: %v", err)
+			}
+			_ = resp.Body.Close()
+			parent.End()
+
+			if tc.propagate && traceparent == "" {
+				t.Fatal("expected traceparent to be sent upstream")
+			}
+			if !tc.propagate && traceparent != "" {
+				t.Fatalf("expected no traceparent, got %q", traceparent)
+			}
+			if req.Header.Get("traceparent") != "" {
+				t.Fatal("caller request headers must not be mutated")
+			}
+			spans := recorder.Ended()
+			if len(spans) != 2 {
+				t.Fatalf("expected 2 spans, got %d", len(spans))
+			}
+			upstreamSpan := spans[0]
+			if upstreamSpan.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Fatal("expected the upstream span to be a child of the caller span")
+			}
+			if upstreamSpan.Status().Code.String() != "Error" {
+				t.Fatalf("expected error status for 429, got %v", upstreamSpan.Status())
+			}
+		})
+	}
+}
+
+func TestGinMiddlewareContinuesClientTrace(t *testing.T) {
+	recorder := useRecorder(t, false)
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(GinMiddleware())
+	engine.GET("/v1/models", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if got := spans[0].SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected inbound trace id to be continued, got %s", got)
+	}
+	if spans[0].Name() != "GET /v1/models" {
+		t.Fatalf("unexpected span name %q", spans[0].Name())
+	}
+}
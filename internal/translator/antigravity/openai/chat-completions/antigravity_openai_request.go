@@ -8,6 +8,7 @@ import (
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/structured"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
@@ -82,6 +83,8 @@ func ConvertOpenAIRequestToAntigravity(modelName string, inputRawJSON []byte, _
 				responseMods = append(responseMods, "TEXT")
 			case "image":
 				responseMods = append(responseMods, "IMAGE")
+			case "audio":
+				responseMods = append(responseMods, "AUDIO")
 			}
 		}
 		if len(responseMods) > 0 {
@@ -89,6 +92,11 @@ func ConvertOpenAIRequestToAntigravity(modelName string, inputRawJSON []byte, _
 		}
 	}
 
+	// OpenAI audio.voice -> Gemini speechConfig prebuilt voice
+	if voice := gjson.GetBytes(rawJSON, "audio.voice"); voice.Type == gjson.String && voice.String() != "" {
+		out, _ = sjson.SetBytes(out, "request.generationConfig.speechConfig.voiceConfig.prebuiltVoiceConfig.voiceName", misc.GeminiVoice(voice.String()))
+	}
+
 	// OpenRouter-style image_config support
 	// If the input uses top-level image_config.aspect_ratio, map it into request.generationConfig.imageConfig.aspectRatio.
 	if imgCfg := gjson.GetBytes(rawJSON, "image_config"); imgCfg.Exists() && imgCfg.IsObject() {
@@ -100,6 +108,11 @@ func ConvertOpenAIRequestToAntigravity(modelName string, inputRawJSON []byte, _
 		}
 	}
 
+	// response_format -> request.generationConfig.responseMimeType / responseJsonSchema
+	if format, ok := structured.FromOpenAI(rawJSON); ok {
+		out = structured.ApplyGemini(out, "request.generationConfig", format)
+	}
+
 	// messages -> systemInstruction + contents
 	messages := gjson.GetBytes(rawJSON, "messages")
 	if messages.IsArray() {
@@ -207,6 +220,13 @@ func ConvertOpenAIRequestToAntigravity(modelName string, inputRawJSON []byte, _
 							} else {
 								log.Warnf("Unknown file name extension '%s' in user message, skip", ext)
 							}
+						case "input_audio":
+							audioData := item.Get("input_audio.data").String()
+							if audioData != "" {
+								node, _ = sjson.SetBytes(node, "parts."+itoa(p)+".inlineData.mime_type", misc.AudioMimeType(item.Get("input_audio.format").String()))
+								node, _ = sjson.SetBytes(node, "parts."+itoa(p)+".inlineData.data", audioData)
+								p++
+							}
 						}
 					}
 				}
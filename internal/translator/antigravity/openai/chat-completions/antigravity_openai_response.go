@@ -15,6 +15,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	. "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/openai/chat-completions"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -24,6 +25,7 @@ import (
 type convertCliResponseToOpenAIChatParams struct {
 	UnixTimestamp        int64
 	FunctionIndex        int
+	ImageIndex           int
 	SawToolCall          bool   // Tracks if any tool call was seen in the entire stream
 	UpstreamFinishReason string // Caches the upstream finish reason for final chunk
 }
@@ -113,6 +115,8 @@ func ConvertAntigravityResponseToOpenAI(_ context.Context, _ string, originalReq
 
 	// Process the main content part of the response.
 	partsResult := gjson.GetBytes(rawJSON, "response.candidates.0.content.parts")
+	var audioParts []string
+	audioMimeType := ""
 	if partsResult.IsArray() {
 		partResults := partsResult.Array()
 		for i := 0; i < len(partResults); i++ {
@@ -170,13 +174,19 @@ func ConvertAntigravityResponseToOpenAI(_ context.Context, _ string, originalReq
 				template, _ = sjson.SetRaw(template, "choices.0.delta.tool_calls.-1", functionCallTemplate)
 			} else if inlineDataResult.Exists() {
 				data := inlineDataResult.Get("data").String()
-				if data == "" {
+				// Draft images produced while thinking are not part of the answer.
+				if data == "" || partResult.Get("thought").Bool() {
 					continue
 				}
 				mimeType := inlineDataResult.Get("mimeType").String()
 				if mimeType == "" {
 					mimeType = inlineDataResult.Get("mime_type").String()
 				}
+				if misc.IsAudioMimeType(mimeType) {
+					audioParts = append(audioParts, data)
+					audioMimeType = mimeType
+					continue
+				}
 				if mimeType == "" {
 					mimeType = "image/png"
 				}
@@ -185,7 +195,8 @@ func ConvertAntigravityResponseToOpenAI(_ context.Context, _ string, originalReq
 				if !imagesResult.Exists() || !imagesResult.IsArray() {
 					template, _ = sjson.SetRaw(template, "choices.0.delta.images", `[]`)
 				}
-				imageIndex := len(gjson.Get(template, "choices.0.delta.images").Array())
+				imageIndex := (*param).(*convertCliResponseToOpenAIChatParams).ImageIndex
+				(*param).(*convertCliResponseToOpenAIChatParams).ImageIndex++
 				imagePayload := `{"type":"image_url","image_url":{"url":""}}`
 				imagePayload, _ = sjson.Set(imagePayload, "index", imageIndex)
 				imagePayload, _ = sjson.Set(imagePayload, "image_url.url", imageURL)
@@ -195,6 +206,12 @@ func ConvertAntigravityResponseToOpenAI(_ context.Context, _ string, originalReq
 		}
 	}
 
+	if len(audioParts) > 0 {
+		template, _ = sjson.Set(template, "choices.0.delta.role", "assistant")
+		template, _ = sjson.Set(template, "choices.0.delta.audio.id", "audio_"+gjson.Get(template, "id").String())
+		template, _ = sjson.Set(template, "choices.0.delta.audio.data", misc.OpenAIAudioData(audioParts, audioMimeType, ""))
+	}
+
 	// Determine finish_reason only on the final chunk (has both finishReason and usage metadata)
 	params := (*param).(*convertCliResponseToOpenAIChatParams)
 	upstreamFinishReason := params.UpstreamFinishReason
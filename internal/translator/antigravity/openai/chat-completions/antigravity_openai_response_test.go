@@ -2,6 +2,8 @@ package chat_completions
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/binary"
 	"testing"
 
 	"github.com/tidwall/gjson"
@@ -126,3 +128,54 @@ func TestNoFinishReasonOnIntermediateChunks(t *testing.T) {
 		t.Errorf("Expected no finish_reason on intermediate chunk, got: %v", fr2)
 	}
 }
+
+func TestImagesSkipThoughtDraftsAndKeepIndexAcrossChunks(t *testing.T) {
+	ctx := context.Background()
+	var param any
+
+	// Draft image emitted while thinking, then two final images in separate chunks.
+	draft := []byte(`{"response":{"candidates":[{"content":{"parts":[{"thought":true,"inlineData":{"mimeType":"image/png","data":"ZHJhZnQ="}}]}}]}}`)
+	if images := gjson.Get(ConvertAntigravityResponseToOpenAI(ctx, "model", nil, nil, draft, &param)[0], "choices.0.delta.images"); images.Exists() {
+		t.Fatalf("thought image exposed: %s", images.Raw)
+	}
+
+	first := []byte(`{"response":{"candidates":[{"content":{"parts":[{"inlineData":{"mimeType":"image/jpeg","data":"Zmlyc3Q="}}]}}]}}`)
+	image := gjson.Get(ConvertAntigravityResponseToOpenAI(ctx, "model", nil, nil, first, &param)[0], "choices.0.delta.images.0")
+	if image.Get("index").Int() != 0 || image.Get("image_url.url").String() != "data:image/jpeg;base64,Zmlyc3Q=" {
+		t.Fatalf("first image = %s", image.Raw)
+	}
+
+	second := []byte(`{"response":{"candidates":[{"content":{"parts":[{"inlineData":{"mimeType":"image/png","data":"c2Vjb25k"}}]}}]}}`)
+	image = gjson.Get(ConvertAntigravityResponseToOpenAI(ctx, "model", nil, nil, second, &param)[0], "choices.0.delta.images.0")
+	if got := image.Get("index").Int(); got != 1 {
+		t.Fatalf("second image index = %d, want 1", got)
+	}
+}
+
+func TestAudioOutputBecomesOpenAIAudio(t *testing.T) {
+	ctx := context.Background()
+	var param any
+
+	chunk := []byte(`{"response":{"responseId":"resp-1","candidates":[{"content":{"parts":[{"inlineData":{"mimeType":"audio/L16;codec=pcm;rate=24000","data":"AAE="}},{"inlineData":{"mimeType":"audio/L16;codec=pcm;rate=24000","data":"AgM="}}]}}]}}`)
+	delta := gjson.Get(ConvertAntigravityResponseToOpenAI(ctx, "model", nil, nil, chunk, &param)[0], "choices.0.delta")
+	if delta.Get("images").Exists() {
+		t.Fatalf("audio exposed as image: %s", delta.Raw)
+	}
+	if got := delta.Get("audio.data").String(); got != "AAECAw==" {
+		t.Fatalf("delta.audio.data = %q, want both parts joined", got)
+	}
+	if got := delta.Get("audio.id").String(); got != "audio_resp-1" {
+		t.Fatalf("delta.audio.id = %q", got)
+	}
+
+	response := []byte(`{"response":{"responseId":"resp-2","candidates":[{"content":{"parts":[{"inlineData":{"mimeType":"audio/L16;codec=pcm;rate=16000","data":"AAECAw=="}}]},"finishReason":"STOP"}]}}`)
+	original := []byte(`{"modalities":["audio"],"audio":{"voice":"alloy","format":"wav"}}`)
+	out := ConvertAntigravityResponseToOpenAINonStream(ctx, "model", original, nil, response, &param)
+	wav, err := base64.StdEncoding.DecodeString(gjson.Get(out, "choices.0.message.audio.data").String())
+	if err != nil || len(wav) != 48 || string(wav[:4]) != "RIFF" {
+		t.Fatalf("message.audio.data is not a wav of the PCM: %v %q", err, wav)
+	}
+	if rate := binary.LittleEndian.Uint32(wav[24:]); rate != 16000 {
+		t.Fatalf("wav sample rate = %d, want 16000", rate)
+	}
+}
@@ -0,0 +1,74 @@
+// Package claude provides same-format request handling for the Claude Messages API.
+// It validates inbound requests before they are dispatched and smooths over client
+// quirks that the Anthropic API would otherwise reject.
+package claude
+
+import (
+	"fmt"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertClaudeRequestToClaude normalizes Claude Messages requests.
+//   - Drops empty text blocks from system and message content, which the API rejects,
+//     unless removing them would leave the content empty.
+//   - Removes an empty string system prompt.
+//   - Decodes tool_use inputs sent as JSON strings and fills in missing inputs.
+//
+// It keeps the payload otherwise unchanged.
+func ConvertClaudeRequestToClaude(_ string, inputRawJSON []byte, _ bool) []byte {
+	out := inputRawJSON
+
+	system := gjson.GetBytes(out, "system")
+	if system.Type == gjson.String && system.String() == "" {
+		out, _ = sjson.DeleteBytes(out, "system")
+	} else if system.IsArray() {
+		out = dropEmptyTextBlocks(out, "system", system)
+	}
+
+	messages := gjson.GetBytes(out, "messages").Array()
+	for i := len(messages) - 1; i >= 0; i-- {
+		contentPath := fmt.Sprintf("messages.%d.content", i)
+		content := messages[i].Get("content")
+		if !content.IsArray() {
+			continue
+		}
+		for j, block := range content.Array() {
+			if block.Get("type").String() != "tool_use" {
+				continue
+			}
+			inputPath := fmt.Sprintf("%s.%d.input", contentPath, j)
+			input := block.Get("input")
+			switch {
+			case !input.Exists() || input.Type == gjson.Null:
+				out, _ = sjson.SetRawBytes(out, inputPath, []byte("{}"))
+			case input.Type == gjson.String:
+				decoded := gjson.Parse(input.String())
+				if gjson.Valid(input.String()) && decoded.IsObject() {
+					out, _ = sjson.SetRawBytes(out, inputPath, []byte(decoded.Raw))
+				}
+			}
+		}
+		out = dropEmptyTextBlocks(out, contentPath, gjson.GetBytes(out, contentPath))
+	}
+	return out
+}
+
+// dropEmptyTextBlocks removes text blocks with empty text from the block array at path.
+func dropEmptyTextBlocks(rawJSON []byte, path string, blocks gjson.Result) []byte {
+	items := blocks.Array()
+	var empty []int
+	for i, block := range items {
+		if block.Get("type").String() == "text" && block.Get("text").String() == "" {
+			empty = append(empty, i)
+		}
+	}
+	if len(empty) == 0 || len(empty) == len(items) {
+		return rawJSON
+	}
+	for i := len(empty) - 1; i >= 0; i-- {
+		rawJSON, _ = sjson.DeleteBytes(rawJSON, fmt.Sprintf("%s.%d", path, empty[i]))
+	}
+	return rawJSON
+}
@@ -0,0 +1,83 @@
+package claude
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertClaudeRequestToClaude_NormalizesQuirks(t *testing.T) {
+	input := []byte(`{
+		"model": "claude-sonnet-4-5",
+		"system": "",
+		"messages": [
+			{"role": "user", "content": [{"type": "text", "text": ""}, {"type": "text", "text": "hi"}]},
+			{"role": "assistant", "content": [
+				{"type": "tool_use", "id": "toolu_1", "name": "lookup", "input": "{\"q\":\"x\"}"},
+				{"type": "tool_use", "id": "toolu_2", "name": "ping"}
+			]},
+			{"role": "user", "content": [{"type": "text", "text": ""}]}
+		]
+	}`)
+
+	out := ConvertClaudeRequestToClaude("claude-sonnet-4-5", input, false)
+
+	if gjson.GetBytes(out, "system").Exists() {
+		t.Fatalf("empty system prompt was kept: %s", out)
+	}
+	if got := gjson.GetBytes(out, "messages.0.content.#").Int(); got != 1 {
+		t.Fatalf("messages.0.content has %d blocks, want empty text dropped", got)
+	}
+	if got := gjson.GetBytes(out, "messages.1.content.0.input.q").String(); got != "x" {
+		t.Fatalf("string tool input was not decoded: %s", out)
+	}
+	if got := gjson.GetBytes(out, "messages.1.content.1.input").Raw; got != "{}" {
+		t.Fatalf("missing tool input = %q, want {}", got)
+	}
+	if got := gjson.GetBytes(out, "messages.2.content.#").Int(); got != 1 {
+		t.Fatalf("sole empty text block was removed: %s", out)
+	}
+}
+
+func TestValidateClaudeRequest(t *testing.T) {
+	valid := `{
+		"model": "claude-sonnet-4-5",
+		"max_tokens": 64,
+		"system": [{"type": "text", "text": "be brief"}],
+		"tools": [
+			{"name": "lookup", "input_schema": {"type": "object"}},
+			{"type": "web_search_20250305", "name": "web_search"}
+		],
+		"tool_choice": {"type": "tool", "name": "lookup"},
+		"messages": [
+			{"role": "user", "content": "hi"},
+			{"role": "assistant", "content": [{"type": "tool_use", "id": "toolu_1", "name": "lookup", "input": {}}]},
+			{"role": "user", "content": [{"type": "tool_result", "tool_use_id": "toolu_1", "content": "ok"}]}
+		]
+	}`
+	if err := ValidateClaudeRequest([]byte(valid)); err != nil {
+		t.Fatalf("ValidateClaudeRequest(valid) = %v", err)
+	}
+
+	cases := map[string]struct {
+		body string
+		want string
+	}{
+		"invalid json":      {`{"messages":`, "valid JSON"},
+		"missing messages":  {`{"model":"m"}`, "messages:"},
+		"bad role":          {`{"messages":[{"role":"system","content":"x"}]}`, "messages.0.role"},
+		"untyped block":     {`{"messages":[{"role":"user","content":[{"text":"x"}]}]}`, "messages.0.content.0.type"},
+		"tool_use id":       {`{"messages":[{"role":"assistant","content":[{"type":"tool_use","name":"f","input":{}}]}]}`, "messages.0.content.0.id"},
+		"tool_result id":    {`{"messages":[{"role":"user","content":[{"type":"tool_result","content":"x"}]}]}`, "tool_use_id"},
+		"tool schema":       {`{"messages":[],"tools":[{"name":"f"}]}`, "tools.0.input_schema"},
+		"tool choice name":  {`{"messages":[],"tool_choice":{"type":"tool"}}`, "tool_choice.name"},
+		"system block type": {`{"messages":[],"system":[{"type":"image"}]}`, "system.0.type"},
+	}
+	for name, tc := range cases {
+		err := ValidateClaudeRequest([]byte(tc.body))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: ValidateClaudeRequest() = %v, want error mentioning %q", name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package claude
+
+import "context"
+
+// PassthroughClaudeResponseStream forwards Claude SSE lines unchanged.
+func PassthroughClaudeResponseStream(_ context.Context, _ string, _, _, rawJSON []byte, _ *any) []string {
+	return []string{string(rawJSON)}
+}
+
+// PassthroughClaudeResponseNonStream forwards Claude responses unchanged.
+func PassthroughClaudeResponseNonStream(_ context.Context, _ string, _, _, rawJSON []byte, _ *any) string {
+	return string(rawJSON)
+}
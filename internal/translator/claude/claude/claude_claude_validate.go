@@ -0,0 +1,147 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+)
+
+// ValidateClaudeRequest checks the parts of a Claude Messages request that the proxy relies on:
+// message roles, content block shapes, the system prompt and tool definitions. Error messages use
+// the field paths the Anthropic API reports so clients see the same diagnostics either way.
+// Quirks that ConvertClaudeRequestToClaude repairs are accepted here.
+func ValidateClaudeRequest(rawJSON []byte) error {
+	if !gjson.ValidBytes(rawJSON) {
+		return errors.New("request body must be valid JSON")
+	}
+	root := gjson.ParseBytes(rawJSON)
+	if !root.IsObject() {
+		return errors.New("request body must be a JSON object")
+	}
+
+	messages := root.Get("messages")
+	if !messages.IsArray() {
+		return errors.New("messages: Field required and must be an array")
+	}
+	for i, message := range messages.Array() {
+		if err := validateClaudeMessage(fmt.Sprintf("messages.%d", i), message); err != nil {
+			return err
+		}
+	}
+
+	if system := root.Get("system"); system.Exists() && system.Type != gjson.String {
+		if !system.IsArray() {
+			return errors.New("system: Input should be a string or an array of text blocks")
+		}
+		for i, block := range system.Array() {
+			path := fmt.Sprintf("system.%d", i)
+			if block.Get("type").String() != "text" {
+				return fmt.Errorf("%s.type: Input should be 'text'", path)
+			}
+			if block.Get("text").Type != gjson.String {
+				return fmt.Errorf("%s.text: Field required", path)
+			}
+		}
+	}
+
+	if tools := root.Get("tools"); tools.Exists() {
+		if !tools.IsArray() {
+			return errors.New("tools: Input should be an array")
+		}
+		for i, tool := range tools.Array() {
+			if err := validateClaudeTool(fmt.Sprintf("tools.%d", i), tool); err != nil {
+				return err
+			}
+		}
+	}
+
+	if choice := root.Get("tool_choice"); choice.Exists() {
+		switch choice.Get("type").String() {
+		case "auto", "any", "none":
+		case "tool":
+			if choice.Get("name").String() == "" {
+				return errors.New("tool_choice.name: Field required")
+			}
+		default:
+			return errors.New("tool_choice.type: Input should be 'auto', 'any', 'tool' or 'none'")
+		}
+	}
+	return nil
+}
+
+func validateClaudeMessage(path string, message gjson.Result) error {
+	if !message.IsObject() {
+		return fmt.Errorf("%s: Input should be an object", path)
+	}
+	if role := message.Get("role").String(); role != "user" && role != "assistant" {
+		return fmt.Errorf("%s.role: Input should be 'user' or 'assistant'", path)
+	}
+	content := message.Get("content")
+	if content.Type == gjson.String {
+		return nil
+	}
+	if !content.IsArray() {
+		return fmt.Errorf("%s.content: Input should be a string or an array of content blocks", path)
+	}
+	for i, block := range content.Array() {
+		if err := validateClaudeContentBlock(fmt.Sprintf("%s.content.%d", path, i), block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateClaudeContentBlock(path string, block gjson.Result) error {
+	if !block.IsObject() {
+		return fmt.Errorf("%s: Input should be an object", path)
+	}
+	blockType := block.Get("type").String()
+	switch blockType {
+	case "":
+		return fmt.Errorf("%s.type: Field required", path)
+	case "text":
+		if block.Get("text").Type != gjson.String {
+			return fmt.Errorf("%s.text: Field required", path)
+		}
+	case "image", "document":
+		if !block.Get("source").IsObject() {
+			return fmt.Errorf("%s.source: Field required", path)
+		}
+	case "tool_use", "server_tool_use":
+		if block.Get("id").String() == "" {
+			return fmt.Errorf("%s.id: Field required", path)
+		}
+		if block.Get("name").String() == "" {
+			return fmt.Errorf("%s.name: Field required", path)
+		}
+		// A JSON-encoded string input is a common client quirk and is decoded during normalization.
+		if input := block.Get("input"); input.Exists() && !input.IsObject() && input.Type != gjson.String && input.Type != gjson.Null {
+			return fmt.Errorf("%s.input: Input should be an object", path)
+		}
+	case "tool_result":
+		if block.Get("tool_use_id").String() == "" {
+			return fmt.Errorf("%s.tool_use_id: Field required", path)
+		}
+		if content := block.Get("content"); content.Exists() && content.Type != gjson.String && !content.IsArray() {
+			return fmt.Errorf("%s.content: Input should be a string or an array of content blocks", path)
+		}
+	}
+	return nil
+}
+
+func validateClaudeTool(path string, tool gjson.Result) error {
+	if !tool.IsObject() {
+		return fmt.Errorf("%s: Input should be an object", path)
+	}
+	if tool.Get("name").String() == "" {
+		return fmt.Errorf("%s.name: Field required", path)
+	}
+	// Server tools such as web_search carry a versioned type and no input schema.
+	if toolType := tool.Get("type").String(); toolType == "" || toolType == "custom" {
+		if !tool.Get("input_schema").IsObject() {
+			return fmt.Errorf("%s.input_schema: Field required", path)
+		}
+	}
+	return nil
+}
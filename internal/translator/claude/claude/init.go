@@ -0,0 +1,22 @@
+package claude
+
+import (
+	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/translator"
+)
+
+// Register a passthrough response translator and a request normalizer for Claude→Claude,
+// plus the validator that rejects malformed Claude Messages requests at the handler.
+func init() {
+	translator.Register(
+		Claude,
+		Claude,
+		ConvertClaudeRequestToClaude,
+		interfaces.TranslateResponse{
+			Stream:    PassthroughClaudeResponseStream,
+			NonStream: PassthroughClaudeResponseNonStream,
+		},
+	)
+	translator.RegisterValidator(Claude, ValidateClaudeRequest)
+}
@@ -0,0 +1,31 @@
+// Package common holds helpers shared by translators that read Claude Messages requests.
+package common
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// DocumentText returns the text carried by a text or custom-content document source, or an
+// empty string for other source types.
+func DocumentText(source gjson.Result) string {
+	switch source.Get("type").String() {
+	case "text":
+		return source.Get("data").String()
+	case "content":
+		content := source.Get("content")
+		if content.Type == gjson.String {
+			return content.String()
+		}
+		var texts []string
+		content.ForEach(func(_, block gjson.Result) bool {
+			if block.Get("type").String() == "text" {
+				texts = append(texts, block.Get("text").String())
+			}
+			return true
+		})
+		return strings.Join(texts, "\n")
+	}
+	return ""
+}
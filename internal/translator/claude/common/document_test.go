@@ -0,0 +1,27 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestDocumentText(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "text", source: `{"type":"text","media_type":"text/plain","data":"plain notes"}`, want: "plain notes"},
+		{name: "content string", source: `{"type":"content","content":"inline"}`, want: "inline"},
+		{name: "content blocks", source: `{"type":"content","content":[{"type":"text","text":"a"},{"type":"image"},{"type":"text","text":"b"}]}`, want: "a\nb"},
+		{name: "base64", source: `{"type":"base64","data":"JVBERi0x"}`, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DocumentText(gjson.Parse(tt.source)); got != tt.want {
+				t.Fatalf("DocumentText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
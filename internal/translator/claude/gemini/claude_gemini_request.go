@@ -15,6 +15,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/structured"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -370,5 +371,10 @@ func ConvertGeminiRequestToClaude(modelName string, inputRawJSON []byte, stream
 		out, _ = sjson.Set(out, fullPath, strings.ToLower(gjson.Get(out, fullPath).String()))
 	}
 
+	// A response schema or JSON mime type is emulated with a forced tool call.
+	if format, ok := structured.FromGemini(root.Get("generationConfig")); ok {
+		return structured.ApplyClaude([]byte(out), format)
+	}
+
 	return []byte(out)
 }
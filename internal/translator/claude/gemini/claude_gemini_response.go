@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/structured"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -54,6 +55,7 @@ type ConvertAnthropicResponseToGeminiParams struct {
 // Returns:
 //   - []string: A slice of strings, each containing a Gemini-compatible JSON response
 func ConvertClaudeResponseToGemini(_ context.Context, modelName string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, param *any) []string {
+	rawJSON = structured.UnwrapClaudeResponse(requestRawJSON, rawJSON)
 	if *param == nil {
 		*param = &ConvertAnthropicResponseToGeminiParams{
 			Model:      modelName,
@@ -277,6 +279,7 @@ func ConvertClaudeResponseToGemini(_ context.Context, modelName string, original
 // Returns:
 //   - string: A Gemini-compatible JSON response containing all message content and metadata
 func ConvertClaudeResponseToGeminiNonStream(_ context.Context, modelName string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, _ *any) string {
+	rawJSON = structured.UnwrapClaudeResponse(requestRawJSON, rawJSON)
 	// Base Gemini response template for non-streaming with default values
 	template := `{"candidates":[{"content":{"role":"model","parts":[]},"finishReason":"STOP"}],"usageMetadata":{"trafficType":"PROVISIONED_THROUGHPUT"},"modelVersion":"","createTime":"","responseId":""}`
 
@@ -8,13 +8,16 @@ package chat_completions
 import (
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"math/big"
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/structured"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -199,6 +202,11 @@ func ConvertOpenAIRequestToClaude(modelName string, inputRawJSON []byte, stream
 									msg, _ = sjson.SetRaw(msg, "content.-1", imagePart)
 								}
 							}
+
+						case "file":
+							if documentPart, ok := convertOpenAIFileToClaudeDocument(part.Get("file")); ok {
+								msg, _ = sjson.SetRaw(msg, "content.-1", documentPart)
+							}
 						}
 						return true
 					})
@@ -312,5 +320,58 @@ func ConvertOpenAIRequestToClaude(modelName string, inputRawJSON []byte, stream
 		}
 	}
 
+	// Claude has no response_format; structured output is emulated with a forced tool call.
+	if format, ok := structured.FromOpenAI(rawJSON); ok {
+		return structured.ApplyClaude([]byte(out), format)
+	}
+
 	return []byte(out)
 }
+
+// convertOpenAIFileToClaudeDocument maps a chat-completions file part to a Claude document block.
+// Data URLs and bare base64 payloads become base64 sources (text/plain files are decoded into text
+// sources, which is what Claude requires for them) and http(s) URLs become URL sources. Parts that
+// only reference an uploaded file_id cannot be resolved and are dropped.
+func convertOpenAIFileToClaudeDocument(file gjson.Result) (string, bool) {
+	fileData := strings.TrimSpace(file.Get("file_data").String())
+	if fileData == "" {
+		return "", false
+	}
+	filename := file.Get("filename").String()
+	document := `{"type":"document","source":{}}`
+	if strings.HasPrefix(fileData, "http://") || strings.HasPrefix(fileData, "https://") {
+		document, _ = sjson.Set(document, "source.type", "url")
+		document, _ = sjson.Set(document, "source.url", fileData)
+	} else {
+		mediaType, data := "", fileData
+		if strings.HasPrefix(fileData, "data:") {
+			header, payload, ok := strings.Cut(strings.TrimPrefix(fileData, "data:"), ";base64,")
+			if !ok || payload == "" {
+				return "", false
+			}
+			mediaType, data = header, payload
+		}
+		if mediaType == "" {
+			mediaType = "application/pdf"
+			if dot := strings.LastIndex(filename, "."); dot >= 0 {
+				if byExt, ok := misc.MimeTypes[strings.ToLower(filename[dot+1:])]; ok {
+					mediaType = byExt
+				}
+			}
+		}
+		decoded, errDecode := base64.StdEncoding.DecodeString(data)
+		if mediaType == "text/plain" && errDecode == nil {
+			document, _ = sjson.Set(document, "source.type", "text")
+			document, _ = sjson.Set(document, "source.media_type", mediaType)
+			document, _ = sjson.Set(document, "source.data", string(decoded))
+		} else {
+			document, _ = sjson.Set(document, "source.type", "base64")
+			document, _ = sjson.Set(document, "source.media_type", mediaType)
+			document, _ = sjson.Set(document, "source.data", data)
+		}
+	}
+	if filename != "" {
+		document, _ = sjson.Set(document, "title", filename)
+	}
+	return document, true
+}
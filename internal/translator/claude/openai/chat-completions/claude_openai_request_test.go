@@ -0,0 +1,64 @@
+package chat_completions
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOpenAIRequestToClaude_FileParts(t *testing.T) {
+	input := []byte(`{
+		"model": "claude-sonnet-4",
+		"messages": [{
+			"role": "user",
+			"content": [
+				{"type": "file", "file": {"filename": "report.pdf", "file_data": "data:application/pdf;base64,JVBERi0x"}},
+				{"type": "file", "file": {"filename": "notes.txt", "file_data": "aGVsbG8="}},
+				{"type": "file", "file": {"file_data": "https://example.com/a.pdf"}},
+				{"type": "file", "file": {"file_id": "file-123"}},
+				{"type": "text", "text": "Summarize"}
+			]
+		}]
+	}`)
+
+	content := gjson.GetBytes(ConvertOpenAIRequestToClaude("claude-sonnet-4", input, false), "messages.0.content")
+	if n := len(content.Array()); n != 4 {
+		t.Fatalf("expected 4 content blocks, got %d: %s", n, content.Raw)
+	}
+	pdf := content.Get("0")
+	if pdf.Get("type").String() != "document" || pdf.Get("source.type").String() != "base64" {
+		t.Fatalf("pdf block = %s", pdf.Raw)
+	}
+	if pdf.Get("source.media_type").String() != "application/pdf" || pdf.Get("source.data").String() != "JVBERi0x" {
+		t.Fatalf("pdf source = %s", pdf.Get("source").Raw)
+	}
+	if pdf.Get("title").String() != "report.pdf" {
+		t.Fatalf("pdf title = %q", pdf.Get("title").String())
+	}
+	text := content.Get("1")
+	if text.Get("source.type").String() != "text" || text.Get("source.data").String() != "hello" {
+		t.Fatalf("text document = %s", text.Raw)
+	}
+	url := content.Get("2")
+	if url.Get("source.type").String() != "url" || url.Get("source.url").String() != "https://example.com/a.pdf" {
+		t.Fatalf("url document = %s", url.Raw)
+	}
+}
+
+func TestConvertOpenAIRequestToClaude_ResponseFormatWithForcedTool(t *testing.T) {
+	input := []byte(`{
+		"model": "claude-sonnet-4",
+		"messages": [{"role": "user", "content": "Weather in Paris?"}],
+		"tools": [{"type": "function", "function": {"name": "get_weather", "parameters": {"type": "object"}}}],
+		"tool_choice": {"type": "function", "function": {"name": "get_weather"}},
+		"response_format": {"type": "json_schema", "json_schema": {"name": "answer", "schema": {"type": "object"}}}
+	}`)
+
+	out := ConvertOpenAIRequestToClaude("claude-sonnet-4", input, false)
+	if got := gjson.GetBytes(out, "tool_choice.name").String(); got != "get_weather" {
+		t.Fatalf("tool_choice = %s, want the client's forced get_weather", gjson.GetBytes(out, "tool_choice").Raw)
+	}
+	if n := len(gjson.GetBytes(out, "tools").Array()); n != 1 {
+		t.Fatalf("tools = %s, want only the client's tool", gjson.GetBytes(out, "tools").Raw)
+	}
+}
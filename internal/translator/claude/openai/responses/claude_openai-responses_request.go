@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/structured"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -335,5 +336,10 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 		}
 	}
 
+	// text.format has no Claude counterpart; emulate it with a forced tool call.
+	if format, ok := structured.FromResponses(rawJSON); ok {
+		return structured.ApplyClaude([]byte(out), format)
+	}
+
 	return []byte(out)
 }
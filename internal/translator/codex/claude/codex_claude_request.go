@@ -11,6 +11,8 @@ import (
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	claudecommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/claude/common"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/structured"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -103,6 +105,12 @@ func ConvertClaudeRequestToCodex(modelName string, inputRawJSON []byte, _ bool)
 				hasContent = true
 			}
 
+			appendFileContent := func(file string) {
+				message, _ = sjson.SetRaw(message, fmt.Sprintf("content.%d", contentIndex), file)
+				contentIndex++
+				hasContent = true
+			}
+
 			messageContentsResult := messageResult.Get("content")
 			if messageContentsResult.IsArray() {
 				messageContentResults := messageContentsResult.Array()
@@ -132,6 +140,12 @@ func ConvertClaudeRequestToCodex(modelName string, inputRawJSON []byte, _ bool)
 								appendImageContent(dataURL)
 							}
 						}
+					case "document":
+						if file, ok := convertClaudeDocumentToInputFile(messageContentResult); ok {
+							appendFileContent(file)
+						} else if text := claudecommon.DocumentText(messageContentResult.Get("source")); text != "" {
+							appendTextContent(text)
+						}
 					case "tool_use":
 						flushMessage()
 						functionCallMessage := `{"type":"function_call"}`
@@ -238,6 +252,11 @@ func ConvertClaudeRequestToCodex(modelName string, inputRawJSON []byte, _ bool)
 	template, _ = sjson.Set(template, "store", false)
 	template, _ = sjson.Set(template, "include", []string{"reasoning.encrypted_content"})
 
+	// output_format -> text.format
+	if format, ok := structured.FromClaude(rawJSON); ok {
+		return structured.ApplyResponses([]byte(template), format)
+	}
+
 	return []byte(template)
 }
 
@@ -353,3 +372,37 @@ func normalizeToolParameters(raw string) string {
 	}
 	return schema
 }
+
+// convertClaudeDocumentToInputFile maps a Claude document block with a base64 or URL source to a
+// Responses input_file part. Text sources are handled by the caller as plain text.
+func convertClaudeDocumentToInputFile(document gjson.Result) (string, bool) {
+	source := document.Get("source")
+	filename := document.Get("title").String()
+	switch source.Get("type").String() {
+	case "base64":
+		data := source.Get("data").String()
+		if data == "" {
+			return "", false
+		}
+		mediaType := source.Get("media_type").String()
+		if mediaType == "" {
+			mediaType = "application/pdf"
+		}
+		if filename == "" {
+			filename = "document.pdf"
+		}
+		file := `{"type":"input_file","filename":"","file_data":""}`
+		file, _ = sjson.Set(file, "filename", filename)
+		file, _ = sjson.Set(file, "file_data", fmt.Sprintf("data:%s;base64,%s", mediaType, data))
+		return file, true
+	case "url":
+		url := source.Get("url").String()
+		if url == "" {
+			return "", false
+		}
+		file := `{"type":"input_file","file_url":""}`
+		file, _ = sjson.Set(file, "file_url", url)
+		return file, true
+	}
+	return "", false
+}
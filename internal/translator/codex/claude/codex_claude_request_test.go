@@ -0,0 +1,41 @@
+package claude
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertClaudeRequestToCodex_DocumentBlocks(t *testing.T) {
+	input := []byte(`{
+		"model": "claude-sonnet-4",
+		"messages": [{
+			"role": "user",
+			"content": [
+				{"type": "document", "title": "report.pdf", "source": {"type": "base64", "media_type": "application/pdf", "data": "JVBERi0x"}},
+				{"type": "document", "source": {"type": "url", "url": "https://example.com/a.pdf"}},
+				{"type": "document", "source": {"type": "text", "media_type": "text/plain", "data": "plain notes"}}
+			]
+		}]
+	}`)
+
+	content := gjson.GetBytes(ConvertClaudeRequestToCodex("gpt-5", input, false), "input.0.content")
+	if n := len(content.Array()); n != 3 {
+		t.Fatalf("expected 3 content parts, got %d: %s", n, content.Raw)
+	}
+	if got := content.Get("0.type").String(); got != "input_file" {
+		t.Fatalf("part 0 type = %q", got)
+	}
+	if got := content.Get("0.filename").String(); got != "report.pdf" {
+		t.Fatalf("filename = %q", got)
+	}
+	if got := content.Get("0.file_data").String(); got != "data:application/pdf;base64,JVBERi0x" {
+		t.Fatalf("file_data = %q", got)
+	}
+	if got := content.Get("1.file_url").String(); got != "https://example.com/a.pdf" {
+		t.Fatalf("file_url = %q", got)
+	}
+	if got := content.Get("2.type").String(); got != "input_text" || content.Get("2.text").String() != "plain notes" {
+		t.Fatalf("text document part = %s", content.Get("2").Raw)
+	}
+}
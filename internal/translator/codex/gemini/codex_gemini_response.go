@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/tidwall/gjson"
@@ -213,10 +214,22 @@ func ConvertCodexResponseToGeminiNonStream(_ context.Context, modelName string,
 					// Flush any pending function calls before adding non-function content
 					flushPendingFunctionCalls()
 
-					// Add thinking content
-					if content := value.Get("content"); content.Exists() {
+					// Add thinking content; Codex reports reasoning as summary text parts
+					var thinking strings.Builder
+					value.Get("summary").ForEach(func(_, summary gjson.Result) bool {
+						if summary.Get("type").String() == "summary_text" {
+							thinking.WriteString(summary.Get("text").String())
+						}
+						return true
+					})
+					if thinking.Len() == 0 {
+						if content := value.Get("content"); content.Exists() {
+							thinking.WriteString(content.String())
+						}
+					}
+					if thinking.Len() > 0 {
 						part := `{"text":"","thought":true}`
-						part, _ = sjson.Set(part, "text", content.String())
+						part, _ = sjson.Set(part, "text", thinking.String())
 						template, _ = sjson.SetRaw(template, "candidates.0.content.parts.-1", part)
 					}
 
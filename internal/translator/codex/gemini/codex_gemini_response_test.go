@@ -0,0 +1,58 @@
+package gemini
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+// Codex reports reasoning as summary_text parts. The translation used to read only the reasoning
+// item's content, so summaries were dropped and no thought part was produced. It now joins the
+// summary parts and falls back to the content.
+func TestConvertCodexResponseToGeminiNonStream_ReasoningThought(t *testing.T) {
+	tests := []struct {
+		name      string
+		reasoning string
+		want      string
+	}{
+		{
+			// Previously dropped: the answer was the only part.
+			name:      "summary parts",
+			reasoning: `{"type":"reasoning","summary":[{"type":"summary_text","text":"Check the "},{"type":"summary_text","text":"forecast."}]}`,
+			want:      "Check the forecast.",
+		},
+		{
+			name:      "content fallback",
+			reasoning: `{"type":"reasoning","summary":[],"content":"Think first."}`,
+			want:      "Think first.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := []byte(`{"type":"response.completed","response":{"id":"resp_1","created_at":1700000000,"output":[` + tt.reasoning + `,{"type":"message","content":[{"type":"output_text","text":"Sunny."}]}]}}`)
+			out := ConvertCodexResponseToGeminiNonStream(context.Background(), "gpt-5", nil, nil, raw, nil)
+
+			thought := gjson.Get(out, "candidates.0.content.parts.0")
+			if !thought.Get("thought").Bool() {
+				t.Fatalf("first part = %s, want a thought part", thought.Raw)
+			}
+			if got := thought.Get("text").String(); got != tt.want {
+				t.Fatalf("thought text = %q, want %q", got, tt.want)
+			}
+			if got := gjson.Get(out, "candidates.0.content.parts.1.text").String(); got != "Sunny." {
+				t.Fatalf("answer text = %q, want Sunny.", got)
+			}
+		})
+	}
+}
+
+func TestConvertCodexResponseToGeminiNonStream_SkipsEmptyReasoning(t *testing.T) {
+	raw := []byte(`{"type":"response.completed","response":{"id":"resp_1","output":[{"type":"reasoning","summary":[]},{"type":"message","content":[{"type":"output_text","text":"Sunny."}]}]}}`)
+	out := ConvertCodexResponseToGeminiNonStream(context.Background(), "gpt-5", nil, nil, raw, nil)
+
+	if got := gjson.Get(out, "candidates.0.content.parts.#").Int(); got != 1 {
+		t.Fatalf("parts = %d, want only the answer: %s", got, out)
+	}
+}
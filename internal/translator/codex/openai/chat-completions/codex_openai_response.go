@@ -358,12 +358,17 @@ func ConvertCodexResponseToOpenAINonStream(_ context.Context, _ string, original
 		}
 	}
 
-	// Extract and set the finish reason based on status
+	// Extract and set the finish reason based on status, matching the streaming translation
+	// when the turn ends in tool calls.
 	if statusResult := responseResult.Get("status"); statusResult.Exists() {
 		status := statusResult.String()
 		if status == "completed" {
-			template, _ = sjson.Set(template, "choices.0.finish_reason", "stop")
-			template, _ = sjson.Set(template, "choices.0.native_finish_reason", "stop")
+			finishReason := "stop"
+			if gjson.Get(template, "choices.0.message.tool_calls.#").Int() > 0 {
+				finishReason = "tool_calls"
+			}
+			template, _ = sjson.Set(template, "choices.0.finish_reason", finishReason)
+			template, _ = sjson.Set(template, "choices.0.native_finish_reason", finishReason)
 		}
 	}
 
@@ -0,0 +1,45 @@
+package chat_completions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+// A completed response used to report finish_reason "stop" even when it ended in tool calls,
+// unlike the stream translation. It now reports "tool_calls" for such turns.
+func TestConvertCodexResponseToOpenAINonStream_FinishReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		old    string
+		want   string
+	}{
+		{
+			name:   "text",
+			output: `[{"type":"message","content":[{"type":"output_text","text":"done"}]}]`,
+			old:    "stop",
+			want:   "stop",
+		},
+		{
+			name:   "tool call",
+			output: `[{"type":"function_call","call_id":"call_1","name":"get_weather","arguments":"{\"city\":\"Paris\"}"}]`,
+			old:    "stop",
+			want:   "tool_calls",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := []byte(`{"type":"response.completed","response":{"id":"resp_1","model":"gpt-5","created_at":1700000000,"status":"completed","output":` + tt.output + `}}`)
+			out := ConvertCodexResponseToOpenAINonStream(context.Background(), "gpt-5", nil, nil, raw, nil)
+			if got := gjson.Get(out, "choices.0.finish_reason").String(); got != tt.want {
+				t.Fatalf("finish_reason = %q, want %q (was %q)", got, tt.want, tt.old)
+			}
+			if got := gjson.Get(out, "choices.0.native_finish_reason").String(); got != tt.want {
+				t.Fatalf("native_finish_reason = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
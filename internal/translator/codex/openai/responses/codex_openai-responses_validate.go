@@ -0,0 +1,106 @@
+package responses
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+)
+
+// ValidateOpenAIResponsesRequest checks the structure of an OpenAI Responses request: input items,
+// message roles and content parts, function call items and tool definitions. Item types it does not
+// know are left for the upstream to judge.
+func ValidateOpenAIResponsesRequest(rawJSON []byte) error {
+	if !gjson.ValidBytes(rawJSON) {
+		return errors.New("request body must be valid JSON")
+	}
+	root := gjson.ParseBytes(rawJSON)
+	if !root.IsObject() {
+		return errors.New("request body must be a JSON object")
+	}
+
+	if instructions := root.Get("instructions"); instructions.Exists() && instructions.Type != gjson.String && instructions.Type != gjson.Null {
+		return errors.New("invalid type for 'instructions': expected a string")
+	}
+
+	if input := root.Get("input"); input.Exists() && input.Type != gjson.String {
+		if !input.IsArray() {
+			return errors.New("invalid type for 'input': expected a string or an array of input items")
+		}
+		for i, item := range input.Array() {
+			if err := validateResponsesInputItem(fmt.Sprintf("input[%d]", i), item); err != nil {
+				return err
+			}
+		}
+	}
+
+	if tools := root.Get("tools"); tools.Exists() {
+		if !tools.IsArray() {
+			return errors.New("invalid type for 'tools': expected an array")
+		}
+		for i, tool := range tools.Array() {
+			path := fmt.Sprintf("tools[%d]", i)
+			if !tool.IsObject() {
+				return fmt.Errorf("invalid type for '%s': expected an object", path)
+			}
+			toolType := tool.Get("type").String()
+			if toolType == "" {
+				return fmt.Errorf("missing required parameter: '%s.type'", path)
+			}
+			if toolType == "function" && tool.Get("name").String() == "" {
+				return fmt.Errorf("missing required parameter: '%s.name'", path)
+			}
+		}
+	}
+	return nil
+}
+
+func validateResponsesInputItem(path string, item gjson.Result) error {
+	if !item.IsObject() {
+		return fmt.Errorf("invalid type for '%s': expected an object", path)
+	}
+	itemType := item.Get("type").String()
+	if itemType == "" && item.Get("role").Exists() {
+		itemType = "message"
+	}
+	switch itemType {
+	case "":
+		return fmt.Errorf("missing required parameter: '%s.type'", path)
+	case "message":
+		switch item.Get("role").String() {
+		case "user", "assistant", "system", "developer":
+		default:
+			return fmt.Errorf("invalid value for '%s.role': expected one of 'user', 'assistant', 'system' or 'developer'", path)
+		}
+		content := item.Get("content")
+		if content.Type == gjson.String {
+			return nil
+		}
+		if !content.IsArray() {
+			return fmt.Errorf("invalid type for '%s.content': expected a string or an array of content parts", path)
+		}
+		for i, part := range content.Array() {
+			if part.Get("type").String() == "" {
+				return fmt.Errorf("missing required parameter: '%s.content[%d].type'", path, i)
+			}
+		}
+	case "function_call":
+		if item.Get("call_id").String() == "" {
+			return fmt.Errorf("missing required parameter: '%s.call_id'", path)
+		}
+		if item.Get("name").String() == "" {
+			return fmt.Errorf("missing required parameter: '%s.name'", path)
+		}
+		if arguments := item.Get("arguments"); arguments.Exists() && arguments.Type != gjson.String {
+			return fmt.Errorf("invalid type for '%s.arguments': expected a string", path)
+		}
+	case "function_call_output":
+		if item.Get("call_id").String() == "" {
+			return fmt.Errorf("missing required parameter: '%s.call_id'", path)
+		}
+		if !item.Get("output").Exists() {
+			return fmt.Errorf("missing required parameter: '%s.output'", path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package responses
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateOpenAIResponsesRequest(t *testing.T) {
+	valid := `{
+		"model": "gpt-5.2",
+		"instructions": "be brief",
+		"input": [
+			{"role": "user", "content": "hi"},
+			{"type": "message", "role": "developer", "content": [{"type": "input_text", "text": "x"}]},
+			{"type": "function_call", "call_id": "call_1", "name": "lookup", "arguments": "{}"},
+			{"type": "function_call_output", "call_id": "call_1", "output": "ok"},
+			{"type": "reasoning", "summary": []}
+		],
+		"tools": [{"type": "function", "name": "lookup"}, {"type": "web_search"}]
+	}`
+	if err := ValidateOpenAIResponsesRequest([]byte(valid)); err != nil {
+		t.Fatalf("ValidateOpenAIResponsesRequest(valid) = %v", err)
+	}
+	if err := ValidateOpenAIResponsesRequest([]byte(`{"model":"gpt-5.2","input":"hi"}`)); err != nil {
+		t.Fatalf("ValidateOpenAIResponsesRequest(string input) = %v", err)
+	}
+
+	cases := map[string]struct {
+		body string
+		want string
+	}{
+		"invalid json":     {`{"input":`, "valid JSON"},
+		"input object":     {`{"input":{"role":"user"}}`, "'input'"},
+		"bad role":         {`{"input":[{"role":"tool","content":"x"}]}`, "input[0].role"},
+		"untyped item":     {`{"input":[{"content":"x"}]}`, "input[0].type"},
+		"untyped part":     {`{"input":[{"role":"user","content":[{"text":"x"}]}]}`, "input[0].content[0].type"},
+		"call id":          {`{"input":[{"type":"function_call","name":"f","arguments":"{}"}]}`, "input[0].call_id"},
+		"object arguments": {`{"input":[{"type":"function_call","call_id":"c","name":"f","arguments":{}}]}`, "input[0].arguments"},
+		"call output":      {`{"input":[{"type":"function_call_output","call_id":"c"}]}`, "input[0].output"},
+		"function name":    {`{"tools":[{"type":"function"}]}`, "tools[0].name"},
+		"tool type":        {`{"tools":[{"name":"f"}]}`, "tools[0].type"},
+	}
+	for name, tc := range cases {
+		err := ValidateOpenAIResponsesRequest([]byte(tc.body))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: ValidateOpenAIResponsesRequest() = %v, want error mentioning %q", name, err, tc.want)
+		}
+	}
+}
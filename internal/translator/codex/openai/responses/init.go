@@ -16,4 +16,5 @@ func init() {
 			NonStream: ConvertCodexResponseToOpenAIResponsesNonStream,
 		},
 	)
+	translator.RegisterValidator(OpenaiResponse, ValidateOpenAIResponsesRequest)
 }
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/structured"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -198,6 +199,10 @@ func ConvertClaudeRequestToCLI(modelName string, inputRawJSON []byte, _ bool) []
 	}
 
 	outBytes := []byte(out)
+	// output_format -> request.generationConfig.responseMimeType / responseJsonSchema
+	if format, ok := structured.FromClaude(rawJSON); ok {
+		outBytes = structured.ApplyGemini(outBytes, "request.generationConfig", format)
+	}
 	outBytes = common.AttachDefaultSafetySettings(outBytes, "request.safetySettings")
 
 	return outBytes
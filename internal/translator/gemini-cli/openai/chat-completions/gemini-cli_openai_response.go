@@ -13,6 +13,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	. "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/openai/chat-completions"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
@@ -23,6 +24,7 @@ import (
 type convertCliResponseToOpenAIChatParams struct {
 	UnixTimestamp int64
 	FunctionIndex int
+	ImageIndex    int
 }
 
 // functionCallIDCounter provides a process-wide unique counter for function call identifiers.
@@ -116,6 +118,8 @@ func ConvertCliResponseToOpenAI(_ context.Context, _ string, originalRequestRawJ
 
 	// Process the main content part of the response.
 	partsResult := gjson.GetBytes(rawJSON, "response.candidates.0.content.parts")
+	var audioParts []string
+	audioMimeType := ""
 	hasFunctionCall := false
 	if partsResult.IsArray() {
 		partResults := partsResult.Array()
@@ -174,13 +178,19 @@ func ConvertCliResponseToOpenAI(_ context.Context, _ string, originalRequestRawJ
 				template, _ = sjson.SetRaw(template, "choices.0.delta.tool_calls.-1", functionCallTemplate)
 			} else if inlineDataResult.Exists() {
 				data := inlineDataResult.Get("data").String()
-				if data == "" {
+				// Draft images produced while thinking are not part of the answer.
+				if data == "" || partResult.Get("thought").Bool() {
 					continue
 				}
 				mimeType := inlineDataResult.Get("mimeType").String()
 				if mimeType == "" {
 					mimeType = inlineDataResult.Get("mime_type").String()
 				}
+				if misc.IsAudioMimeType(mimeType) {
+					audioParts = append(audioParts, data)
+					audioMimeType = mimeType
+					continue
+				}
 				if mimeType == "" {
 					mimeType = "image/png"
 				}
@@ -189,7 +199,8 @@ func ConvertCliResponseToOpenAI(_ context.Context, _ string, originalRequestRawJ
 				if !imagesResult.Exists() || !imagesResult.IsArray() {
 					template, _ = sjson.SetRaw(template, "choices.0.delta.images", `[]`)
 				}
-				imageIndex := len(gjson.Get(template, "choices.0.delta.images").Array())
+				imageIndex := (*param).(*convertCliResponseToOpenAIChatParams).ImageIndex
+				(*param).(*convertCliResponseToOpenAIChatParams).ImageIndex++
 				imagePayload := `{"type":"image_url","image_url":{"url":""}}`
 				imagePayload, _ = sjson.Set(imagePayload, "index", imageIndex)
 				imagePayload, _ = sjson.Set(imagePayload, "image_url.url", imageURL)
@@ -199,6 +210,12 @@ func ConvertCliResponseToOpenAI(_ context.Context, _ string, originalRequestRawJ
 		}
 	}
 
+	if len(audioParts) > 0 {
+		template, _ = sjson.Set(template, "choices.0.delta.role", "assistant")
+		template, _ = sjson.Set(template, "choices.0.delta.audio.id", "audio_"+gjson.Get(template, "id").String())
+		template, _ = sjson.Set(template, "choices.0.delta.audio.data", misc.OpenAIAudioData(audioParts, audioMimeType, ""))
+	}
+
 	if hasFunctionCall {
 		template, _ = sjson.Set(template, "choices.0.finish_reason", "tool_calls")
 		template, _ = sjson.Set(template, "choices.0.native_finish_reason", "tool_calls")
@@ -9,7 +9,9 @@ import (
 	"bytes"
 	"strings"
 
+	claudecommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/claude/common"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/structured"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -82,6 +84,11 @@ func ConvertClaudeRequestToGemini(modelName string, inputRawJSON []byte, _ bool)
 						part, _ = sjson.Set(part, "text", contentResult.Get("text").String())
 						contentJSON, _ = sjson.SetRaw(contentJSON, "parts.-1", part)
 
+					case "document":
+						if part, ok := convertClaudeDocumentToGeminiPart(contentResult); ok {
+							contentJSON, _ = sjson.SetRaw(contentJSON, "parts.-1", part)
+						}
+
 					case "tool_use":
 						functionName := contentResult.Get("name").String()
 						functionArgs := contentResult.Get("input").String()
@@ -179,7 +186,49 @@ func ConvertClaudeRequestToGemini(modelName string, inputRawJSON []byte, _ bool)
 	}
 
 	result := []byte(out)
+	// output_format -> generationConfig.responseMimeType / responseJsonSchema
+	if format, ok := structured.FromClaude(rawJSON); ok {
+		result = structured.ApplyGemini(result, "generationConfig", format)
+	}
 	result = common.AttachDefaultSafetySettings(result, "safetySettings")
 
 	return result
 }
+
+// convertClaudeDocumentToGeminiPart maps a Claude document block to a Gemini part. Base64
+// sources become inlineData, URL sources fileData, and text or custom-content sources a text part.
+func convertClaudeDocumentToGeminiPart(document gjson.Result) (string, bool) {
+	source := document.Get("source")
+	mediaType := source.Get("media_type").String()
+	if mediaType == "" {
+		mediaType = "application/pdf"
+	}
+	switch source.Get("type").String() {
+	case "base64":
+		data := source.Get("data").String()
+		if data == "" {
+			return "", false
+		}
+		part := `{"inlineData":{"mime_type":"","data":""}}`
+		part, _ = sjson.Set(part, "inlineData.mime_type", mediaType)
+		part, _ = sjson.Set(part, "inlineData.data", data)
+		return part, true
+	case "url":
+		url := source.Get("url").String()
+		if url == "" {
+			return "", false
+		}
+		part := `{"fileData":{"mimeType":"","fileUri":""}}`
+		part, _ = sjson.Set(part, "fileData.mimeType", mediaType)
+		part, _ = sjson.Set(part, "fileData.fileUri", url)
+		return part, true
+	case "text", "content":
+		text := claudecommon.DocumentText(source)
+		if strings.TrimSpace(text) == "" {
+			return "", false
+		}
+		part, _ := sjson.Set(`{"text":""}`, "text", text)
+		return part, true
+	}
+	return "", false
+}
@@ -0,0 +1,42 @@
+package claude
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertClaudeRequestToGemini_DocumentBlocks(t *testing.T) {
+	input := []byte(`{
+		"model": "claude-sonnet-4",
+		"messages": [{
+			"role": "user",
+			"content": [
+				{"type": "document", "source": {"type": "base64", "media_type": "application/pdf", "data": "JVBERi0x"}},
+				{"type": "document", "source": {"type": "url", "url": "https://example.com/a.pdf"}},
+				{"type": "document", "source": {"type": "content", "content": [{"type": "text", "text": "first"}, {"type": "text", "text": "second"}]}},
+				{"type": "text", "text": "Summarize"}
+			]
+		}]
+	}`)
+
+	parts := gjson.GetBytes(ConvertClaudeRequestToGemini("gemini-2.5-pro", input, false), "contents.0.parts")
+	if n := len(parts.Array()); n != 4 {
+		t.Fatalf("expected 4 parts, got %d: %s", n, parts.Raw)
+	}
+	if got := parts.Get("0.inlineData.mime_type").String(); got != "application/pdf" {
+		t.Fatalf("inlineData.mime_type = %q", got)
+	}
+	if got := parts.Get("0.inlineData.data").String(); got != "JVBERi0x" {
+		t.Fatalf("inlineData.data = %q", got)
+	}
+	if got := parts.Get("1.fileData.fileUri").String(); got != "https://example.com/a.pdf" {
+		t.Fatalf("fileData.fileUri = %q", got)
+	}
+	if got := parts.Get("1.fileData.mimeType").String(); got != "application/pdf" {
+		t.Fatalf("fileData.mimeType = %q", got)
+	}
+	if got := parts.Get("2.text").String(); got != "first\nsecond" {
+		t.Fatalf("text = %q", got)
+	}
+}
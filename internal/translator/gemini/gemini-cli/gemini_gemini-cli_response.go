@@ -28,12 +28,13 @@ var dataTag = []byte("data:")
 // Returns:
 //   - []string: A slice of strings, each containing a Gemini CLI-compatible JSON response.
 func ConvertGeminiResponseToGeminiCLI(_ context.Context, _ string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, _ *any) []string {
-	if !bytes.HasPrefix(rawJSON, dataTag) {
-		return []string{}
+	// Vertex hands over whole SSE lines while the Gemini executor strips the data prefix.
+	if bytes.HasPrefix(rawJSON, dataTag) {
+		rawJSON = rawJSON[len(dataTag):]
 	}
-	rawJSON = bytes.TrimSpace(rawJSON[5:])
+	rawJSON = bytes.TrimSpace(rawJSON)
 
-	if bytes.Equal(rawJSON, []byte("[DONE]")) {
+	if len(rawJSON) == 0 || bytes.Equal(rawJSON, []byte("[DONE]")) {
 		return []string{}
 	}
 	json := `{"response": {}}`
@@ -0,0 +1,44 @@
+package geminiCLI
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+// Vertex hands over whole SSE lines while the Gemini executor strips the data: prefix. The
+// translator used to accept only prefixed chunks, so every chunk from the Gemini executor was
+// dropped. It now wraps both forms.
+func TestConvertGeminiResponseToGeminiCLI_AcceptsPrefixedAndBareChunks(t *testing.T) {
+	tests := []struct {
+		name  string
+		chunk string
+	}{
+		{name: "sse line", chunk: `data: {"candidates":[{"content":{"parts":[{"text":"hi"}]}}]}`},
+		// Previously dropped.
+		{name: "bare json", chunk: `{"candidates":[{"content":{"parts":[{"text":"hi"}]}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := ConvertGeminiResponseToGeminiCLI(context.Background(), "gemini-2.5-pro", nil, nil, []byte(tt.chunk), nil)
+			if len(out) != 1 {
+				t.Fatalf("chunks = %d, want 1", len(out))
+			}
+			if got := gjson.Get(out[0], "response.candidates.0.content.parts.0.text").String(); got != "hi" {
+				t.Fatalf("wrapped chunk = %s, want the text under response", out[0])
+			}
+		})
+	}
+}
+
+// An empty data line used to come out as the invalid JSON {"response": }; it is now dropped like
+// the [DONE] marker.
+func TestConvertGeminiResponseToGeminiCLI_DropsEmptyAndDoneChunks(t *testing.T) {
+	for _, chunk := range []string{"", "data: ", "data: [DONE]", "[DONE]"} {
+		if out := ConvertGeminiResponseToGeminiCLI(context.Background(), "gemini-2.5-pro", nil, nil, []byte(chunk), nil); len(out) != 0 {
+			t.Fatalf("chunk %q produced %v, want nothing", chunk, out)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package gemini
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+)
+
+// ValidateGeminiRequest checks the structure of a Gemini generateContent or countTokens request:
+// contents and their parts, the system instruction and function declarations. Missing or unknown
+// roles are accepted because ConvertGeminiRequestToGemini assigns them.
+func ValidateGeminiRequest(rawJSON []byte) error {
+	if !gjson.ValidBytes(rawJSON) {
+		return errors.New("request body must be valid JSON")
+	}
+	root := gjson.ParseBytes(rawJSON)
+	if !root.IsObject() {
+		return errors.New("request body must be a JSON object")
+	}
+
+	if contents := root.Get("contents"); contents.Exists() {
+		if !contents.IsArray() {
+			return errors.New("contents: expected a list of Content")
+		}
+		for i, content := range contents.Array() {
+			if err := validateGeminiContent(fmt.Sprintf("contents[%d]", i), content); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, key := range []string{"systemInstruction", "system_instruction"} {
+		if instruction := root.Get(key); instruction.Exists() {
+			if err := validateGeminiContent(key, instruction); err != nil {
+				return err
+			}
+		}
+	}
+
+	if tools := root.Get("tools"); tools.Exists() {
+		if !tools.IsArray() {
+			return errors.New("tools: expected a list of Tool")
+		}
+		for i, tool := range tools.Array() {
+			if !tool.IsObject() {
+				return fmt.Errorf("tools[%d]: expected a Tool object", i)
+			}
+			for _, key := range []string{"functionDeclarations", "function_declarations"} {
+				declarations := tool.Get(key)
+				if !declarations.Exists() {
+					continue
+				}
+				if !declarations.IsArray() {
+					return fmt.Errorf("tools[%d].%s: expected a list of FunctionDeclaration", i, key)
+				}
+				for j, declaration := range declarations.Array() {
+					if declaration.Get("name").String() == "" {
+						return fmt.Errorf("tools[%d].%s[%d].name: field required", i, key, j)
+					}
+				}
+			}
+		}
+	}
+
+	if config := root.Get("generationConfig"); config.Exists() && !config.IsObject() {
+		return errors.New("generationConfig: expected a GenerationConfig object")
+	}
+	return nil
+}
+
+func validateGeminiContent(path string, content gjson.Result) error {
+	if !content.IsObject() {
+		return fmt.Errorf("%s: expected a Content object", path)
+	}
+	if role := content.Get("role"); role.Exists() && role.Type != gjson.String {
+		return fmt.Errorf("%s.role: expected a string", path)
+	}
+	parts := content.Get("parts")
+	if !parts.IsArray() {
+		return fmt.Errorf("%s.parts: field required and must be a list of Part", path)
+	}
+	for i, part := range parts.Array() {
+		if !part.IsObject() {
+			return fmt.Errorf("%s.parts[%d]: expected a Part object", path, i)
+		}
+		if call := part.Get("functionCall"); call.Exists() && call.Get("name").String() == "" {
+			return fmt.Errorf("%s.parts[%d].functionCall.name: field required", path, i)
+		}
+		if response := part.Get("functionResponse"); response.Exists() && response.Get("name").String() == "" {
+			return fmt.Errorf("%s.parts[%d].functionResponse.name: field required", path, i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package gemini
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateGeminiRequest(t *testing.T) {
+	valid := `{
+		"systemInstruction": {"parts": [{"text": "be brief"}]},
+		"contents": [
+			{"parts": [{"text": "hi"}]},
+			{"role": "assistant", "parts": [{"functionCall": {"name": "lookup", "args": {}}}]}
+		],
+		"tools": [{"functionDeclarations": [{"name": "lookup"}]}],
+		"generationConfig": {"temperature": 0.2}
+	}`
+	if err := ValidateGeminiRequest([]byte(valid)); err != nil {
+		t.Fatalf("ValidateGeminiRequest(valid) = %v", err)
+	}
+
+	cases := map[string]struct {
+		body string
+		want string
+	}{
+		"invalid json":       {`{"contents":`, "valid JSON"},
+		"contents object":    {`{"contents":{"parts":[]}}`, "contents:"},
+		"missing parts":      {`{"contents":[{"role":"user"}]}`, "contents[0].parts"},
+		"non-object part":    {`{"contents":[{"parts":["hi"]}]}`, "contents[0].parts[0]"},
+		"function call name": {`{"contents":[{"parts":[{"functionCall":{"args":{}}}]}]}`, "functionCall.name"},
+		"declaration name":   {`{"tools":[{"function_declarations":[{"description":"x"}]}]}`, "function_declarations[0].name"},
+		"system instruction": {`{"system_instruction":"be brief"}`, "system_instruction"},
+		"generation config":  {`{"generationConfig":[]}`, "generationConfig"},
+	}
+	for name, tc := range cases {
+		err := ValidateGeminiRequest([]byte(tc.body))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: ValidateGeminiRequest() = %v, want error mentioning %q", name, err, tc.want)
+		}
+	}
+}
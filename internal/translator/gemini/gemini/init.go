@@ -7,7 +7,8 @@ import (
 )
 
 // Register a no-op response translator and a request normalizer for Gemini→Gemini.
-// The request converter ensures missing or invalid roles are normalized to valid values.
+// The request converter ensures missing or invalid roles are normalized to valid values,
+// and the validator rejects structurally malformed requests before they are dispatched.
 func init() {
 	translator.Register(
 		Gemini,
@@ -19,4 +20,5 @@ func init() {
 			TokenCount: GeminiTokenCount,
 		},
 	)
+	translator.RegisterValidator(Gemini, ValidateGeminiRequest)
 }
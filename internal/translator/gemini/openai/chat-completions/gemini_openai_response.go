@@ -13,6 +13,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -23,6 +24,8 @@ type convertGeminiResponseToOpenAIChatParams struct {
 	UnixTimestamp int64
 	// FunctionIndex tracks tool call indices per candidate index to support multiple candidates.
 	FunctionIndex map[int]int
+	// ImageIndex numbers generated images per candidate across chunks.
+	ImageIndex map[int]int
 }
 
 // functionCallIDCounter provides a process-wide unique counter for function call identifiers.
@@ -48,6 +51,7 @@ func ConvertGeminiResponseToOpenAI(_ context.Context, _ string, originalRequestR
 		*param = &convertGeminiResponseToOpenAIChatParams{
 			UnixTimestamp: 0,
 			FunctionIndex: make(map[int]int),
+			ImageIndex:    make(map[int]int),
 		}
 	}
 
@@ -56,6 +60,9 @@ func ConvertGeminiResponseToOpenAI(_ context.Context, _ string, originalRequestR
 	if p.FunctionIndex == nil {
 		p.FunctionIndex = make(map[int]int)
 	}
+	if p.ImageIndex == nil {
+		p.ImageIndex = make(map[int]int)
+	}
 
 	if bytes.HasPrefix(rawJSON, []byte("data:")) {
 		rawJSON = bytes.TrimSpace(rawJSON[5:])
@@ -142,6 +149,8 @@ func ConvertGeminiResponseToOpenAI(_ context.Context, _ string, originalRequestR
 
 			partsResult := candidate.Get("content.parts")
 			hasFunctionCall := false
+			var audioParts []string
+			audioMimeType := ""
 
 			if partsResult.IsArray() {
 				partResults := partsResult.Array()
@@ -202,13 +211,19 @@ func ConvertGeminiResponseToOpenAI(_ context.Context, _ string, originalRequestR
 						template, _ = sjson.SetRaw(template, "choices.0.delta.tool_calls.-1", functionCallTemplate)
 					} else if inlineDataResult.Exists() {
 						data := inlineDataResult.Get("data").String()
-						if data == "" {
+						// Draft images produced while thinking are not part of the answer.
+						if data == "" || partResult.Get("thought").Bool() {
 							continue
 						}
 						mimeType := inlineDataResult.Get("mimeType").String()
 						if mimeType == "" {
 							mimeType = inlineDataResult.Get("mime_type").String()
 						}
+						if misc.IsAudioMimeType(mimeType) {
+							audioParts = append(audioParts, data)
+							audioMimeType = mimeType
+							continue
+						}
 						if mimeType == "" {
 							mimeType = "image/png"
 						}
@@ -217,7 +232,8 @@ func ConvertGeminiResponseToOpenAI(_ context.Context, _ string, originalRequestR
 						if !imagesResult.Exists() || !imagesResult.IsArray() {
 							template, _ = sjson.SetRaw(template, "choices.0.delta.images", `[]`)
 						}
-						imageIndex := len(gjson.Get(template, "choices.0.delta.images").Array())
+						imageIndex := p.ImageIndex[candidateIndex]
+						p.ImageIndex[candidateIndex]++
 						imagePayload := `{"type":"image_url","image_url":{"url":""}}`
 						imagePayload, _ = sjson.Set(imagePayload, "index", imageIndex)
 						imagePayload, _ = sjson.Set(imagePayload, "image_url.url", imageURL)
@@ -227,6 +243,13 @@ func ConvertGeminiResponseToOpenAI(_ context.Context, _ string, originalRequestR
 				}
 			}
 
+			// Speech output streams as raw PCM deltas, the way OpenAI streams pcm16 audio.
+			if len(audioParts) > 0 {
+				template, _ = sjson.Set(template, "choices.0.delta.role", "assistant")
+				template, _ = sjson.Set(template, "choices.0.delta.audio.id", "audio_"+gjson.Get(template, "id").String())
+				template, _ = sjson.Set(template, "choices.0.delta.audio.data", misc.OpenAIAudioData(audioParts, audioMimeType, ""))
+			}
+
 			if hasFunctionCall {
 				template, _ = sjson.Set(template, "choices.0.finish_reason", "tool_calls")
 				template, _ = sjson.Set(template, "choices.0.native_finish_reason", "tool_calls")
@@ -329,6 +352,8 @@ func ConvertGeminiResponseToOpenAINonStream(_ context.Context, _ string, origina
 
 			partsResult := candidate.Get("content.parts")
 			hasFunctionCall := false
+			var audioParts []string
+			audioMimeType := ""
 			if partsResult.IsArray() {
 				partsResults := partsResult.Array()
 				for i := 0; i < len(partsResults); i++ {
@@ -368,11 +393,16 @@ func ConvertGeminiResponseToOpenAINonStream(_ context.Context, _ string, origina
 						choiceTemplate, _ = sjson.SetRaw(choiceTemplate, "message.tool_calls.-1", functionCallItemTemplate)
 					} else if inlineDataResult.Exists() {
 						data := inlineDataResult.Get("data").String()
-						if data != "" {
+						if data != "" && !partResult.Get("thought").Bool() {
 							mimeType := inlineDataResult.Get("mimeType").String()
 							if mimeType == "" {
 								mimeType = inlineDataResult.Get("mime_type").String()
 							}
+							if misc.IsAudioMimeType(mimeType) {
+								audioParts = append(audioParts, data)
+								audioMimeType = mimeType
+								continue
+							}
 							if mimeType == "" {
 								mimeType = "image/png"
 							}
@@ -392,6 +422,16 @@ func ConvertGeminiResponseToOpenAINonStream(_ context.Context, _ string, origina
 				}
 			}
 
+			if len(audioParts) > 0 {
+				audioFormat := gjson.GetBytes(originalRequestRawJSON, "audio.format").String()
+				audioTemplate := `{"id":"","data":"","expires_at":0,"transcript":""}`
+				audioTemplate, _ = sjson.Set(audioTemplate, "id", "audio_"+gjson.Get(template, "id").String())
+				audioTemplate, _ = sjson.Set(audioTemplate, "data", misc.OpenAIAudioData(audioParts, audioMimeType, audioFormat))
+				audioTemplate, _ = sjson.Set(audioTemplate, "transcript", gjson.Get(choiceTemplate, "message.content").String())
+				choiceTemplate, _ = sjson.Set(choiceTemplate, "message.role", "assistant")
+				choiceTemplate, _ = sjson.SetRaw(choiceTemplate, "message.audio", audioTemplate)
+			}
+
 			if hasFunctionCall {
 				choiceTemplate, _ = sjson.Set(choiceTemplate, "finish_reason", "tool_calls")
 				choiceTemplate, _ = sjson.Set(choiceTemplate, "native_finish_reason", "tool_calls")
@@ -0,0 +1,65 @@
+// Package embeddings converts OpenAI embeddings requests into Gemini batchEmbedContents
+// requests and Gemini embedding responses back into the OpenAI list shape.
+package embeddings
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertOpenAIEmbeddingsRequestToGemini builds a batchEmbedContents request with one entry per
+// OpenAI input string. Token-array inputs have no Gemini equivalent and are rejected.
+//
+// Parameters:
+//   - modelName: The Gemini model name used for each embedding request
+//   - inputRawJSON: The raw OpenAI embeddings request
+//
+// Returns:
+//   - []byte: The Gemini batchEmbedContents request
+//   - error: An error when the input is missing or not text
+func ConvertOpenAIEmbeddingsRequestToGemini(modelName string, inputRawJSON []byte) ([]byte, error) {
+	texts, err := openAIEmbeddingInputs(gjson.GetBytes(inputRawJSON, "input"))
+	if err != nil {
+		return nil, err
+	}
+	dimensions := gjson.GetBytes(inputRawJSON, "dimensions")
+
+	out := []byte(`{"requests":[]}`)
+	for _, text := range texts {
+		entry := []byte(`{"model":"","content":{"parts":[{"text":""}]}}`)
+		entry, _ = sjson.SetBytes(entry, "model", "models/"+strings.TrimPrefix(modelName, "models/"))
+		entry, _ = sjson.SetBytes(entry, "content.parts.0.text", text)
+		if dimensions.Exists() && dimensions.Int() > 0 {
+			entry, _ = sjson.SetBytes(entry, "outputDimensionality", dimensions.Int())
+		}
+		out, _ = sjson.SetRawBytes(out, "requests.-1", entry)
+	}
+	return out, nil
+}
+
+func openAIEmbeddingInputs(input gjson.Result) ([]string, error) {
+	switch {
+	case !input.Exists():
+		return nil, fmt.Errorf("input is required")
+	case input.Type == gjson.String:
+		return []string{input.String()}, nil
+	case input.IsArray():
+		items := input.Array()
+		if len(items) == 0 {
+			return nil, fmt.Errorf("input must not be empty")
+		}
+		texts := make([]string, 0, len(items))
+		for _, item := range items {
+			if item.Type != gjson.String {
+				return nil, fmt.Errorf("input must be a string or an array of strings")
+			}
+			texts = append(texts, item.String())
+		}
+		return texts, nil
+	default:
+		return nil, fmt.Errorf("input must be a string or an array of strings")
+	}
+}
@@ -0,0 +1,65 @@
+package embeddings
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertGeminiEmbeddingsResponseToOpenAI converts a batchEmbedContents response into an OpenAI
+// embeddings list. Vectors are base64 encoded when the original request asked for it.
+//
+// Parameters:
+//   - modelName: The model name reported to the client
+//   - originalRequestRawJSON: The raw OpenAI request, consulted for encoding_format
+//   - rawJSON: The Gemini batchEmbedContents response
+//   - promptTokens: The input token count when the upstream reported one
+//
+// Returns:
+//   - []byte: The OpenAI embeddings response
+func ConvertGeminiEmbeddingsResponseToOpenAI(modelName string, originalRequestRawJSON, rawJSON []byte, promptTokens int64) []byte {
+	encodeBase64 := gjson.GetBytes(originalRequestRawJSON, "encoding_format").String() == "base64"
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"object":"list","data":[`)
+	for i, embedding := range gjson.GetBytes(rawJSON, "embeddings").Array() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`{"object":"embedding","index":`)
+		buf.WriteString(strconv.Itoa(i))
+		buf.WriteString(`,"embedding":`)
+		values := embedding.Get("values")
+		switch {
+		case encodeBase64:
+			buf.WriteString(strconv.Quote(encodeEmbeddingBase64(values)))
+		case values.IsArray():
+			buf.WriteString(values.Raw)
+		default:
+			buf.WriteString("[]")
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteString(`]}`)
+
+	out := buf.Bytes()
+	out, _ = sjson.SetBytes(out, "model", modelName)
+	out, _ = sjson.SetBytes(out, "usage.prompt_tokens", promptTokens)
+	out, _ = sjson.SetBytes(out, "usage.total_tokens", promptTokens)
+	return out
+}
+
+// encodeEmbeddingBase64 packs values as little-endian float32, matching OpenAI's base64 format.
+func encodeEmbeddingBase64(values gjson.Result) string {
+	items := values.Array()
+	raw := make([]byte, 4*len(items))
+	for i, item := range items {
+		binary.LittleEndian.PutUint32(raw[4*i:], math.Float32bits(float32(item.Float())))
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
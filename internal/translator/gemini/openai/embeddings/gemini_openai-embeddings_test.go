@@ -0,0 +1,66 @@
+package embeddings
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOpenAIEmbeddingsRequestToGemini(t *testing.T) {
+	out, err := ConvertOpenAIEmbeddingsRequestToGemini("gemini-embedding-001", []byte(`{"input":"hello","dimensions":256}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := gjson.GetBytes(out, "requests.#").Int(); got != 1 {
+		t.Fatalf("requests = %d, want 1", got)
+	}
+	if got := gjson.GetBytes(out, "requests.0.model").String(); got != "models/gemini-embedding-001" {
+		t.Fatalf("model = %q", got)
+	}
+	if got := gjson.GetBytes(out, "requests.0.content.parts.0.text").String(); got != "hello" {
+		t.Fatalf("text = %q", got)
+	}
+	if got := gjson.GetBytes(out, "requests.0.outputDimensionality").Int(); got != 256 {
+		t.Fatalf("outputDimensionality = %d", got)
+	}
+}
+
+func TestConvertOpenAIEmbeddingsRequestToGeminiRejectsTokens(t *testing.T) {
+	for _, body := range []string{`{}`, `{"input":[]}`, `{"input":[1,2,3]}`, `{"input":[[1,2]]}`} {
+		if _, err := ConvertOpenAIEmbeddingsRequestToGemini("m", []byte(body)); err == nil {
+			t.Fatalf("expected error for %s", body)
+		}
+	}
+}
+
+func TestConvertGeminiEmbeddingsResponseToOpenAI(t *testing.T) {
+	raw := []byte(`{"embeddings":[{"values":[0.5,-1]},{"values":[2]}]}`)
+	out := ConvertGeminiEmbeddingsResponseToOpenAI("gemini-embedding-001", []byte(`{}`), raw, 7)
+	if got := gjson.GetBytes(out, "data.#").Int(); got != 2 {
+		t.Fatalf("data = %d, want 2", got)
+	}
+	if got := gjson.GetBytes(out, "data.1.index").Int(); got != 1 {
+		t.Fatalf("index = %d, want 1", got)
+	}
+	if got := gjson.GetBytes(out, "data.0.embedding").Raw; got != "[0.5,-1]" {
+		t.Fatalf("embedding = %s", got)
+	}
+	if got := gjson.GetBytes(out, "usage.prompt_tokens").Int(); got != 7 {
+		t.Fatalf("prompt_tokens = %d, want 7", got)
+	}
+
+	encoded := ConvertGeminiEmbeddingsResponseToOpenAI("m", []byte(`{"encoding_format":"base64"}`), raw, 0)
+	decoded, err := base64.StdEncoding.DecodeString(gjson.GetBytes(encoded, "data.0.embedding").String())
+	if err != nil {
+		t.Fatalf("decode base64: %v", err)
+	}
+	if len(decoded) != 8 {
+		t.Fatalf("decoded length = %d, want 8", len(decoded))
+	}
+	if got := math.Float32frombits(binary.LittleEndian.Uint32(decoded[4:])); got != -1 {
+		t.Fatalf("second value = %v, want -1", got)
+	}
+}
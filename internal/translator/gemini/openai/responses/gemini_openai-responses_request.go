@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/structured"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -444,6 +445,10 @@ func ConvertOpenAIResponsesRequestToGemini(modelName string, inputRawJSON []byte
 	}
 
 	result := []byte(out)
+	// Map text.format (json_object / json_schema) to the Gemini JSON mime type and response schema.
+	if format, ok := structured.FromResponses(rawJSON); ok {
+		result = structured.ApplyGemini(result, "generationConfig", format)
+	}
 	result = common.AttachDefaultSafetySettings(result, "safetySettings")
 	return result
 }
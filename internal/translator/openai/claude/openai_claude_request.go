@@ -9,6 +9,8 @@ import (
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	claudecommon "github.com/router-for-me/CLIProxyAPI/v6/internal/translator/claude/common"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/structured"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -149,7 +151,7 @@ func ConvertClaudeRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 					case "redacted_thinking":
 						// Explicitly ignore redacted_thinking - never map to reasoning_content (AC2)
 
-					case "text", "image":
+					case "text", "image", "document":
 						if contentItem, ok := convertClaudeContentPart(part); ok {
 							contentItems = append(contentItems, contentItem)
 						}
@@ -312,6 +314,11 @@ func ConvertClaudeRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 		out, _ = sjson.Set(out, "user", user.String())
 	}
 
+	// Handle output_format (structured output)
+	if format, ok := structured.FromClaude(rawJSON); ok {
+		return structured.ApplyOpenAI([]byte(out), format)
+	}
+
 	return []byte(out)
 }
 
@@ -361,11 +368,64 @@ func convertClaudeContentPart(part gjson.Result) (string, bool) {
 
 		return imageContent, true
 
+	case "document":
+		return convertClaudeDocumentPart(part)
+
 	default:
 		return "", false
 	}
 }
 
+// convertClaudeDocumentPart maps a Claude document block to a chat-completions content part.
+// Base64 sources become file parts carrying a data URL. Chat Completions file parts only accept
+// inline data, so URL sources are passed as a text reference, as are text or custom-content
+// sources with their text.
+func convertClaudeDocumentPart(part gjson.Result) (string, bool) {
+	source := part.Get("source")
+	filename := part.Get("title").String()
+	if filename == "" {
+		filename = "document.pdf"
+	}
+	var fileData string
+	switch source.Get("type").String() {
+	case "base64":
+		data := source.Get("data").String()
+		if data == "" {
+			return "", false
+		}
+		mediaType := source.Get("media_type").String()
+		if mediaType == "" {
+			mediaType = "application/pdf"
+		}
+		fileData = "data:" + mediaType + ";base64," + data
+	case "url":
+		url := strings.TrimSpace(source.Get("url").String())
+		if url == "" {
+			return "", false
+		}
+		reference := "Document: " + url
+		if title := strings.TrimSpace(part.Get("title").String()); title != "" {
+			reference = "Document " + title + ": " + url
+		}
+		textContent, _ := sjson.Set(`{"type":"text","text":""}`, "text", reference)
+		return textContent, true
+	case "text", "content":
+		text := claudecommon.DocumentText(source)
+		if strings.TrimSpace(text) == "" {
+			return "", false
+		}
+		textContent, _ := sjson.Set(`{"type":"text","text":""}`, "text", text)
+		return textContent, true
+	}
+	if fileData == "" {
+		return "", false
+	}
+	fileContent := `{"type":"file","file":{"filename":"","file_data":""}}`
+	fileContent, _ = sjson.Set(fileContent, "file.filename", filename)
+	fileContent, _ = sjson.Set(fileContent, "file.file_data", fileData)
+	return fileContent, true
+}
+
 func convertClaudeToolResultContentToString(content gjson.Result) string {
 	if !content.Exists() {
 		return ""
@@ -588,3 +588,39 @@ func TestConvertClaudeRequestToOpenAI_AssistantThinkingToolUseThinkingSplit(t *t
 		t.Fatalf("Expected reasoning_content %q, got %q", "t1\n\nt2", got)
 	}
 }
+
+func TestConvertClaudeRequestToOpenAI_DocumentBlocks(t *testing.T) {
+	inputJSON := `{
+		"model": "claude-3-opus",
+		"messages": [{
+			"role": "user",
+			"content": [
+				{"type": "document", "title": "report.pdf", "source": {"type": "base64", "media_type": "application/pdf", "data": "JVBERi0x"}},
+				{"type": "document", "source": {"type": "url", "url": "https://example.com/a.pdf"}},
+				{"type": "document", "source": {"type": "text", "media_type": "text/plain", "data": "plain notes"}},
+				{"type": "text", "text": "Summarize"}
+			]
+		}]
+	}`
+
+	result := ConvertClaudeRequestToOpenAI("test-model", []byte(inputJSON), false)
+	content := gjson.GetBytes(result, "messages.0.content")
+	if n := len(content.Array()); n != 4 {
+		t.Fatalf("Expected 4 content parts, got %d: %s", n, content.Raw)
+	}
+	if got := content.Get("0.type").String(); got != "file" {
+		t.Fatalf("Expected file part, got %q", got)
+	}
+	if got := content.Get("0.file.filename").String(); got != "report.pdf" {
+		t.Fatalf("Expected filename report.pdf, got %q", got)
+	}
+	if got := content.Get("0.file.file_data").String(); got != "data:application/pdf;base64,JVBERi0x" {
+		t.Fatalf("Unexpected file_data %q", got)
+	}
+	if got := content.Get("1.type").String(); got != "text" || content.Get("1.text").String() != "Document: https://example.com/a.pdf" {
+		t.Fatalf("Expected text reference for URL document, got %s", content.Get("1").Raw)
+	}
+	if got := content.Get("2.type").String(); got != "text" || content.Get("2.text").String() != "plain notes" {
+		t.Fatalf("Expected text part for text document, got %s", content.Get("2").Raw)
+	}
+}
@@ -0,0 +1,63 @@
+// Package embeddings converts Gemini embedContent and batchEmbedContents requests into OpenAI
+// embeddings requests and OpenAI embedding responses back into the Gemini shapes.
+package embeddings
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ConvertGeminiEmbeddingsRequestToOpenAI builds an OpenAI embeddings request from either a single
+// embedContent request or a batchEmbedContents request. The text parts of each content are joined
+// with newlines; outputDimensionality maps to dimensions.
+//
+// Parameters:
+//   - modelName: The model name to request upstream
+//   - inputRawJSON: The raw Gemini embedding request
+//
+// Returns:
+//   - []byte: The OpenAI embeddings request
+//   - error: An error when the request carries no content
+func ConvertGeminiEmbeddingsRequestToOpenAI(modelName string, inputRawJSON []byte) ([]byte, error) {
+	root := gjson.ParseBytes(inputRawJSON)
+	out := []byte(`{"model":"","encoding_format":"float"}`)
+	out, _ = sjson.SetBytes(out, "model", modelName)
+
+	var dimensions gjson.Result
+	if requests := root.Get("requests"); requests.IsArray() {
+		items := requests.Array()
+		if len(items) == 0 {
+			return nil, fmt.Errorf("requests must not be empty")
+		}
+		texts := make([]string, 0, len(items))
+		for _, item := range items {
+			texts = append(texts, geminiContentText(item.Get("content")))
+		}
+		out, _ = sjson.SetBytes(out, "input", texts)
+		dimensions = items[0].Get("outputDimensionality")
+	} else {
+		content := root.Get("content")
+		if !content.Exists() {
+			return nil, fmt.Errorf("content is required")
+		}
+		out, _ = sjson.SetBytes(out, "input", geminiContentText(content))
+		dimensions = root.Get("outputDimensionality")
+	}
+	if dimensions.Exists() && dimensions.Int() > 0 {
+		out, _ = sjson.SetBytes(out, "dimensions", dimensions.Int())
+	}
+	return out, nil
+}
+
+func geminiContentText(content gjson.Result) string {
+	var parts []string
+	for _, part := range content.Get("parts").Array() {
+		if text := part.Get("text"); text.Exists() {
+			parts = append(parts, text.String())
+		}
+	}
+	return strings.Join(parts, "\n")
+}
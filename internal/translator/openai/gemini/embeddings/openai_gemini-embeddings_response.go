@@ -0,0 +1,50 @@
+package embeddings
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/tidwall/gjson"
+)
+
+// ConvertOpenAIEmbeddingsResponseToGemini converts an OpenAI embeddings list into an embedContent
+// response when single is true, or a batchEmbedContents response otherwise. Entries are ordered
+// by their OpenAI index.
+//
+// Parameters:
+//   - rawJSON: The OpenAI embeddings response, with float vectors
+//   - single: Whether the client called embedContent
+//
+// Returns:
+//   - []byte: The Gemini embedding response
+func ConvertOpenAIEmbeddingsResponseToGemini(rawJSON []byte, single bool) []byte {
+	data := gjson.GetBytes(rawJSON, "data").Array()
+	sort.SliceStable(data, func(i, j int) bool { return data[i].Get("index").Int() < data[j].Get("index").Int() })
+
+	var buf bytes.Buffer
+	if single {
+		buf.WriteString(`{"embedding":{"values":`)
+		if len(data) > 0 && data[0].Get("embedding").IsArray() {
+			buf.WriteString(data[0].Get("embedding").Raw)
+		} else {
+			buf.WriteString("[]")
+		}
+		buf.WriteString(`}}`)
+		return buf.Bytes()
+	}
+	buf.WriteString(`{"embeddings":[`)
+	for i, item := range data {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`{"values":`)
+		if embedding := item.Get("embedding"); embedding.IsArray() {
+			buf.WriteString(embedding.Raw)
+		} else {
+			buf.WriteString("[]")
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteString(`]}`)
+	return buf.Bytes()
+}
@@ -0,0 +1,50 @@
+package embeddings
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertGeminiEmbeddingsRequestToOpenAI(t *testing.T) {
+	single, err := ConvertGeminiEmbeddingsRequestToOpenAI("text-embedding-3-small", []byte(`{"content":{"parts":[{"text":"a"},{"text":"b"}]},"outputDimensionality":64}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := gjson.GetBytes(single, "input").String(); got != "a\nb" {
+		t.Fatalf("input = %q", got)
+	}
+	if got := gjson.GetBytes(single, "dimensions").Int(); got != 64 {
+		t.Fatalf("dimensions = %d", got)
+	}
+
+	batch, err := ConvertGeminiEmbeddingsRequestToOpenAI("m", []byte(`{"requests":[{"content":{"parts":[{"text":"x"}]}},{"content":{"parts":[{"text":"y"}]}}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := gjson.GetBytes(batch, "input.1").String(); got != "y" {
+		t.Fatalf("input[1] = %q", got)
+	}
+	if gjson.GetBytes(batch, "dimensions").Exists() {
+		t.Fatal("unexpected dimensions")
+	}
+
+	if _, err = ConvertGeminiEmbeddingsRequestToOpenAI("m", []byte(`{}`)); err == nil {
+		t.Fatal("expected error for missing content")
+	}
+}
+
+func TestConvertOpenAIEmbeddingsResponseToGemini(t *testing.T) {
+	raw := []byte(`{"data":[{"index":1,"embedding":[2]},{"index":0,"embedding":[1]}]}`)
+	batch := ConvertOpenAIEmbeddingsResponseToGemini(raw, false)
+	if got := gjson.GetBytes(batch, "embeddings.0.values").Raw; got != "[1]" {
+		t.Fatalf("embeddings[0] = %s", got)
+	}
+	if got := gjson.GetBytes(batch, "embeddings.1.values").Raw; got != "[2]" {
+		t.Fatalf("embeddings[1] = %s", got)
+	}
+	single := ConvertOpenAIEmbeddingsResponseToGemini(raw, true)
+	if got := gjson.GetBytes(single, "embedding.values").Raw; got != "[1]" {
+		t.Fatalf("embedding = %s", got)
+	}
+}
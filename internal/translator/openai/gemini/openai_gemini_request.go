@@ -11,7 +11,9 @@ import (
 	"math/big"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/structured"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -81,6 +83,30 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 			out, _ = sjson.Set(out, "n", candidateCount.Int())
 		}
 
+		// Response modalities -> OpenAI modalities; speech output also needs an audio block.
+		if modalities := genConfig.Get("responseModalities"); modalities.IsArray() {
+			var mods []string
+			for _, modality := range modalities.Array() {
+				mods = append(mods, strings.ToLower(modality.String()))
+			}
+			if len(mods) > 0 {
+				out, _ = sjson.Set(out, "modalities", mods)
+			}
+			for _, modality := range mods {
+				if modality != "audio" {
+					continue
+				}
+				voice := genConfig.Get("speechConfig.voiceConfig.prebuiltVoiceConfig.voiceName").String()
+				out, _ = sjson.Set(out, "audio.voice", misc.OpenAIVoice(voice))
+				out, _ = sjson.Set(out, "audio.format", "pcm16")
+			}
+		}
+
+		// Response schema / JSON mime type -> response_format
+		if format, ok := structured.FromGemini(genConfig); ok {
+			out = string(structured.ApplyOpenAI([]byte(out), format))
+		}
+
 		// Map Gemini thinkingConfig to OpenAI reasoning_effort.
 		// Always perform conversion to support allowCompat models that may not be in registry.
 		// Note: Google official Python SDK sends snake_case fields (thinking_level/thinking_budget).
@@ -142,6 +168,11 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 						mimeType = "application/octet-stream"
 					}
 					data := inlineData.Get("data").String()
+					if misc.IsAudioMimeType(mimeType) {
+						msg, _ = sjson.SetRaw(msg, "content.-1", inputAudioPart(mimeType, data))
+						hasContent = true
+						return true
+					}
 					imageURL := fmt.Sprintf("data:%s;base64,%s", mimeType, data)
 
 					contentPart := `{"type":"image_url","image_url":{"url":""}}`
@@ -199,11 +230,15 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 							mimeType = "application/octet-stream"
 						}
 						data := inlineData.Get("data").String()
-						imageURL := fmt.Sprintf("data:%s;base64,%s", mimeType, data)
+						if misc.IsAudioMimeType(mimeType) {
+							contentWrapper, _ = sjson.SetRaw(contentWrapper, "arr.-1", inputAudioPart(mimeType, data))
+						} else {
+							imageURL := fmt.Sprintf("data:%s;base64,%s", mimeType, data)
 
-						contentPart := `{"type":"image_url","image_url":{"url":""}}`
-						contentPart, _ = sjson.Set(contentPart, "image_url.url", imageURL)
-						contentWrapper, _ = sjson.SetRaw(contentWrapper, "arr.-1", contentPart)
+							contentPart := `{"type":"image_url","image_url":{"url":""}}`
+							contentPart, _ = sjson.Set(contentPart, "image_url.url", imageURL)
+							contentWrapper, _ = sjson.SetRaw(contentWrapper, "arr.-1", contentPart)
+						}
 						contentPartsCount++
 					}
 
@@ -319,3 +354,11 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 
 	return []byte(out)
 }
+
+// inputAudioPart builds an OpenAI input_audio content part from Gemini inline audio.
+func inputAudioPart(mimeType, data string) string {
+	part := `{"type":"input_audio","input_audio":{"data":"","format":""}}`
+	part, _ = sjson.Set(part, "input_audio.data", data)
+	part, _ = sjson.Set(part, "input_audio.format", misc.AudioFormat(mimeType))
+	return part
+}
@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -146,6 +147,14 @@ func ConvertOpenAIResponseToGemini(_ context.Context, _ string, originalRequestR
 				chunkOutputs = append(chunkOutputs, contentTemplate)
 			}
 
+			// Handle audio delta; OpenAI only streams pcm16 audio.
+			if audio := delta.Get("audio.data"); audio.Exists() && audio.String() != "" {
+				audioTemplate := baseTemplate
+				audioTemplate, _ = sjson.Set(audioTemplate, "candidates.0.content.parts.0.inlineData.mimeType", misc.PCM16MimeType)
+				audioTemplate, _ = sjson.Set(audioTemplate, "candidates.0.content.parts.0.inlineData.data", audio.String())
+				chunkOutputs = append(chunkOutputs, audioTemplate)
+			}
+
 			if len(chunkOutputs) > 0 {
 				results = append(results, chunkOutputs...)
 				return true
@@ -576,6 +585,17 @@ func ConvertOpenAIResponseToGeminiNonStream(_ context.Context, _ string, origina
 				partIndex++
 			}
 
+			// Handle audio output in the format the request asked for
+			if audio := message.Get("audio.data"); audio.Exists() && audio.String() != "" {
+				audioFormat := gjson.GetBytes(requestRawJSON, "audio.format").String()
+				if audioFormat == "" {
+					audioFormat = "pcm16"
+				}
+				out, _ = sjson.Set(out, fmt.Sprintf("candidates.0.content.parts.%d.inlineData.mimeType", partIndex), misc.AudioMimeType(audioFormat))
+				out, _ = sjson.Set(out, fmt.Sprintf("candidates.0.content.parts.%d.inlineData.data", partIndex), audio.String())
+				partIndex++
+			}
+
 			// Handle tool calls
 			if toolCalls := message.Get("tool_calls"); toolCalls.Exists() && toolCalls.IsArray() {
 				toolCalls.ForEach(func(_, toolCall gjson.Result) bool {
@@ -3,6 +3,7 @@ package responses
 import (
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/structured"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -210,5 +211,10 @@ func ConvertOpenAIResponsesRequestToOpenAIChatCompletions(modelName string, inpu
 		out, _ = sjson.Set(out, "tool_choice", toolChoice.String())
 	}
 
+	// Convert text.format to response_format
+	if format, ok := structured.FromResponses(rawJSON); ok {
+		return structured.ApplyOpenAI([]byte(out), format)
+	}
+
 	return []byte(out)
 }
@@ -45,6 +45,8 @@ type oaiToResponsesState struct {
 	TotalTokens      int64
 	ReasoningTokens  int64
 	UsageSeen        bool
+	// PendingCompleted holds response.completed until the usage-only chunk that follows finish_reason
+	PendingCompleted string
 }
 
 // responseIDCounter provides a process-wide unique counter for synthesized response identifiers.
@@ -82,6 +84,10 @@ func ConvertOpenAIChatCompletionsResponseToOpenAIResponses(ctx context.Context,
 		return []string{}
 	}
 	if bytes.Equal(rawJSON, []byte("[DONE]")) {
+		if st.PendingCompleted != "" {
+			st.Seq++
+			return []string{completeResponse(st, st.Seq)}
+		}
 		return []string{}
 	}
 
@@ -126,6 +132,11 @@ func ConvertOpenAIChatCompletionsResponseToOpenAIResponses(ctx context.Context,
 	nextSeq := func() int { st.Seq++; return st.Seq }
 	var out []string
 
+	// Streams that request usage send it in a chunk after finish_reason; complete once it arrives.
+	if st.PendingCompleted != "" {
+		return []string{completeResponse(st, nextSeq())}
+	}
+
 	if !st.Started {
 		st.ResponseID = root.Get("id").String()
 		st.Created = root.Get("created").Int()
@@ -446,7 +457,6 @@ func ConvertOpenAIChatCompletionsResponseToOpenAIResponses(ctx context.Context,
 					}
 				}
 				completed := `{"type":"response.completed","sequence_number":0,"response":{"id":"","object":"response","created_at":0,"status":"completed","background":false,"error":null}}`
-				completed, _ = sjson.Set(completed, "sequence_number", nextSeq())
 				completed, _ = sjson.Set(completed, "response.id", st.ResponseID)
 				completed, _ = sjson.Set(completed, "response.created_at", st.Created)
 				// Inject original request fields into response as per docs/response.completed.json
@@ -578,20 +588,10 @@ func ConvertOpenAIChatCompletionsResponseToOpenAIResponses(ctx context.Context,
 				if gjson.Get(outputsWrapper, "arr.#").Int() > 0 {
 					completed, _ = sjson.SetRaw(completed, "response.output", gjson.Get(outputsWrapper, "arr").Raw)
 				}
+				st.PendingCompleted = completed
 				if st.UsageSeen {
-					completed, _ = sjson.Set(completed, "response.usage.input_tokens", st.PromptTokens)
-					completed, _ = sjson.Set(completed, "response.usage.input_tokens_details.cached_tokens", st.CachedTokens)
-					completed, _ = sjson.Set(completed, "response.usage.output_tokens", st.CompletionTokens)
-					if st.ReasoningTokens > 0 {
-						completed, _ = sjson.Set(completed, "response.usage.output_tokens_details.reasoning_tokens", st.ReasoningTokens)
-					}
-					total := st.TotalTokens
-					if total == 0 {
-						total = st.PromptTokens + st.CompletionTokens
-					}
-					completed, _ = sjson.Set(completed, "response.usage.total_tokens", total)
+					out = append(out, completeResponse(st, nextSeq()))
 				}
-				out = append(out, emitRespEvent("response.completed", completed))
 			}
 
 			return true
@@ -601,6 +601,28 @@ func ConvertOpenAIChatCompletionsResponseToOpenAIResponses(ctx context.Context,
 	return out
 }
 
+// completeResponse emits the pending response.completed event with its sequence number and the
+// aggregated usage, and clears it from the state.
+func completeResponse(st *oaiToResponsesState, seq int) string {
+	completed := st.PendingCompleted
+	st.PendingCompleted = ""
+	completed, _ = sjson.Set(completed, "sequence_number", seq)
+	if st.UsageSeen {
+		completed, _ = sjson.Set(completed, "response.usage.input_tokens", st.PromptTokens)
+		completed, _ = sjson.Set(completed, "response.usage.input_tokens_details.cached_tokens", st.CachedTokens)
+		completed, _ = sjson.Set(completed, "response.usage.output_tokens", st.CompletionTokens)
+		if st.ReasoningTokens > 0 {
+			completed, _ = sjson.Set(completed, "response.usage.output_tokens_details.reasoning_tokens", st.ReasoningTokens)
+		}
+		total := st.TotalTokens
+		if total == 0 {
+			total = st.PromptTokens + st.CompletionTokens
+		}
+		completed, _ = sjson.Set(completed, "response.usage.total_tokens", total)
+	}
+	return emitRespEvent("response.completed", completed)
+}
+
 // ConvertOpenAIChatCompletionsResponseToOpenAIResponsesNonStream builds a single Responses JSON
 // from a non-streaming OpenAI Chat Completions response.
 func ConvertOpenAIChatCompletionsResponseToOpenAIResponsesNonStream(_ context.Context, _ string, originalRequestRawJSON, requestRawJSON, rawJSON []byte, _ *any) string {
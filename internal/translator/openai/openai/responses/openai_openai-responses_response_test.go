@@ -0,0 +1,65 @@
+package responses
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOpenAIChatCompletionsResponseToOpenAIResponses_WaitsForTrailingUsage(t *testing.T) {
+	chunks := []string{
+		`data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1700000000,"model":"gpt-4o","choices":[{"index":0,"delta":{"role":"assistant","content":"hi"}}]}`,
+		`data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1700000000,"model":"gpt-4o","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1700000000,"model":"gpt-4o","choices":[],"usage":{"prompt_tokens":11,"completion_tokens":7,"total_tokens":18}}`,
+		`data: [DONE]`,
+	}
+
+	var param any
+	var completed []string
+	for i, chunk := range chunks {
+		for _, event := range ConvertOpenAIChatCompletionsResponseToOpenAIResponses(context.Background(), "gpt-4o", nil, nil, []byte(chunk), &param) {
+			if strings.HasPrefix(event, "event: response.completed\n") {
+				if i < 2 {
+					t.Fatalf("response.completed emitted at chunk %d, before the usage chunk", i)
+				}
+				completed = append(completed, strings.TrimPrefix(event, "event: response.completed\ndata: "))
+			}
+		}
+	}
+
+	if len(completed) != 1 {
+		t.Fatalf("response.completed events = %d, want 1", len(completed))
+	}
+	if got := gjson.Get(completed[0], "response.usage.input_tokens").Int(); got != 11 {
+		t.Fatalf("input_tokens = %d, want 11", got)
+	}
+	if got := gjson.Get(completed[0], "response.usage.output_tokens").Int(); got != 7 {
+		t.Fatalf("output_tokens = %d, want 7", got)
+	}
+	if got := gjson.Get(completed[0], "response.usage.total_tokens").Int(); got != 18 {
+		t.Fatalf("total_tokens = %d, want 18", got)
+	}
+}
+
+func TestConvertOpenAIChatCompletionsResponseToOpenAIResponses_CompletesOnDoneWithoutUsage(t *testing.T) {
+	chunks := []string{
+		`data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1700000000,"model":"gpt-4o","choices":[{"index":0,"delta":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`,
+		`data: [DONE]`,
+	}
+
+	var param any
+	var events []string
+	for _, chunk := range chunks {
+		events = append(events, ConvertOpenAIChatCompletionsResponseToOpenAIResponses(context.Background(), "gpt-4o", nil, nil, []byte(chunk), &param)...)
+	}
+
+	last := events[len(events)-1]
+	if !strings.HasPrefix(last, "event: response.completed\n") {
+		t.Fatalf("last event = %q, want response.completed", last)
+	}
+	if gjson.Get(strings.TrimPrefix(last, "event: response.completed\ndata: "), "response.usage").Exists() {
+		t.Fatalf("response.completed carries usage although none was streamed: %s", last)
+	}
+}
@@ -0,0 +1,124 @@
+package structured
+
+import (
+	"bytes"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ClaudeToolName is the tool Claude is forced to call when structured output is emulated. The name
+// is reserved by the proxy so a client tool forced through tool_choice is never taken for the
+// emulation.
+const ClaudeToolName = "__cliproxy_structured_output"
+
+const claudeToolDescription = "Respond to the user by calling this tool exactly once. Its input is the complete final answer."
+
+// ApplyClaude emulates structured output on a Claude Messages request: the schema becomes the
+// input schema of a single extra tool and tool_choice forces Claude to call it. A forced call is
+// the only tool call of the turn, so the emulation cannot be combined with a tool the client
+// forces itself ("any" or a named tool); such a request is returned unchanged so the client gets
+// the tool call it asked for, and a warning is logged. An "auto" or "none" tool_choice is
+// replaced. Plain JSON mode uses an open object schema because Claude tool inputs are always
+// objects.
+func ApplyClaude(out []byte, f Format) []byte {
+	if choice := gjson.GetBytes(out, "tool_choice.type").String(); choice == "any" || choice == "tool" {
+		log.Warnf("structured output: not emulated for Claude because the request forces a tool call (tool_choice %s)", gjson.GetBytes(out, "tool_choice").Raw)
+		return out
+	}
+	schema := f.Schema
+	if schema == "" {
+		schema = `{"type":"object"}`
+	}
+	description := claudeToolDescription
+	if f.Description != "" {
+		description += " " + f.Description
+	}
+	tool := `{"name":"","description":"","input_schema":{}}`
+	tool, _ = sjson.Set(tool, "name", ClaudeToolName)
+	tool, _ = sjson.Set(tool, "description", description)
+	tool, _ = sjson.SetRaw(tool, "input_schema", schema)
+	if !gjson.GetBytes(out, "tools").IsArray() {
+		out, _ = sjson.SetRawBytes(out, "tools", []byte(`[]`))
+	}
+	out, _ = sjson.SetRawBytes(out, "tools.-1", []byte(tool))
+	out, _ = sjson.SetRawBytes(out, "tool_choice", []byte(`{"type":"tool","name":"`+ClaudeToolName+`"}`))
+	return out
+}
+
+// ClaudeEmulated reports whether a translated Claude request carries the structured-output
+// emulation added by ApplyClaude.
+func ClaudeEmulated(requestRawJSON []byte) bool {
+	choice := gjson.GetBytes(requestRawJSON, "tool_choice")
+	return choice.Get("type").String() == "tool" && choice.Get("name").String() == ClaudeToolName
+}
+
+// UnwrapClaudeResponse rewrites a Claude response so the emulated tool call reads as the message
+// text: the tool_use block becomes a text block holding its JSON input and the tool_use stop
+// reason becomes end_turn. payload may be a message body, a single stream event with or without
+// its "data:" prefix, or a buffered SSE body. It is returned unchanged unless requestRawJSON
+// carries the emulation, so response translators can call it unconditionally.
+//
+// Stream events are rewritten without tracking state: with the tool forced, every input_json_delta
+// of the turn belongs to the emulated call.
+func UnwrapClaudeResponse(requestRawJSON, payload []byte) []byte {
+	if !ClaudeEmulated(requestRawJSON) {
+		return payload
+	}
+	lines := bytes.Split(payload, []byte("\n"))
+	for i, line := range lines {
+		prefix, data := []byte(nil), line
+		if rest, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			prefix, data = []byte("data: "), bytes.TrimSpace(rest)
+		}
+		if len(data) == 0 || data[0] != '{' {
+			continue
+		}
+		if rewritten, changed := unwrapClaudeEvent(data); changed {
+			lines[i] = append(prefix, rewritten...)
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
+func unwrapClaudeEvent(event []byte) ([]byte, bool) {
+	root := gjson.ParseBytes(event)
+	changed := false
+	switch root.Get("type").String() {
+	case "message":
+		root.Get("content").ForEach(func(index, block gjson.Result) bool {
+			if block.Get("type").String() == "tool_use" && block.Get("name").String() == ClaudeToolName {
+				text := `{"type":"text","text":""}`
+				text, _ = sjson.Set(text, "text", block.Get("input").Raw)
+				event, _ = sjson.SetRawBytes(event, "content."+index.String(), []byte(text))
+				changed = true
+			}
+			return true
+		})
+		if root.Get("stop_reason").String() == "tool_use" {
+			event, _ = sjson.SetBytes(event, "stop_reason", "end_turn")
+			changed = true
+		}
+	case "content_block_start":
+		block := root.Get("content_block")
+		if block.Get("type").String() == "tool_use" && block.Get("name").String() == ClaudeToolName {
+			event, _ = sjson.SetRawBytes(event, "content_block", []byte(`{"type":"text","text":""}`))
+			changed = true
+		}
+	case "content_block_delta":
+		delta := root.Get("delta")
+		if delta.Get("type").String() == "input_json_delta" {
+			text := `{"type":"text_delta","text":""}`
+			text, _ = sjson.Set(text, "text", delta.Get("partial_json").String())
+			event, _ = sjson.SetRawBytes(event, "delta", []byte(text))
+			changed = true
+		}
+	case "message_delta":
+		if root.Get("delta.stop_reason").String() == "tool_use" {
+			event, _ = sjson.SetBytes(event, "delta.stop_reason", "end_turn")
+			changed = true
+		}
+	}
+	return event, changed
+}
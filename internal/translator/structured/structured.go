@@ -0,0 +1,178 @@
+// Package structured maps structured-output (JSON mode and JSON schema) requests between the
+// schemas the proxy speaks. Request translators read the client's setting with one of the From
+// helpers and write the upstream's native mechanism with the matching Apply helper. Claude has no
+// response-format parameter, so ApplyClaude emulates it with a forced tool call that
+// UnwrapClaudeResponse turns back into message text.
+package structured
+
+import (
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Format is a structured-output request in schema-neutral form.
+type Format struct {
+	// Name and Description label the schema; upstreams that require a name get a default.
+	Name        string
+	Description string
+	// Schema is the raw JSON Schema the response must follow. It is empty for plain JSON mode.
+	Schema string
+	// Strict asks for exact schema adherence where the upstream supports it.
+	Strict bool
+}
+
+const defaultName = "response"
+
+// FromOpenAI reads an OpenAI Chat Completions response_format. It reports false for plain text
+// or when no format is requested.
+func FromOpenAI(rawJSON []byte) (Format, bool) {
+	responseFormat := gjson.GetBytes(rawJSON, "response_format")
+	switch responseFormat.Get("type").String() {
+	case "json_object":
+		return Format{}, true
+	case "json_schema":
+		schema := responseFormat.Get("json_schema")
+		return Format{
+			Name:        schema.Get("name").String(),
+			Description: schema.Get("description").String(),
+			Schema:      objectRaw(schema.Get("schema")),
+			Strict:      schema.Get("strict").Bool(),
+		}, true
+	}
+	return Format{}, false
+}
+
+// FromResponses reads an OpenAI Responses text.format setting.
+func FromResponses(rawJSON []byte) (Format, bool) {
+	format := gjson.GetBytes(rawJSON, "text.format")
+	switch format.Get("type").String() {
+	case "json_object":
+		return Format{}, true
+	case "json_schema":
+		return Format{
+			Name:        format.Get("name").String(),
+			Description: format.Get("description").String(),
+			Schema:      objectRaw(format.Get("schema")),
+			Strict:      format.Get("strict").Bool(),
+		}, true
+	}
+	return Format{}, false
+}
+
+// FromGemini reads the structured-output fields of a Gemini generationConfig. A response schema
+// counts even when the JSON mime type Gemini expects next to it is missing. The OpenAPI-style
+// responseSchema has its upper-case type names lowered so it reads as JSON Schema.
+func FromGemini(generationConfig gjson.Result) (Format, bool) {
+	if schema := firstField(generationConfig, "responseJsonSchema", "response_json_schema"); schema.IsObject() {
+		return Format{Schema: schema.Raw}, true
+	}
+	if schema := firstField(generationConfig, "responseSchema", "response_schema"); schema.IsObject() {
+		return Format{Schema: lowerSchemaTypes(schema.Raw)}, true
+	}
+	if firstField(generationConfig, "responseMimeType", "response_mime_type").String() == "application/json" {
+		return Format{}, true
+	}
+	return Format{}, false
+}
+
+// FromClaude reads the output_format parameter of a Claude Messages request.
+func FromClaude(rawJSON []byte) (Format, bool) {
+	format := gjson.GetBytes(rawJSON, "output_format")
+	if format.Get("type").String() != "json_schema" {
+		return Format{}, false
+	}
+	return Format{Schema: objectRaw(format.Get("schema"))}, true
+}
+
+// ApplyOpenAI sets response_format on an OpenAI Chat Completions request.
+func ApplyOpenAI(out []byte, f Format) []byte {
+	if f.Schema == "" {
+		out, _ = sjson.SetRawBytes(out, "response_format", []byte(`{"type":"json_object"}`))
+		return out
+	}
+	format := `{"type":"json_schema","json_schema":{"name":""}}`
+	format, _ = sjson.Set(format, "json_schema.name", f.name())
+	if f.Description != "" {
+		format, _ = sjson.Set(format, "json_schema.description", f.Description)
+	}
+	format, _ = sjson.SetRaw(format, "json_schema.schema", f.Schema)
+	if f.Strict {
+		format, _ = sjson.Set(format, "json_schema.strict", true)
+	}
+	out, _ = sjson.SetRawBytes(out, "response_format", []byte(format))
+	return out
+}
+
+// ApplyResponses sets text.format on an OpenAI Responses or Codex request, keeping any other
+// text settings such as verbosity.
+func ApplyResponses(out []byte, f Format) []byte {
+	if f.Schema == "" {
+		out, _ = sjson.SetRawBytes(out, "text.format", []byte(`{"type":"json_object"}`))
+		return out
+	}
+	format := `{"type":"json_schema","name":""}`
+	format, _ = sjson.Set(format, "name", f.name())
+	if f.Description != "" {
+		format, _ = sjson.Set(format, "description", f.Description)
+	}
+	format, _ = sjson.SetRaw(format, "schema", f.Schema)
+	if f.Strict {
+		format, _ = sjson.Set(format, "strict", true)
+	}
+	out, _ = sjson.SetRawBytes(out, "text.format", []byte(format))
+	return out
+}
+
+// ApplyGemini sets the JSON mime type and response schema on the generationConfig found at
+// configPath, which is "generationConfig" for Gemini and "request.generationConfig" for the
+// Gemini CLI and Antigravity envelopes.
+func ApplyGemini(out []byte, configPath string, f Format) []byte {
+	out, _ = sjson.SetBytes(out, configPath+".responseMimeType", "application/json")
+	if f.Schema != "" {
+		out, _ = sjson.DeleteBytes(out, configPath+".responseSchema")
+		out, _ = sjson.SetRawBytes(out, configPath+".responseJsonSchema", []byte(util.CleanJSONSchemaForGemini(f.Schema)))
+	}
+	return out
+}
+
+func (f Format) name() string {
+	if f.Name != "" {
+		return f.Name
+	}
+	return defaultName
+}
+
+func objectRaw(value gjson.Result) string {
+	if value.IsObject() {
+		return value.Raw
+	}
+	return ""
+}
+
+func firstField(object gjson.Result, names ...string) gjson.Result {
+	for _, name := range names {
+		if value := object.Get(name); value.Exists() {
+			return value
+		}
+	}
+	return gjson.Result{}
+}
+
+// lowerSchemaTypes rewrites OpenAPI type names such as "OBJECT" to their JSON Schema spelling.
+func lowerSchemaTypes(schema string) string {
+	var paths []string
+	util.Walk(gjson.Parse(schema), "", "type", &paths)
+	for _, path := range paths {
+		value := gjson.Get(schema, path)
+		if value.Type != gjson.String {
+			continue
+		}
+		if lowered := strings.ToLower(value.String()); lowered != value.String() {
+			schema, _ = sjson.Set(schema, path, lowered)
+		}
+	}
+	return schema
+}
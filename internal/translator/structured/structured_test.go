@@ -0,0 +1,147 @@
+package structured
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+const weatherSchema = `{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`
+
+func TestFromOpenAI(t *testing.T) {
+	format, ok := FromOpenAI([]byte(`{"response_format":{"type":"json_schema","json_schema":{"name":"weather","strict":true,"schema":` + weatherSchema + `}}}`))
+	if !ok {
+		t.Fatal("json_schema response_format not recognised")
+	}
+	if format.Name != "weather" || !format.Strict || format.Schema != weatherSchema {
+		t.Fatalf("unexpected format: %+v", format)
+	}
+
+	if format, ok = FromOpenAI([]byte(`{"response_format":{"type":"json_object"}}`)); !ok || format.Schema != "" {
+		t.Fatalf("json_object = %+v, %v; want JSON mode", format, ok)
+	}
+	if _, ok = FromOpenAI([]byte(`{"response_format":{"type":"text"}}`)); ok {
+		t.Fatal("text response_format must not request structured output")
+	}
+}
+
+func TestFromGeminiLowersResponseSchemaTypes(t *testing.T) {
+	config := gjson.Parse(`{"responseMimeType":"application/json","responseSchema":{"type":"OBJECT","properties":{"type":{"type":"STRING"}}}}`)
+	format, ok := FromGemini(config)
+	if !ok {
+		t.Fatal("responseSchema not recognised")
+	}
+	if got := gjson.Get(format.Schema, "type").String(); got != "object" {
+		t.Fatalf("root type = %q, want object", got)
+	}
+	if got := gjson.Get(format.Schema, "properties.type.type").String(); got != "string" {
+		t.Fatalf("property type = %q, want string", got)
+	}
+
+	if _, ok = FromGemini(gjson.Parse(`{"responseMimeType":"text/plain"}`)); ok {
+		t.Fatal("text/plain must not request structured output")
+	}
+}
+
+func TestApplyGemini(t *testing.T) {
+	out := ApplyGemini([]byte(`{"request":{"generationConfig":{"responseSchema":{}}}}`), "request.generationConfig", Format{Schema: weatherSchema})
+	config := gjson.GetBytes(out, "request.generationConfig")
+	if config.Get("responseMimeType").String() != "application/json" {
+		t.Fatalf("responseMimeType missing: %s", out)
+	}
+	if config.Get("responseSchema").Exists() {
+		t.Fatalf("responseSchema should be replaced: %s", out)
+	}
+	if config.Get("responseJsonSchema.properties.city.type").String() != "string" {
+		t.Fatalf("responseJsonSchema missing: %s", out)
+	}
+}
+
+func TestApplyResponsesDefaultsName(t *testing.T) {
+	out := ApplyResponses([]byte(`{"text":{"verbosity":"low"}}`), Format{Schema: weatherSchema})
+	if got := gjson.GetBytes(out, "text.format.name").String(); got != defaultName {
+		t.Fatalf("text.format.name = %q, want %q", got, defaultName)
+	}
+	if got := gjson.GetBytes(out, "text.verbosity").String(); got != "low" {
+		t.Fatalf("text.verbosity = %q, want low", got)
+	}
+}
+
+func TestApplyClaudeForcesTool(t *testing.T) {
+	out := ApplyClaude([]byte(`{"tools":[{"name":"get_weather","input_schema":{"type":"object"}}],"tool_choice":{"type":"auto"}}`), Format{Schema: weatherSchema})
+	if !ClaudeEmulated(out) {
+		t.Fatalf("tool_choice does not force the emulation tool: %s", out)
+	}
+	tools := gjson.GetBytes(out, "tools").Array()
+	if len(tools) != 2 || tools[1].Get("name").String() != ClaudeToolName {
+		t.Fatalf("emulation tool not appended: %s", out)
+	}
+	if tools[1].Get("input_schema.properties.city.type").String() != "string" {
+		t.Fatalf("schema not used as input_schema: %s", out)
+	}
+}
+
+func TestUnwrapClaudeResponse(t *testing.T) {
+	request := ApplyClaude([]byte(`{}`), Format{Schema: weatherSchema})
+
+	message := `{"type":"message","content":[{"type":"tool_use","id":"toolu_1","name":"` + ClaudeToolName + `","input":{"city":"Paris"}}],"stop_reason":"tool_use"}`
+	got := gjson.ParseBytes(UnwrapClaudeResponse(request, []byte(message)))
+	if got.Get("content.0.type").String() != "text" || got.Get("content.0.text").String() != `{"city":"Paris"}` {
+		t.Fatalf("tool_use not unwrapped: %s", got.Raw)
+	}
+	if got.Get("stop_reason").String() != "end_turn" {
+		t.Fatalf("stop_reason = %q, want end_turn", got.Get("stop_reason").String())
+	}
+
+	stream := strings.Join([]string{
+		"event: content_block_start",
+		`data: {"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"` + ClaudeToolName + `","input":{}}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"city\""}}`,
+		`data: {"type":"message_delta","delta":{"stop_reason":"tool_use"}}`,
+	}, "\n")
+	lines := strings.Split(string(UnwrapClaudeResponse(request, []byte(stream))), "\n")
+	if lines[0] != "event: content_block_start" {
+		t.Fatalf("event line changed: %q", lines[0])
+	}
+	if got := gjson.Get(strings.TrimPrefix(lines[1], "data: "), "content_block.type").String(); got != "text" {
+		t.Fatalf("content_block.type = %q, want text", got)
+	}
+	if got := gjson.Get(strings.TrimPrefix(lines[2], "data: "), "delta.text").String(); got != `{"city"` {
+		t.Fatalf("delta.text = %q", got)
+	}
+	if got := gjson.Get(strings.TrimPrefix(lines[3], "data: "), "delta.stop_reason").String(); got != "end_turn" {
+		t.Fatalf("delta.stop_reason = %q, want end_turn", got)
+	}
+
+	if unchanged := UnwrapClaudeResponse([]byte(`{}`), []byte(message)); string(unchanged) != message {
+		t.Fatalf("response rewritten without emulation: %s", unchanged)
+	}
+}
+
+func TestApplyClaudeKeepsForcedClientTool(t *testing.T) {
+	for _, choice := range []string{`{"type":"any"}`, `{"type":"tool","name":"get_weather"}`} {
+		request := `{"tools":[{"name":"get_weather","input_schema":{"type":"object"}}],"tool_choice":` + choice + `}`
+		out := ApplyClaude([]byte(request), Format{Schema: weatherSchema})
+		if string(out) != request {
+			t.Fatalf("tool_choice %s: request changed to %s, want the client's forced tool kept", choice, out)
+		}
+		if ClaudeEmulated(out) {
+			t.Fatalf("tool_choice %s: emulation applied", choice)
+		}
+	}
+	if out := ApplyClaude([]byte(`{"tool_choice":{"type":"none"}}`), Format{}); !ClaudeEmulated(out) {
+		t.Fatalf("tool_choice none was not replaced by the emulation: %s", out)
+	}
+}
+
+func TestClaudeEmulatedIgnoresClientTool(t *testing.T) {
+	request := []byte(`{"tools":[{"name":"structured_output","input_schema":{"type":"object"}}],"tool_choice":{"type":"tool","name":"structured_output"}}`)
+	if ClaudeEmulated(request) {
+		t.Fatalf("client tool taken for the emulation: %s", request)
+	}
+	message := `{"type":"message","content":[{"type":"tool_use","id":"toolu_1","name":"structured_output","input":{}}],"stop_reason":"tool_use"}`
+	if got := UnwrapClaudeResponse(request, []byte(message)); string(got) != message {
+		t.Fatalf("client tool call rewritten: %s", got)
+	}
+}
@@ -25,6 +25,27 @@ func Register(from, to string, request interfaces.TranslateRequestFunc, response
 	registry.Register(sdktranslator.FromString(from), sdktranslator.FromString(to), request, response)
 }
 
+// RegisterValidator registers the inbound request validator for an API format.
+//
+// Parameters:
+//   - format: The API format identifier the validator checks
+//   - validator: The function reporting why a payload is malformed
+func RegisterValidator(format string, validator sdktranslator.RequestValidator) {
+	registry.RegisterValidator(sdktranslator.FromString(format), validator)
+}
+
+// Validate checks an inbound request against the validator registered for its API format.
+//
+// Parameters:
+//   - format: The API format identifier of the request
+//   - rawJSON: The raw JSON request data
+//
+// Returns:
+//   - error: A description of the first schema violation, or nil if the request is acceptable
+func Validate(format string, rawJSON []byte) error {
+	return registry.ValidateRequest(sdktranslator.FromString(format), rawJSON)
+}
+
 // Request translates a request from one API format to another.
 //
 // Parameters:
@@ -0,0 +1,257 @@
+// Package transport pools the HTTP transports used for upstream provider and OAuth requests.
+// Transports are shared per proxy URL, TLS profile and provider so that keep-alive connections
+// and TLS sessions are reused across requests instead of being rebuilt for every call.
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/proxy"
+)
+
+// TLSProfileFirefox selects a transport that presents a Firefox TLS fingerprint, for upstreams
+// that block the Go TLS handshake.
+const TLSProfileFirefox = "firefox"
+
+const (
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 32
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultDialTimeout         = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+)
+
+// Key identifies a pooled transport. The zero value is a direct connection with the Go TLS stack
+// shared by all callers that do not name a provider.
+type Key struct {
+	// ProxyURL is the socks5, http or https proxy to dial through; empty dials directly.
+	ProxyURL string
+	// TLSProfile is "" for the standard TLS stack or TLSProfileFirefox.
+	TLSProfile string
+	// Provider keeps the idle connections of one provider from being evicted by another's.
+	Provider string
+	// ConnectTimeout bounds dialing the upstream or proxy; 0 uses the 30s default.
+	ConnectTimeout time.Duration
+	// TLSHandshakeTimeout bounds the TLS handshake; 0 uses the 10s default.
+	TLSHandshakeTimeout time.Duration
+	// NoEnvironmentProxy makes a transport without ProxyURL dial directly instead of following
+	// the HTTP_PROXY and HTTPS_PROXY environment settings.
+	NoEnvironmentProxy bool
+}
+
+// idleCloser is implemented by every pooled transport so eviction can drop its connections.
+type idleCloser interface {
+	http.RoundTripper
+	CloseIdleConnections()
+}
+
+var (
+	mu       sync.Mutex
+	applied  config.TransportConfig
+	proxyURL string
+	pool     = make(map[Key]idleCloser)
+)
+
+// Configure applies the pool settings of cfg. Changed settings evict every pooled transport; a
+// changed global proxy URL evicts the transports built for the previous one.
+func Configure(cfg *config.SDKConfig) {
+	if cfg == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	nextProxy := strings.TrimSpace(cfg.ProxyURL)
+	switch {
+	case cfg.Transport != applied:
+		evictLocked(func(Key) bool { return true })
+	case nextProxy != proxyURL && proxyURL != "":
+		previous := proxyURL
+		evictLocked(func(key Key) bool { return key.ProxyURL == previous })
+	}
+	applied = cfg.Transport
+	proxyURL = nextProxy
+}
+
+// RetainProxyURLs evicts the transports built for per-auth proxy URLs that are no longer in use.
+// Transports for a direct connection, the global proxy or one of urls are kept.
+func RetainProxyURLs(urls []string) {
+	live := make(map[string]struct{}, len(urls))
+	for _, u := range urls {
+		if u = strings.TrimSpace(u); u != "" {
+			live[u] = struct{}{}
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	evictLocked(func(key Key) bool {
+		if key.ProxyURL == "" || key.ProxyURL == proxyURL {
+			return false
+		}
+		_, ok := live[key.ProxyURL]
+		return !ok
+	})
+}
+
+// Evict closes the idle connections of every pooled transport and forgets them. Requests in
+// flight finish on the connections they hold.
+func Evict() {
+	mu.Lock()
+	defer mu.Unlock()
+	evictLocked(func(Key) bool { return true })
+}
+
+func evictLocked(match func(Key) bool) {
+	for key, rt := range pool {
+		if match(key) {
+			rt.CloseIdleConnections()
+			delete(pool, key)
+		}
+	}
+}
+
+// RoundTripper returns the pooled transport for key, building it on first use. It returns nil
+// when the proxy URL cannot be used.
+func RoundTripper(key Key) http.RoundTripper {
+	key.ProxyURL = strings.TrimSpace(key.ProxyURL)
+	mu.Lock()
+	defer mu.Unlock()
+	if rt, ok := pool[key]; ok {
+		return rt
+	}
+	rt := newRoundTripper(key, applied)
+	if rt == nil {
+		return nil
+	}
+	pool[key] = rt
+	return rt
+}
+
+// Client returns an HTTP client on the pooled transport for key. When the proxy URL cannot be
+// used the client falls back to a direct pooled transport.
+func Client(key Key, timeout time.Duration) *http.Client {
+	rt := RoundTripper(key)
+	if rt == nil {
+		log.Debugf("transport: unusable proxy URL %q, dialing directly", key.ProxyURL)
+		key.ProxyURL = ""
+		rt = RoundTripper(key)
+	}
+	return &http.Client{Transport: rt, Timeout: timeout}
+}
+
+func newRoundTripper(key Key, cfg config.TransportConfig) idleCloser {
+	dialer := &net.Dialer{Timeout: durationOr(key.ConnectTimeout, defaultDialTimeout), KeepAlive: 30 * time.Second}
+	if key.TLSProfile == TLSProfileFirefox {
+		utlsDialer, ok := proxyDialer(key.ProxyURL, dialer)
+		if !ok {
+			return nil
+		}
+		return newUTLSRoundTripper(utlsDialer, durationOr(key.TLSHandshakeTimeout, defaultTLSHandshakeTimeout))
+	}
+
+	t := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     !cfg.DisableHTTP2,
+		MaxIdleConns:          valueOr(cfg.MaxIdleConns, defaultMaxIdleConns),
+		MaxIdleConnsPerHost:   valueOr(cfg.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost),
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       defaultIdleConnTimeout,
+		TLSHandshakeTimeout:   durationOr(key.TLSHandshakeTimeout, defaultTLSHandshakeTimeout),
+		ExpectContinueTimeout: time.Second,
+	}
+	if cfg.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = time.Duration(cfg.IdleConnTimeout) * time.Second
+	}
+	if cfg.DisableHTTP2 {
+		// A non-nil, empty map turns off the HTTP/2 upgrade negotiated through ALPN.
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	if key.ProxyURL == "" {
+		if key.NoEnvironmentProxy {
+			t.Proxy = nil
+		}
+		return t
+	}
+
+	parsedURL, errParse := url.Parse(key.ProxyURL)
+	if errParse != nil {
+		log.Errorf("parse proxy URL failed: %v", errParse)
+		return nil
+	}
+	switch parsedURL.Scheme {
+	case "socks5":
+		socks, ok := proxyDialer(key.ProxyURL, dialer)
+		if !ok {
+			return nil
+		}
+		t.Proxy = nil
+		t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if contextDialer, ok := socks.(proxy.ContextDialer); ok {
+				return contextDialer.DialContext(ctx, network, addr)
+			}
+			return socks.Dial(network, addr)
+		}
+	case "http", "https":
+		t.Proxy = http.ProxyURL(parsedURL)
+	default:
+		log.Errorf("unsupported proxy scheme: %s", parsedURL.Scheme)
+		return nil
+	}
+	return t
+}
+
+// proxyDialer returns the dialer for a raw-connection proxy reached through forward, or forward
+// itself when proxyStr is empty.
+func proxyDialer(proxyStr string, forward proxy.Dialer) (proxy.Dialer, bool) {
+	if proxyStr == "" {
+		return forward, true
+	}
+	parsedURL, errParse := url.Parse(proxyStr)
+	if errParse != nil {
+		log.Errorf("parse proxy URL failed: %v", errParse)
+		return nil, false
+	}
+	if parsedURL.Scheme == "socks5" {
+		var proxyAuth *proxy.Auth
+		if parsedURL.User != nil {
+			password, _ := parsedURL.User.Password()
+			proxyAuth = &proxy.Auth{User: parsedURL.User.Username(), Password: password}
+		}
+		dialer, errSOCKS5 := proxy.SOCKS5("tcp", parsedURL.Host, proxyAuth, forward)
+		if errSOCKS5 != nil {
+			log.Errorf("create SOCKS5 dialer failed: %v", errSOCKS5)
+			return nil, false
+		}
+		return dialer, true
+	}
+	dialer, errDialer := proxy.FromURL(parsedURL, forward)
+	if errDialer != nil {
+		log.Errorf("failed to create proxy dialer for %q: %v", proxyStr, errDialer)
+		return nil, false
+	}
+	return dialer, true
+}
+
+func valueOr(value, fallback int) int {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func durationOr(value, fallback time.Duration) time.Duration {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
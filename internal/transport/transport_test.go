@@ -0,0 +1,156 @@
+package transport
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func resetPool(t *testing.T) {
+	t.Helper()
+	Configure(&config.SDKConfig{})
+	Evict()
+	t.Cleanup(func() {
+		Configure(&config.SDKConfig{})
+		Evict()
+	})
+}
+
+func TestRoundTripperIsPooledPerKey(t *testing.T) {
+	resetPool(t)
+
+	direct := RoundTripper(Key{Provider: "codex"})
+	if direct == nil || RoundTripper(Key{Provider: "codex"}) != direct {
+		t.Fatal("same key did not return the pooled transport")
+	}
+	if RoundTripper(Key{Provider: "claude"}) == direct {
+		t.Fatal("providers share a transport")
+	}
+	proxied := RoundTripper(Key{ProxyURL: " http://proxy.local:8080 ", Provider: "codex"})
+	if proxied == nil || proxied == direct {
+		t.Fatal("proxied key reused the direct transport")
+	}
+	if RoundTripper(Key{ProxyURL: "http://proxy.local:8080", Provider: "codex"}) != proxied {
+		t.Fatal("proxy URL whitespace produced a second transport")
+	}
+	if RoundTripper(Key{ProxyURL: "ftp://proxy.local"}) != nil {
+		t.Fatal("unsupported proxy scheme accepted")
+	}
+	if client := Client(Key{ProxyURL: "ftp://proxy.local", Provider: "codex"}, 0); client.Transport != direct {
+		t.Fatal("client with an unusable proxy did not fall back to the direct transport")
+	}
+}
+
+func TestConfigureEvicts(t *testing.T) {
+	resetPool(t)
+
+	Configure(&config.SDKConfig{ProxyURL: "http://old.local:8080"})
+	oldProxy := RoundTripper(Key{ProxyURL: "http://old.local:8080"})
+	direct := RoundTripper(Key{})
+
+	Configure(&config.SDKConfig{ProxyURL: "http://new.local:8080"})
+	if RoundTripper(Key{ProxyURL: "http://old.local:8080"}) == oldProxy {
+		t.Fatal("transport of the replaced proxy survived the reload")
+	}
+	if RoundTripper(Key{}) != direct {
+		t.Fatal("unrelated transport evicted by a proxy change")
+	}
+
+	Configure(&config.SDKConfig{ProxyURL: "http://new.local:8080", Transport: config.TransportConfig{MaxIdleConnsPerHost: 4, DisableHTTP2: true}})
+	rebuilt, ok := RoundTripper(Key{}).(*http.Transport)
+	if !ok || rebuilt == direct {
+		t.Fatal("changed settings did not rebuild the transport")
+	}
+	if rebuilt.MaxIdleConnsPerHost != 4 || rebuilt.TLSNextProto == nil || rebuilt.ForceAttemptHTTP2 {
+		t.Fatalf("settings not applied: idle per host %d, http2 forced %t", rebuilt.MaxIdleConnsPerHost, rebuilt.ForceAttemptHTTP2)
+	}
+}
+
+func TestRetainProxyURLsEvictsUnusedAuthProxies(t *testing.T) {
+	resetPool(t)
+
+	Configure(&config.SDKConfig{ProxyURL: "http://global.local:8080"})
+	global := RoundTripper(Key{ProxyURL: "http://global.local:8080"})
+	direct := RoundTripper(Key{Provider: "codex"})
+	kept := RoundTripper(Key{ProxyURL: "http://kept.local:8080", Provider: "codex"})
+	dropped := RoundTripper(Key{ProxyURL: "socks5://dropped.local:1080", Provider: "claude"})
+
+	RetainProxyURLs([]string{" http://kept.local:8080 "})
+	if RoundTripper(Key{ProxyURL: "socks5://dropped.local:1080", Provider: "claude"}) == dropped {
+		t.Fatal("transport of an unused auth proxy survived")
+	}
+	if RoundTripper(Key{ProxyURL: "http://kept.local:8080", Provider: "codex"}) != kept {
+		t.Fatal("transport of a live auth proxy evicted")
+	}
+	if RoundTripper(Key{ProxyURL: "http://global.local:8080"}) != global {
+		t.Fatal("transport of the global proxy evicted")
+	}
+	if RoundTripper(Key{Provider: "codex"}) != direct {
+		t.Fatal("direct transport evicted")
+	}
+}
+
+func TestPooledTransportReusesConnections(t *testing.T) {
+	resetPool(t)
+
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	for range 3 {
+		resp, err := Client(Key{Provider: "codex"}, 0).Get(server.URL)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+	if got := conns.Load(); got != 1 {
+		t.Fatalf("connections opened = %d, want 1", got)
+	}
+}
+
+func TestFirefoxTransportBoundsTLSHandshake(t *testing.T) {
+	resetPool(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		conn, errAccept := listener.Accept()
+		if errAccept != nil {
+			return
+		}
+		// Hold the connection open without ever answering the client hello.
+		<-done
+		_ = conn.Close()
+	}()
+
+	rt := RoundTripper(Key{TLSProfile: TLSProfileFirefox, TLSHandshakeTimeout: 200 * time.Millisecond})
+	req, _ := http.NewRequest(http.MethodGet, "https://"+listener.Addr().String()+"/", nil)
+	started := time.Now()
+	if _, err = rt.RoundTrip(req); err == nil {
+		t.Fatal("RoundTrip() succeeded against a server that never completes the handshake")
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("handshake ran %v, want it bounded by the 200ms timeout", elapsed)
+	}
+}
@@ -1,16 +1,14 @@
-// Package claude provides authentication functionality for Anthropic's Claude API.
-// This file implements a custom HTTP transport using utls to bypass TLS fingerprinting.
-package claude
+// This file implements the TLSProfileFirefox transport using utls to bypass TLS fingerprinting.
+
+package transport
 
 import (
 	"net/http"
-	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	tls "github.com/refraction-networking/utls"
-	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
-	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/proxy"
 )
@@ -26,29 +24,17 @@ type utlsRoundTripper struct {
 	pending map[string]*sync.Cond
 	// dialer is used to create network connections, supporting proxies
 	dialer proxy.Dialer
+	// handshakeTimeout bounds the TLS handshake of a new connection
+	handshakeTimeout time.Duration
 }
 
-// newUtlsRoundTripper creates a new utls-based round tripper with optional proxy support
-func newUtlsRoundTripper(cfg *config.SDKConfig) *utlsRoundTripper {
-	var dialer proxy.Dialer = proxy.Direct
-	if cfg != nil && cfg.ProxyURL != "" {
-		proxyURL, err := url.Parse(cfg.ProxyURL)
-		if err != nil {
-			log.Errorf("failed to parse proxy URL %q: %v", cfg.ProxyURL, err)
-		} else {
-			pDialer, err := proxy.FromURL(proxyURL, proxy.Direct)
-			if err != nil {
-				log.Errorf("failed to create proxy dialer for %q: %v", cfg.ProxyURL, err)
-			} else {
-				dialer = pDialer
-			}
-		}
-	}
-
+// newUTLSRoundTripper creates a utls-based round tripper dialing through dialer.
+func newUTLSRoundTripper(dialer proxy.Dialer, handshakeTimeout time.Duration) *utlsRoundTripper {
 	return &utlsRoundTripper{
-		connections: make(map[string]*http2.ClientConn),
-		pending:     make(map[string]*sync.Cond),
-		dialer:      dialer,
+		connections:      make(map[string]*http2.ClientConn),
+		pending:          make(map[string]*sync.Cond),
+		dialer:           dialer,
+		handshakeTimeout: handshakeTimeout,
 	}
 }
 
@@ -110,10 +96,12 @@ func (t *utlsRoundTripper) createConnection(host, addr string) (*http2.ClientCon
 	tlsConfig := &tls.Config{ServerName: host}
 	tlsConn := tls.UClient(conn, tlsConfig, tls.HelloFirefox_Auto)
 
+	_ = conn.SetDeadline(time.Now().Add(t.handshakeTimeout))
 	if err := tlsConn.Handshake(); err != nil {
 		conn.Close()
 		return nil, err
 	}
+	_ = conn.SetDeadline(time.Time{})
 
 	tr := &http2.Transport{}
 	h2Conn, err := tr.NewClientConn(tlsConn)
@@ -155,11 +143,14 @@ func (t *utlsRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 	return resp, nil
 }
 
-// NewAnthropicHttpClient creates an HTTP client that bypasses TLS fingerprinting
-// for Anthropic domains by using utls with Firefox fingerprint.
-// It accepts optional SDK configuration for proxy settings.
-func NewAnthropicHttpClient(cfg *config.SDKConfig) *http.Client {
-	return &http.Client{
-		Transport: newUtlsRoundTripper(cfg),
+// CloseIdleConnections closes the cached connections that have no request in flight.
+func (t *utlsRoundTripper) CloseIdleConnections() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for host, h2Conn := range t.connections {
+		if h2Conn.State().StreamsActive == 0 {
+			_ = h2Conn.Close()
+			delete(t.connections, host)
+		}
 	}
 }
@@ -0,0 +1,212 @@
+package usage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fileStorePrefix = "usage-"
+	fileStoreExt    = ".jsonl"
+	// fileStoreMaxLine bounds a single JSON line when reading records back.
+	fileStoreMaxLine = 1 << 20
+)
+
+// FileStore persists usage records as JSON lines, one file per UTC day. Retention removes
+// whole days, so a file is only deleted once every record in it is older than the cutoff.
+type FileStore struct {
+	mu   sync.Mutex
+	dir  string
+	day  string
+	file *os.File
+}
+
+// NewFileStore prepares dir for writing usage records.
+func NewFileStore(dir string) (*FileStore, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil, fmt.Errorf("usage file store: directory is required")
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("usage file store: resolve directory: %w", err)
+	}
+	if err = os.MkdirAll(absDir, 0o700); err != nil {
+		return nil, fmt.Errorf("usage file store: create directory: %w", err)
+	}
+	return &FileStore{dir: absDir}, nil
+}
+
+// Dir returns the directory holding the daily record files.
+func (s *FileStore) Dir() string {
+	if s == nil {
+		return ""
+	}
+	return s.dir
+}
+
+// Append writes record to the file for its UTC day.
+func (s *FileStore) Append(_ context.Context, record StoredRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("usage file store: encode record: %w", err)
+	}
+	data = append(data, '\n')
+	day := record.Timestamp.UTC().Format(time.DateOnly)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil || s.day != day {
+		if s.file != nil {
+			_ = s.file.Close()
+			s.file = nil
+		}
+		f, errOpen := os.OpenFile(s.pathForDay(day), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if errOpen != nil {
+			return fmt.Errorf("usage file store: open %s: %w", day, errOpen)
+		}
+		s.file = f
+		s.day = day
+	}
+	if _, err = s.file.Write(data); err != nil {
+		return fmt.Errorf("usage file store: write record: %w", err)
+	}
+	return nil
+}
+
+// Query implements Store.
+func (s *FileStore) Query(ctx context.Context, from, to time.Time) ([]StoredRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	days, err := s.listDays()
+	if err != nil {
+		return nil, err
+	}
+	var out []StoredRecord
+	for _, day := range days {
+		if ctx != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		start, _ := time.Parse(time.DateOnly, day)
+		if !from.IsZero() && !start.Add(24*time.Hour).After(from) {
+			continue
+		}
+		if !to.IsZero() && !start.Before(to) {
+			continue
+		}
+		records, errRead := readRecordFile(s.pathForDay(day), from, to)
+		if errRead != nil {
+			return nil, errRead
+		}
+		out = append(out, records...)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Timestamp.Before(out[j].Timestamp) })
+	return out, nil
+}
+
+// Prune implements Store.
+func (s *FileStore) Prune(_ context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	days, err := s.listDays()
+	if err != nil {
+		return err
+	}
+	for _, day := range days {
+		start, _ := time.Parse(time.DateOnly, day)
+		if start.Add(24 * time.Hour).After(before) {
+			continue
+		}
+		if day == s.day && s.file != nil {
+			_ = s.file.Close()
+			s.file = nil
+			s.day = ""
+		}
+		if errRemove := os.Remove(s.pathForDay(day)); errRemove != nil && !os.IsNotExist(errRemove) {
+			return fmt.Errorf("usage file store: remove %s: %w", day, errRemove)
+		}
+	}
+	return nil
+}
+
+// Close implements Store.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	s.day = ""
+	return err
+}
+
+func (s *FileStore) pathForDay(day string) string {
+	return filepath.Join(s.dir, fileStorePrefix+day+fileStoreExt)
+}
+
+// listDays returns the days that have a record file, oldest first.
+func (s *FileStore) listDays() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("usage file store: read directory: %w", err)
+	}
+	days := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, fileStorePrefix) || !strings.HasSuffix(name, fileStoreExt) {
+			continue
+		}
+		day := strings.TrimSuffix(strings.TrimPrefix(name, fileStorePrefix), fileStoreExt)
+		if _, errParse := time.Parse(time.DateOnly, day); errParse != nil {
+			continue
+		}
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	return days, nil
+}
+
+func readRecordFile(path string, from, to time.Time) ([]StoredRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("usage file store: open %s: %w", filepath.Base(path), err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var out []StoredRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), fileStoreMaxLine)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var record StoredRecord
+		// A torn trailing line from an interrupted write is skipped rather than failing the query.
+		if errDecode := json.Unmarshal(line, &record); errDecode != nil {
+			continue
+		}
+		if inRange(record.Timestamp, from, to) {
+			out = append(out, record)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("usage file store: read %s: %w", filepath.Base(path), err)
+	}
+	return out, nil
+}
@@ -0,0 +1,152 @@
+package usage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func TestFileStoreQueryAndPrune(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	day3 := day2.Add(24 * time.Hour)
+	for i, ts := range []time.Time{day1, day2, day2.Add(time.Hour), day3} {
+		record := StoredRecord{API: "key-a", Model: "gpt-5", RequestDetail: RequestDetail{
+			Timestamp: ts,
+			Tokens:    TokenStats{InputTokens: 10, OutputTokens: int64(i), TotalTokens: 10 + int64(i)},
+		}}
+		if errAppend := store.Append(ctx, record); errAppend != nil {
+			t.Fatalf("Append() error = %v", errAppend)
+		}
+	}
+
+	records, err := store.Query(ctx, day2, day3)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Query() returned %d records, want 2", len(records))
+	}
+
+	snapshot, err := SnapshotFromStore(ctx, store, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("SnapshotFromStore() error = %v", err)
+	}
+	if snapshot.TotalRequests != 4 || snapshot.TotalTokens != 46 {
+		t.Fatalf("snapshot totals = %d requests / %d tokens, want 4 / 46", snapshot.TotalRequests, snapshot.TotalTokens)
+	}
+	if got := snapshot.APIs["key-a"].Models["gpt-5"].TotalRequests; got != 4 {
+		t.Fatalf("model requests = %d, want 4", got)
+	}
+
+	if err = store.Prune(ctx, day2.Add(time.Hour)); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if _, errStat := os.Stat(filepath.Join(store.Dir(), "usage-2026-03-01.jsonl")); !os.IsNotExist(errStat) {
+		t.Fatalf("expected day 1 file to be pruned, stat error = %v", errStat)
+	}
+	records, err = store.Query(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Query() after prune error = %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Query() after prune returned %d records, want 3", len(records))
+	}
+}
+
+func TestSnapshotFromStoreSkipsHedgedTotals(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	for _, hedged := range []bool{false, true} {
+		record := StoredRecord{API: "key-a", Model: "gpt-5", RequestDetail: RequestDetail{
+			Timestamp: now,
+			Tokens:    TokenStats{InputTokens: 10, TotalTokens: 10},
+			Hedged:    hedged,
+		}}
+		if errAppend := store.Append(ctx, record); errAppend != nil {
+			t.Fatalf("Append() error = %v", errAppend)
+		}
+	}
+
+	snapshot, err := SnapshotFromStore(ctx, store, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("SnapshotFromStore() error = %v", err)
+	}
+	if snapshot.TotalRequests != 1 || snapshot.TotalTokens != 10 {
+		t.Fatalf("snapshot totals = %d requests / %d tokens, want the hedged loser left out", snapshot.TotalRequests, snapshot.TotalTokens)
+	}
+	if got := len(snapshot.APIs["key-a"].Models["gpt-5"].Details); got != 2 {
+		t.Fatalf("model details = %d, want both attempts listed", got)
+	}
+}
+
+func TestFilterSnapshotRestrictsRange(t *testing.T) {
+	stats := NewRequestStatistics()
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		stats.addStored(StoredRecord{API: "key-a", Model: "claude", RequestDetail: RequestDetail{
+			Timestamp: base.Add(time.Duration(i) * time.Hour),
+			Tokens:    TokenStats{TotalTokens: 5},
+			Failed:    i == 2,
+		}})
+	}
+
+	filtered := FilterSnapshot(stats.Snapshot(), base.Add(time.Hour), time.Time{})
+	if filtered.TotalRequests != 2 || filtered.FailureCount != 1 || filtered.TotalTokens != 10 {
+		t.Fatalf("filtered = %+v, want 2 requests, 1 failure, 10 tokens", filtered)
+	}
+}
+
+func TestPersistencePluginStoresMaskedKeys(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	plugin := &PersistencePlugin{store: store, now: time.Now}
+
+	const apiKey = "sk-client-secret-1234"
+	ctx := context.Background()
+	plugin.HandleUsage(ctx, coreusage.Record{
+		APIKey:      apiKey,
+		Model:       "gpt-5",
+		RequestedAt: time.Now(),
+		Detail:      coreusage.Detail{InputTokens: 3, OutputTokens: 4},
+	})
+
+	records, err := store.Query(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Query() returned %d records, want 1", len(records))
+	}
+	if records[0].API == apiKey || records[0].APIKeyHash != HashAPIKey(apiKey) {
+		t.Fatalf("stored api = %q, hash = %q, want a masked key and its hash", records[0].API, records[0].APIKeyHash)
+	}
+
+	stats := NewRequestStatistics()
+	if _, err = RestoreFromStore(ctx, store, stats, time.Time{}, []string{apiKey}); err != nil {
+		t.Fatalf("RestoreFromStore() error = %v", err)
+	}
+	if got := stats.Snapshot().APIs[apiKey].TotalRequests; got != 1 {
+		t.Fatalf("restored requests for the configured key = %d, want 1", got)
+	}
+}
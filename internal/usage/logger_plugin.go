@@ -94,6 +94,9 @@ type RequestDetail struct {
 	AuthIndex string     `json:"auth_index"`
 	Tokens    TokenStats `json:"tokens"`
 	Failed    bool       `json:"failed"`
+	// Hedged marks the losing attempt of a hedged request. It is listed with the model's
+	// details but not counted in request or token totals.
+	Hedged bool `json:"hedged,omitempty"`
 }
 
 // TokenStats captures the token usage breakdown for a request.
@@ -158,12 +161,28 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 	if !statisticsEnabled.Load() {
 		return
 	}
+	s.addStored(resolveStoredRecord(ctx, record))
+}
+
+func (s *RequestStatistics) addStored(record StoredRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats, ok := s.apis[record.API]
+	if !ok {
+		stats = &apiStats{Models: make(map[string]*modelStats)}
+		s.apis[record.API] = stats
+	}
+	s.recordImported(record.API, record.Model, stats, record.RequestDetail)
+}
+
+// resolveStoredRecord derives the API identifier, model and request detail for record using the
+// same rules as the in-memory aggregates.
+func resolveStoredRecord(ctx context.Context, record coreusage.Record) StoredRecord {
 	timestamp := record.RequestedAt
 	if timestamp.IsZero() {
 		timestamp = time.Now()
 	}
-	detail := normaliseDetail(record.Detail)
-	totalTokens := detail.TotalTokens
 	statsKey := record.APIKey
 	if statsKey == "" {
 		statsKey = resolveAPIIdentifier(ctx, record)
@@ -172,42 +191,22 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 	if !failed {
 		failed = !resolveSuccess(ctx)
 	}
-	success := !failed
 	modelName := record.Model
 	if modelName == "" {
 		modelName = "unknown"
 	}
-	dayKey := timestamp.Format("2006-01-02")
-	hourKey := timestamp.Hour()
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	s.totalRequests++
-	if success {
-		s.successCount++
-	} else {
-		s.failureCount++
-	}
-	s.totalTokens += totalTokens
-
-	stats, ok := s.apis[statsKey]
-	if !ok {
-		stats = &apiStats{Models: make(map[string]*modelStats)}
-		s.apis[statsKey] = stats
+	return StoredRecord{
+		API:   statsKey,
+		Model: modelName,
+		RequestDetail: RequestDetail{
+			Timestamp: timestamp,
+			Source:    record.Source,
+			AuthIndex: record.AuthIndex,
+			Tokens:    normaliseDetail(record.Detail),
+			Failed:    failed,
+			Hedged:    record.Hedged,
+		},
 	}
-	s.updateAPIStats(stats, modelName, RequestDetail{
-		Timestamp: timestamp,
-		Source:    record.Source,
-		AuthIndex: record.AuthIndex,
-		Tokens:    detail,
-		Failed:    failed,
-	})
-
-	s.requestsByDay[dayKey]++
-	s.requestsByHour[hourKey]++
-	s.tokensByDay[dayKey] += totalTokens
-	s.tokensByHour[hourKey] += totalTokens
 }
 
 func (s *RequestStatistics) updateAPIStats(stats *apiStats, model string, detail RequestDetail) {
@@ -351,6 +350,15 @@ func (s *RequestStatistics) MergeSnapshot(snapshot StatisticsSnapshot) MergeResu
 }
 
 func (s *RequestStatistics) recordImported(apiName, modelName string, stats *apiStats, detail RequestDetail) {
+	if detail.Hedged {
+		modelStatsValue, ok := stats.Models[modelName]
+		if !ok {
+			modelStatsValue = &modelStats{}
+			stats.Models[modelName] = modelStatsValue
+		}
+		modelStatsValue.Details = append(modelStatsValue.Details, detail)
+		return
+	}
 	totalTokens := detail.Tokens.TotalTokens
 	if totalTokens < 0 {
 		totalTokens = 0
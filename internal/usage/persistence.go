@@ -0,0 +1,210 @@
+package usage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	log "github.com/sirupsen/logrus"
+)
+
+// pruneInterval bounds how often retention is enforced while records are being written.
+const pruneInterval = time.Hour
+
+// StoredRecord is a single request as kept by a persistent Store. Client API keys are never
+// stored: API holds the masked key and APIKeyHash its SHA-256, which lets restores and budgets
+// match records to configured keys.
+type StoredRecord struct {
+	API        string `json:"api"`
+	APIKeyHash string `json:"api_key_hash,omitempty"`
+	Model      string `json:"model"`
+	RequestDetail
+}
+
+// HashAPIKey returns the hex SHA-256 of a client API key as stored in StoredRecord.APIKeyHash.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// protectStoredRecord replaces the client API key of record with its masked form and hash.
+func protectStoredRecord(record StoredRecord, apiKey string) StoredRecord {
+	if apiKey == "" || record.API != apiKey {
+		return record
+	}
+	record.API = util.HideAPIKey(apiKey)
+	record.APIKeyHash = HashAPIKey(apiKey)
+	return record
+}
+
+// Store persists usage records so statistics survive restarts.
+type Store interface {
+	// Append persists a single record.
+	Append(ctx context.Context, record StoredRecord) error
+	// Query returns records with from <= timestamp < to, ordered by timestamp.
+	// A zero bound leaves that side of the range open.
+	Query(ctx context.Context, from, to time.Time) ([]StoredRecord, error)
+	// Prune deletes records older than before. Stores may keep records up to their storage granularity.
+	Prune(ctx context.Context, before time.Time) error
+	// Close releases resources held by the store.
+	Close() error
+}
+
+// PersistencePlugin writes usage records to the active Store and enforces retention.
+// It implements coreusage.Plugin and is inert until a store is configured.
+type PersistencePlugin struct {
+	mu        sync.Mutex
+	store     Store
+	retention time.Duration
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+var defaultPersistence = &PersistencePlugin{now: time.Now}
+
+func init() {
+	coreusage.RegisterPlugin(defaultPersistence)
+}
+
+// SetStore installs store as the persistence backend, closing the previous one.
+// Passing nil disables persistence. Records older than retention are pruned; zero keeps them forever.
+func SetStore(store Store, retention time.Duration) {
+	defaultPersistence.mu.Lock()
+	previous := defaultPersistence.store
+	defaultPersistence.store = store
+	defaultPersistence.retention = retention
+	defaultPersistence.lastPrune = time.Time{}
+	defaultPersistence.mu.Unlock()
+	if previous != nil && previous != store {
+		if err := previous.Close(); err != nil {
+			log.Warnf("usage store: close previous store: %v", err)
+		}
+	}
+}
+
+// SetStoreRetention updates the retention applied to the active store.
+func SetStoreRetention(retention time.Duration) {
+	defaultPersistence.mu.Lock()
+	defaultPersistence.retention = retention
+	defaultPersistence.lastPrune = time.Time{}
+	defaultPersistence.mu.Unlock()
+}
+
+// ActiveStore returns the configured persistence backend, or nil when persistence is disabled.
+func ActiveStore() Store {
+	defaultPersistence.mu.Lock()
+	defer defaultPersistence.mu.Unlock()
+	return defaultPersistence.store
+}
+
+// HandleUsage implements coreusage.Plugin.
+func (p *PersistencePlugin) HandleUsage(ctx context.Context, record coreusage.Record) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	store := p.store
+	p.mu.Unlock()
+	if store == nil {
+		return
+	}
+	// Usage is published after the response completes, so the request context may already be
+	// cancelled; storage writes must not depend on it.
+	stored := protectStoredRecord(resolveStoredRecord(ctx, record), record.APIKey)
+	if err := store.Append(context.Background(), stored); err != nil {
+		log.Warnf("usage store: append record: %v", err)
+	}
+	p.pruneIfDue(store)
+}
+
+func (p *PersistencePlugin) pruneIfDue(store Store) {
+	p.mu.Lock()
+	now := p.now()
+	if p.retention <= 0 || store != p.store || now.Sub(p.lastPrune) < pruneInterval {
+		p.mu.Unlock()
+		return
+	}
+	p.lastPrune = now
+	cutoff := now.Add(-p.retention)
+	p.mu.Unlock()
+	if err := store.Prune(context.Background(), cutoff); err != nil {
+		log.Warnf("usage store: prune records before %s: %v", cutoff.Format(time.RFC3339), err)
+	}
+}
+
+// SnapshotFromStore aggregates the records stored between from (inclusive) and to (exclusive).
+func SnapshotFromStore(ctx context.Context, store Store, from, to time.Time) (StatisticsSnapshot, error) {
+	records, err := store.Query(ctx, from, to)
+	if err != nil {
+		return StatisticsSnapshot{}, err
+	}
+	return snapshotFromRecords(records), nil
+}
+
+// FilterSnapshot returns a snapshot restricted to requests between from (inclusive) and
+// to (exclusive). Zero bounds leave that side of the range open.
+func FilterSnapshot(snapshot StatisticsSnapshot, from, to time.Time) StatisticsSnapshot {
+	var records []StoredRecord
+	for apiName, apiSnapshot := range snapshot.APIs {
+		for modelName, modelSnapshot := range apiSnapshot.Models {
+			for _, detail := range modelSnapshot.Details {
+				if inRange(detail.Timestamp, from, to) {
+					records = append(records, StoredRecord{API: apiName, Model: modelName, RequestDetail: detail})
+				}
+			}
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Timestamp.Before(records[j].Timestamp) })
+	return snapshotFromRecords(records)
+}
+
+// RestoreFromStore merges stored records newer than since into stats, skipping duplicates.
+// Records of the given client API keys are filed under the key again, as live records are.
+func RestoreFromStore(ctx context.Context, store Store, stats *RequestStatistics, since time.Time, apiKeys []string) (MergeResult, error) {
+	if store == nil || stats == nil {
+		return MergeResult{}, nil
+	}
+	records, err := store.Query(ctx, since, time.Time{})
+	if err != nil {
+		return MergeResult{}, err
+	}
+	keysByHash := make(map[string]string, len(apiKeys))
+	for _, key := range apiKeys {
+		if key != "" {
+			keysByHash[HashAPIKey(key)] = key
+		}
+	}
+	for i := range records {
+		if key, ok := keysByHash[records[i].APIKeyHash]; ok {
+			records[i].API = key
+		}
+	}
+	return stats.MergeSnapshot(snapshotFromRecords(records)), nil
+}
+
+func snapshotFromRecords(records []StoredRecord) StatisticsSnapshot {
+	stats := NewRequestStatistics()
+	for _, record := range records {
+		if record.Model == "" {
+			record.Model = "unknown"
+		}
+		record.Tokens = normaliseTokenStats(record.Tokens)
+		stats.addStored(record)
+	}
+	return stats.Snapshot()
+}
+
+func inRange(ts, from, to time.Time) bool {
+	if !from.IsZero() && ts.Before(from) {
+		return false
+	}
+	if !to.IsZero() && !ts.Before(to) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package util
+
+import "strings"
+
+// MatchModelPattern reports whether model matches pattern, where '*' matches zero or more
+// characters. Both sides are trimmed and compared case-insensitively; an empty pattern matches
+// nothing. It is the single matcher behind model globs in client allow-lists, model fallbacks,
+// hedging rules and payload rules, so a pattern means the same thing everywhere.
+// Examples:
+//
+//	"*-5" matches "gpt-5"
+//	"gpt-*" matches "gpt-5" and "GPT-4"
+//	"gemini-*-pro" matches "gemini-2.5-pro" and "gemini-3-pro".
+func MatchModelPattern(pattern, model string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	model = strings.ToLower(strings.TrimSpace(model))
+	if pattern == "" {
+		return false
+	}
+	if pattern == "*" {
+		return true
+	}
+	// Iterative glob-style matcher supporting only '*' wildcard.
+	pi, si := 0, 0
+	starIdx, matchIdx := -1, 0
+	for si < len(model) {
+		switch {
+		case pi < len(pattern) && pattern[pi] == model[si]:
+			pi++
+			si++
+		case pi < len(pattern) && pattern[pi] == '*':
+			starIdx, matchIdx = pi, si
+			pi++
+		case starIdx != -1:
+			pi = starIdx + 1
+			matchIdx++
+			si = matchIdx
+		default:
+			return false
+		}
+	}
+	for pi < len(pattern) && pattern[pi] == '*' {
+		pi++
+	}
+	return pi == len(pattern)
+}
@@ -0,0 +1,28 @@
+package util
+
+import "testing"
+
+func TestMatchModelPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		model   string
+		want    bool
+	}{
+		{pattern: "gpt-5", model: "gpt-5", want: true},
+		{pattern: "gpt-*", model: "gpt-5-mini", want: true},
+		{pattern: "*-5", model: "gpt-5", want: true},
+		{pattern: "gemini-*-pro", model: "gemini-2.5-pro", want: true},
+		{pattern: "*flash*", model: "gemini-2.5-flash-lite", want: true},
+		{pattern: "GPT-*", model: "gpt-5", want: true},
+		{pattern: " gpt-5 ", model: "GPT-5", want: true},
+		{pattern: "*", model: "anything", want: true},
+		{pattern: "gpt-*", model: "claude-sonnet-4", want: false},
+		{pattern: "gpt-5", model: "gpt-5-mini", want: false},
+		{pattern: "", model: "gpt-5", want: false},
+	}
+	for _, tt := range tests {
+		if got := MatchModelPattern(tt.pattern, tt.model); got != tt.want {
+			t.Errorf("MatchModelPattern(%q, %q) = %v, want %v", tt.pattern, tt.model, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+// DiffAnthropicCompatibility produces human-readable change descriptions for
+// anthropic-compatibility providers, matched by name (or base-url when unnamed).
+func DiffAnthropicCompatibility(oldList, newList []config.AnthropicCompatibility) []string {
+	oldMap := anthropicCompatByKey(oldList)
+	newMap := anthropicCompatByKey(newList)
+	keys := make([]string, 0, len(oldMap)+len(newMap))
+	for key := range oldMap {
+		keys = append(keys, key)
+	}
+	for key := range newMap {
+		if _, ok := oldMap[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make([]string, 0)
+	for _, key := range keys {
+		oldEntry, oldOk := oldMap[key]
+		newEntry, newOk := newMap[key]
+		switch {
+		case !oldOk:
+			changes = append(changes, fmt.Sprintf("provider added: %s (api-keys=%d, models=%d)", anthropicCompatLabel(newEntry), countAnthropicAPIKeys(newEntry), len(newEntry.Models)))
+		case !newOk:
+			changes = append(changes, fmt.Sprintf("provider removed: %s (api-keys=%d, models=%d)", anthropicCompatLabel(oldEntry), countAnthropicAPIKeys(oldEntry), len(oldEntry.Models)))
+		default:
+			details := make([]string, 0, 4)
+			if strings.TrimSpace(oldEntry.BaseURL) != strings.TrimSpace(newEntry.BaseURL) {
+				details = append(details, fmt.Sprintf("base-url %s -> %s", strings.TrimSpace(oldEntry.BaseURL), strings.TrimSpace(newEntry.BaseURL)))
+			}
+			if oldCount, newCount := countAnthropicAPIKeys(oldEntry), countAnthropicAPIKeys(newEntry); oldCount != newCount {
+				details = append(details, fmt.Sprintf("api-keys %d -> %d", oldCount, newCount))
+			}
+			if ComputeAnthropicCompatModelsHash(oldEntry.Models) != ComputeAnthropicCompatModelsHash(newEntry.Models) {
+				details = append(details, fmt.Sprintf("models %d -> %d", len(oldEntry.Models), len(newEntry.Models)))
+			}
+			if !equalStringMap(oldEntry.Headers, newEntry.Headers) {
+				details = append(details, "headers updated")
+			}
+			if len(details) > 0 {
+				changes = append(changes, fmt.Sprintf("provider updated: %s (%s)", anthropicCompatLabel(newEntry), strings.Join(details, ", ")))
+			}
+		}
+	}
+	return changes
+}
+
+func anthropicCompatByKey(list []config.AnthropicCompatibility) map[string]config.AnthropicCompatibility {
+	out := make(map[string]config.AnthropicCompatibility, len(list))
+	for idx, entry := range list {
+		key := "name:" + strings.ToLower(strings.TrimSpace(entry.Name))
+		if strings.TrimSpace(entry.Name) == "" {
+			key = fmt.Sprintf("base:%s#%d", strings.TrimSpace(entry.BaseURL), idx)
+		}
+		out[key] = entry
+	}
+	return out
+}
+
+func anthropicCompatLabel(entry config.AnthropicCompatibility) string {
+	if name := strings.TrimSpace(entry.Name); name != "" {
+		return name
+	}
+	return strings.TrimSpace(entry.BaseURL)
+}
+
+func countAnthropicAPIKeys(entry config.AnthropicCompatibility) int {
+	count := 0
+	for _, keyEntry := range entry.APIKeyEntries {
+		if strings.TrimSpace(keyEntry.APIKey) != "" {
+			count++
+		}
+	}
+	return count
+}
@@ -33,6 +33,27 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if strings.TrimSpace(oldCfg.Pprof.Addr) != strings.TrimSpace(newCfg.Pprof.Addr) {
 		changes = append(changes, fmt.Sprintf("pprof.addr: %s -> %s", strings.TrimSpace(oldCfg.Pprof.Addr), strings.TrimSpace(newCfg.Pprof.Addr)))
 	}
+	if oldCfg.Metrics.Enable != newCfg.Metrics.Enable {
+		changes = append(changes, fmt.Sprintf("metrics.enable: %t -> %t", oldCfg.Metrics.Enable, newCfg.Metrics.Enable))
+	}
+	if oldCfg.Metrics.Addr != newCfg.Metrics.Addr {
+		changes = append(changes, fmt.Sprintf("metrics.addr: %s -> %s", oldCfg.Metrics.Addr, newCfg.Metrics.Addr))
+	}
+	if oldCfg.Tracing.Enable != newCfg.Tracing.Enable {
+		changes = append(changes, fmt.Sprintf("tracing.enable: %t -> %t", oldCfg.Tracing.Enable, newCfg.Tracing.Enable))
+	}
+	if oldCfg.Tracing.Endpoint != newCfg.Tracing.Endpoint {
+		changes = append(changes, fmt.Sprintf("tracing.endpoint: %s -> %s", oldCfg.Tracing.Endpoint, newCfg.Tracing.Endpoint))
+	}
+	if oldCfg.Tracing.ServiceName != newCfg.Tracing.ServiceName {
+		changes = append(changes, fmt.Sprintf("tracing.service-name: %s -> %s", oldCfg.Tracing.ServiceName, newCfg.Tracing.ServiceName))
+	}
+	if oldCfg.Tracing.SampleRatio != newCfg.Tracing.SampleRatio {
+		changes = append(changes, fmt.Sprintf("tracing.sample-ratio: %g -> %g", oldCfg.Tracing.SampleRatio, newCfg.Tracing.SampleRatio))
+	}
+	if oldCfg.Tracing.PropagateUpstream != newCfg.Tracing.PropagateUpstream {
+		changes = append(changes, fmt.Sprintf("tracing.propagate-upstream: %t -> %t", oldCfg.Tracing.PropagateUpstream, newCfg.Tracing.PropagateUpstream))
+	}
 	if oldCfg.LoggingToFile != newCfg.LoggingToFile {
 		changes = append(changes, fmt.Sprintf("logging-to-file: %t -> %t", oldCfg.LoggingToFile, newCfg.LoggingToFile))
 	}
@@ -45,6 +66,18 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if oldCfg.RequestLog != newCfg.RequestLog {
 		changes = append(changes, fmt.Sprintf("request-log: %t -> %t", oldCfg.RequestLog, newCfg.RequestLog))
 	}
+	if oldCfg.UsageStore.Enable != newCfg.UsageStore.Enable {
+		changes = append(changes, fmt.Sprintf("usage-store.enable: %t -> %t", oldCfg.UsageStore.Enable, newCfg.UsageStore.Enable))
+	}
+	if oldCfg.UsageStore.Backend != newCfg.UsageStore.Backend {
+		changes = append(changes, fmt.Sprintf("usage-store.backend: %s -> %s", oldCfg.UsageStore.Backend, newCfg.UsageStore.Backend))
+	}
+	if oldCfg.UsageStore.Path != newCfg.UsageStore.Path {
+		changes = append(changes, fmt.Sprintf("usage-store.path: %s -> %s", oldCfg.UsageStore.Path, newCfg.UsageStore.Path))
+	}
+	if oldCfg.UsageStore.RetentionDays != newCfg.UsageStore.RetentionDays {
+		changes = append(changes, fmt.Sprintf("usage-store.retention-days: %d -> %d", oldCfg.UsageStore.RetentionDays, newCfg.UsageStore.RetentionDays))
+	}
 	if oldCfg.LogsMaxTotalSizeMB != newCfg.LogsMaxTotalSizeMB {
 		changes = append(changes, fmt.Sprintf("logs-max-total-size-mb: %d -> %d", oldCfg.LogsMaxTotalSizeMB, newCfg.LogsMaxTotalSizeMB))
 	}
@@ -69,6 +102,18 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if oldCfg.NonStreamKeepAliveInterval != newCfg.NonStreamKeepAliveInterval {
 		changes = append(changes, fmt.Sprintf("nonstream-keepalive-interval: %d -> %d", oldCfg.NonStreamKeepAliveInterval, newCfg.NonStreamKeepAliveInterval))
 	}
+	if oldCfg.RateLimit.RequestsPerMinute != newCfg.RateLimit.RequestsPerMinute {
+		changes = append(changes, fmt.Sprintf("rate-limit.requests-per-minute: %d -> %d", oldCfg.RateLimit.RequestsPerMinute, newCfg.RateLimit.RequestsPerMinute))
+	}
+	if oldCfg.RateLimit.TokensPerMinute != newCfg.RateLimit.TokensPerMinute {
+		changes = append(changes, fmt.Sprintf("rate-limit.tokens-per-minute: %d -> %d", oldCfg.RateLimit.TokensPerMinute, newCfg.RateLimit.TokensPerMinute))
+	}
+	if oldCfg.RateLimit.MaxConcurrentStreams != newCfg.RateLimit.MaxConcurrentStreams {
+		changes = append(changes, fmt.Sprintf("rate-limit.max-concurrent-streams: %d -> %d", oldCfg.RateLimit.MaxConcurrentStreams, newCfg.RateLimit.MaxConcurrentStreams))
+	}
+	if !reflect.DeepEqual(oldCfg.RateLimit.Overrides, newCfg.RateLimit.Overrides) {
+		changes = append(changes, fmt.Sprintf("rate-limit.overrides: updated (%d -> %d entries)", len(oldCfg.RateLimit.Overrides), len(newCfg.RateLimit.Overrides)))
+	}
 
 	// Quota-exceeded behavior
 	if oldCfg.QuotaExceeded.SwitchProject != newCfg.QuotaExceeded.SwitchProject {
@@ -81,6 +126,11 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if oldCfg.Routing.Strategy != newCfg.Routing.Strategy {
 		changes = append(changes, fmt.Sprintf("routing.strategy: %s -> %s", oldCfg.Routing.Strategy, newCfg.Routing.Strategy))
 	}
+	if oldCfg.Routing.SessionAffinity != newCfg.Routing.SessionAffinity {
+		o, n := oldCfg.Routing.SessionAffinity, newCfg.Routing.SessionAffinity
+		changes = append(changes, fmt.Sprintf("routing.session-affinity: enable=%t ttl-seconds=%d header=%s -> enable=%t ttl-seconds=%d header=%s",
+			o.Enable, o.TTLSeconds, o.Header, n.Enable, n.TTLSeconds, n.Header))
+	}
 
 	// API keys (redacted) and counts
 	if len(oldCfg.APIKeys) != len(newCfg.APIKeys) {
@@ -88,6 +138,9 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	} else if !reflect.DeepEqual(trimStrings(oldCfg.APIKeys), trimStrings(newCfg.APIKeys)) {
 		changes = append(changes, "api-keys: values updated (count unchanged, redacted)")
 	}
+	if !reflect.DeepEqual(oldCfg.ClientKeys, newCfg.ClientKeys) {
+		changes = append(changes, fmt.Sprintf("client-keys: updated (%d -> %d entries)", len(oldCfg.ClientKeys), len(newCfg.ClientKeys)))
+	}
 	if len(oldCfg.GeminiKey) != len(newCfg.GeminiKey) {
 		changes = append(changes, fmt.Sprintf("gemini-api-key count: %d -> %d", len(oldCfg.GeminiKey), len(newCfg.GeminiKey)))
 	} else {
@@ -245,6 +298,9 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if entries, _ := DiffOAuthModelAliasChanges(oldCfg.OAuthModelAlias, newCfg.OAuthModelAlias); len(entries) > 0 {
 		changes = append(changes, entries...)
 	}
+	if !reflect.DeepEqual(oldCfg.ModelFallbacks, newCfg.ModelFallbacks) {
+		changes = append(changes, fmt.Sprintf("model-fallbacks: updated (%d -> %d models)", len(oldCfg.ModelFallbacks), len(newCfg.ModelFallbacks)))
+	}
 
 	// Remote management (never print the key)
 	if oldCfg.RemoteManagement.AllowRemote != newCfg.RemoteManagement.AllowRemote {
@@ -277,6 +333,14 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 		}
 	}
 
+	// Anthropic compatibility providers (summarized)
+	if compat := DiffAnthropicCompatibility(oldCfg.AnthropicCompatibility, newCfg.AnthropicCompatibility); len(compat) > 0 {
+		changes = append(changes, "anthropic-compatibility:")
+		for _, c := range compat {
+			changes = append(changes, "  "+c)
+		}
+	}
+
 	// Vertex-compatible API keys
 	if len(oldCfg.VertexCompatAPIKey) != len(newCfg.VertexCompatAPIKey) {
 		changes = append(changes, fmt.Sprintf("vertex-api-key count: %d -> %d", len(oldCfg.VertexCompatAPIKey), len(newCfg.VertexCompatAPIKey)))
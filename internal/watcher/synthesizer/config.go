@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/diff"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 )
@@ -33,6 +34,8 @@ func (s *ConfigSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth,
 	out = append(out, s.synthesizeCodexKeys(ctx)...)
 	// OpenAI-compat
 	out = append(out, s.synthesizeOpenAICompat(ctx)...)
+	// Anthropic-compat
+	out = append(out, s.synthesizeAnthropicCompat(ctx)...)
 	// Vertex-compat
 	out = append(out, s.synthesizeVertexCompat(ctx)...)
 
@@ -63,6 +66,9 @@ func (s *ConfigSynthesizer) synthesizeGeminiKeys(ctx *SynthesisContext) []*corea
 		if entry.Priority != 0 {
 			attrs["priority"] = strconv.Itoa(entry.Priority)
 		}
+		if entry.Weight > 0 {
+			attrs["weight"] = strconv.Itoa(entry.Weight)
+		}
 		if base != "" {
 			attrs["base_url"] = base
 		}
@@ -70,6 +76,7 @@ func (s *ConfigSynthesizer) synthesizeGeminiKeys(ctx *SynthesisContext) []*corea
 			attrs["models_hash"] = hash
 		}
 		addConfigHeadersToAttrs(entry.Headers, attrs)
+		addConfigTimeoutsToAttrs(entry.Timeouts, attrs)
 		a := &coreauth.Auth{
 			ID:         id,
 			Provider:   "gemini",
@@ -110,6 +117,9 @@ func (s *ConfigSynthesizer) synthesizeClaudeKeys(ctx *SynthesisContext) []*corea
 		if ck.Priority != 0 {
 			attrs["priority"] = strconv.Itoa(ck.Priority)
 		}
+		if ck.Weight > 0 {
+			attrs["weight"] = strconv.Itoa(ck.Weight)
+		}
 		if base != "" {
 			attrs["base_url"] = base
 		}
@@ -117,6 +127,7 @@ func (s *ConfigSynthesizer) synthesizeClaudeKeys(ctx *SynthesisContext) []*corea
 			attrs["models_hash"] = hash
 		}
 		addConfigHeadersToAttrs(ck.Headers, attrs)
+		addConfigTimeoutsToAttrs(ck.Timeouts, attrs)
 		proxyURL := strings.TrimSpace(ck.ProxyURL)
 		a := &coreauth.Auth{
 			ID:         id,
@@ -157,6 +168,9 @@ func (s *ConfigSynthesizer) synthesizeCodexKeys(ctx *SynthesisContext) []*coreau
 		if ck.Priority != 0 {
 			attrs["priority"] = strconv.Itoa(ck.Priority)
 		}
+		if ck.Weight > 0 {
+			attrs["weight"] = strconv.Itoa(ck.Weight)
+		}
 		if ck.BaseURL != "" {
 			attrs["base_url"] = ck.BaseURL
 		}
@@ -167,6 +181,7 @@ func (s *ConfigSynthesizer) synthesizeCodexKeys(ctx *SynthesisContext) []*coreau
 			attrs["models_hash"] = hash
 		}
 		addConfigHeadersToAttrs(ck.Headers, attrs)
+		addConfigTimeoutsToAttrs(ck.Timeouts, attrs)
 		proxyURL := strings.TrimSpace(ck.ProxyURL)
 		a := &coreauth.Auth{
 			ID:         id,
@@ -218,6 +233,9 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 			if compat.Priority != 0 {
 				attrs["priority"] = strconv.Itoa(compat.Priority)
 			}
+			if weight := compatWeight(compat, entry); weight > 0 {
+				attrs["weight"] = strconv.Itoa(weight)
+			}
 			if key != "" {
 				attrs["api_key"] = key
 			}
@@ -225,6 +243,7 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 				attrs["models_hash"] = hash
 			}
 			addConfigHeadersToAttrs(compat.Headers, attrs)
+			addConfigTimeoutsToAttrs(compat.Timeouts, attrs)
 			a := &coreauth.Auth{
 				ID:         id,
 				Provider:   providerName,
@@ -252,10 +271,14 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 			if compat.Priority != 0 {
 				attrs["priority"] = strconv.Itoa(compat.Priority)
 			}
+			if compat.Weight > 0 {
+				attrs["weight"] = strconv.Itoa(compat.Weight)
+			}
 			if hash := diff.ComputeOpenAICompatModelsHash(compat.Models); hash != "" {
 				attrs["models_hash"] = hash
 			}
 			addConfigHeadersToAttrs(compat.Headers, attrs)
+			addConfigTimeoutsToAttrs(compat.Timeouts, attrs)
 			a := &coreauth.Auth{
 				ID:         id,
 				Provider:   providerName,
@@ -272,6 +295,74 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 	return out
 }
 
+// synthesizeAnthropicCompat creates Auth entries for Anthropic-compatible providers, one per API
+// key entry, or a single keyless entry when the provider lists none.
+func (s *ConfigSynthesizer) synthesizeAnthropicCompat(ctx *SynthesisContext) []*coreauth.Auth {
+	cfg := ctx.Config
+	now := ctx.Now
+	idGen := ctx.IDGenerator
+
+	out := make([]*coreauth.Auth, 0)
+	for i := range cfg.AnthropicCompatibility {
+		compat := &cfg.AnthropicCompatibility[i]
+		providerName := strings.ToLower(strings.TrimSpace(compat.Name))
+		if providerName == "" {
+			providerName = "anthropic-compatibility"
+		}
+		base := strings.TrimSpace(compat.BaseURL)
+		idKind := fmt.Sprintf("anthropic-compatibility:%s", providerName)
+		modelsHash := diff.ComputeAnthropicCompatModelsHash(compat.Models)
+
+		entries := compat.APIKeyEntries
+		if len(entries) == 0 {
+			entries = []config.AnthropicCompatibilityAPIKey{{}}
+		}
+		for j := range entries {
+			entry := &entries[j]
+			key := strings.TrimSpace(entry.APIKey)
+			proxyURL := strings.TrimSpace(entry.ProxyURL)
+			id, token := idGen.Next(idKind, key, base, proxyURL)
+			attrs := map[string]string{
+				"source":                fmt.Sprintf("config:%s[%s]", providerName, token),
+				"base_url":              base,
+				"anthropic_compat_name": compat.Name,
+				"provider_key":          providerName,
+			}
+			if compat.Priority != 0 {
+				attrs["priority"] = strconv.Itoa(compat.Priority)
+			}
+			weight := compat.Weight
+			if entry.Weight > 0 {
+				weight = entry.Weight
+			}
+			if weight > 0 {
+				attrs["weight"] = strconv.Itoa(weight)
+			}
+			if key != "" {
+				attrs["api_key"] = key
+			}
+			if modelsHash != "" {
+				attrs["models_hash"] = modelsHash
+			}
+			addConfigHeadersToAttrs(compat.Headers, attrs)
+			addConfigTimeoutsToAttrs(compat.Timeouts, attrs)
+			a := &coreauth.Auth{
+				ID:         id,
+				Provider:   providerName,
+				Label:      compat.Name,
+				Prefix:     strings.TrimSpace(compat.Prefix),
+				Status:     coreauth.StatusActive,
+				ProxyURL:   proxyURL,
+				Attributes: attrs,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			}
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
 // synthesizeVertexCompat creates Auth entries for Vertex-compatible providers.
 func (s *ConfigSynthesizer) synthesizeVertexCompat(ctx *SynthesisContext) []*coreauth.Auth {
 	cfg := ctx.Config
@@ -297,6 +388,9 @@ func (s *ConfigSynthesizer) synthesizeVertexCompat(ctx *SynthesisContext) []*cor
 		if compat.Priority != 0 {
 			attrs["priority"] = strconv.Itoa(compat.Priority)
 		}
+		if compat.Weight > 0 {
+			attrs["weight"] = strconv.Itoa(compat.Weight)
+		}
 		if key != "" {
 			attrs["api_key"] = key
 		}
@@ -304,6 +398,7 @@ func (s *ConfigSynthesizer) synthesizeVertexCompat(ctx *SynthesisContext) []*cor
 			attrs["models_hash"] = hash
 		}
 		addConfigHeadersToAttrs(compat.Headers, attrs)
+		addConfigTimeoutsToAttrs(compat.Timeouts, attrs)
 		a := &coreauth.Auth{
 			ID:         id,
 			Provider:   providerName,
@@ -320,3 +415,14 @@ func (s *ConfigSynthesizer) synthesizeVertexCompat(ctx *SynthesisContext) []*cor
 	}
 	return out
 }
+
+// compatWeight returns the per-key weight when set, falling back to the provider-level weight.
+func compatWeight(compat *config.OpenAICompatibility, entry *config.OpenAICompatibilityAPIKey) int {
+	if entry != nil && entry.Weight > 0 {
+		return entry.Weight
+	}
+	if compat != nil {
+		return compat.Weight
+	}
+	return 0
+}
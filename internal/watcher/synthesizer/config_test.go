@@ -225,6 +225,34 @@ func TestConfigSynthesizer_ClaudeKeys_SkipsEmptyAndHeaders(t *testing.T) {
 	}
 }
 
+func TestConfigSynthesizer_ClaudeKeys_Timeouts(t *testing.T) {
+	synth := NewConfigSynthesizer()
+	ctx := &SynthesisContext{
+		Config: &config.Config{
+			ClaudeKey: []config.ClaudeKey{
+				{APIKey: "valid-key", Timeouts: &config.Timeouts{FirstByte: 30, Idle: 60}},
+			},
+		},
+		Now:         time.Now(),
+		IDGenerator: NewStableIDGenerator(),
+	}
+
+	auths, err := synth.Synthesize(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(auths) != 1 {
+		t.Fatalf("expected 1 auth, got %d", len(auths))
+	}
+	attrs := auths[0].Attributes
+	if attrs["timeout:first-byte"] != "30" || attrs["timeout:idle"] != "60" {
+		t.Errorf("expected first-byte and idle timeouts, got %v", attrs)
+	}
+	if _, ok := attrs["timeout:connect"]; ok {
+		t.Error("expected unset connect timeout to be omitted")
+	}
+}
+
 func TestConfigSynthesizer_CodexKeys(t *testing.T) {
 	synth := NewConfigSynthesizer()
 	ctx := &SynthesisContext{
@@ -369,6 +397,99 @@ func TestConfigSynthesizer_OpenAICompat(t *testing.T) {
 	}
 }
 
+func TestConfigSynthesizer_OpenAICompat_Weight(t *testing.T) {
+	synth := NewConfigSynthesizer()
+	ctx := &SynthesisContext{
+		Config: &config.Config{
+			OpenAICompatibility: []config.OpenAICompatibility{
+				{
+					Name:    "Weighted",
+					BaseURL: "https://weighted.api.com",
+					Weight:  3,
+					APIKeyEntries: []config.OpenAICompatibilityAPIKey{
+						{APIKey: "key-1"},
+						{APIKey: "key-2", Weight: 7},
+					},
+				},
+			},
+		},
+		Now:         time.Now(),
+		IDGenerator: NewStableIDGenerator(),
+	}
+
+	auths, err := synth.Synthesize(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(auths) != 2 {
+		t.Fatalf("expected 2 auths, got %d", len(auths))
+	}
+	if got := auths[0].Attributes["weight"]; got != "3" {
+		t.Errorf("expected provider-level weight 3, got %q", got)
+	}
+	if got := auths[1].Attributes["weight"]; got != "7" {
+		t.Errorf("expected per-key weight 7, got %q", got)
+	}
+}
+
+func TestConfigSynthesizer_AnthropicCompat(t *testing.T) {
+	synth := NewConfigSynthesizer()
+	ctx := &SynthesisContext{
+		Config: &config.Config{
+			AnthropicCompatibility: []config.AnthropicCompatibility{
+				{
+					Name:    "Relay",
+					BaseURL: "https://relay.example.com",
+					Weight:  2,
+					Headers: map[string]string{"X-Team": "core"},
+					APIKeyEntries: []config.AnthropicCompatibilityAPIKey{
+						{APIKey: "key-1"},
+						{APIKey: "key-2", Weight: 5},
+					},
+					Models: []config.AnthropicCompatibilityModel{{Name: "claude-sonnet-4", Alias: "sonnet"}},
+				},
+				{Name: "", BaseURL: "https://keyless.example.com"},
+			},
+		},
+		Now:         time.Now(),
+		IDGenerator: NewStableIDGenerator(),
+	}
+
+	auths, err := synth.Synthesize(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(auths) != 3 {
+		t.Fatalf("expected 3 auths, got %d", len(auths))
+	}
+	first := auths[0]
+	if first.Provider != "relay" {
+		t.Errorf("expected provider relay, got %q", first.Provider)
+	}
+	if first.Attributes["anthropic_compat_name"] != "Relay" || first.Attributes["base_url"] != "https://relay.example.com" {
+		t.Errorf("unexpected attributes: %v", first.Attributes)
+	}
+	if first.Attributes["api_key"] != "key-1" || first.Attributes["header:X-Team"] != "core" {
+		t.Errorf("expected api key and header attributes, got %v", first.Attributes)
+	}
+	if first.Attributes["models_hash"] == "" {
+		t.Error("expected models_hash to be set")
+	}
+	if got := first.Attributes["weight"]; got != "2" {
+		t.Errorf("expected provider-level weight 2, got %q", got)
+	}
+	if got := auths[1].Attributes["weight"]; got != "5" {
+		t.Errorf("expected per-key weight 5, got %q", got)
+	}
+	keyless := auths[2]
+	if keyless.Provider != "anthropic-compatibility" {
+		t.Errorf("expected default provider for an unnamed entry, got %q", keyless.Provider)
+	}
+	if _, ok := keyless.Attributes["api_key"]; ok {
+		t.Errorf("expected no api_key for a keyless entry, got %v", keyless.Attributes)
+	}
+}
+
 func TestConfigSynthesizer_VertexCompat(t *testing.T) {
 	synth := NewConfigSynthesizer()
 	ctx := &SynthesisContext{
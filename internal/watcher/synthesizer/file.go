@@ -112,15 +112,19 @@ func (s *FileSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth, e
 			CreatedAt: now,
 			UpdatedAt: now,
 		}
-		// Read priority from auth file
-		if rawPriority, ok := metadata["priority"]; ok {
-			switch v := rawPriority.(type) {
+		// Read priority and weight from auth file
+		for _, key := range []string{"priority", "weight"} {
+			rawValue, ok := metadata[key]
+			if !ok {
+				continue
+			}
+			switch v := rawValue.(type) {
 			case float64:
-				a.Attributes["priority"] = strconv.Itoa(int(v))
+				a.Attributes[key] = strconv.Itoa(int(v))
 			case string:
-				priority := strings.TrimSpace(v)
-				if _, errAtoi := strconv.Atoi(priority); errAtoi == nil {
-					a.Attributes["priority"] = priority
+				value := strings.TrimSpace(v)
+				if _, errAtoi := strconv.Atoi(value); errAtoi == nil {
+					a.Attributes[key] = value
 				}
 			}
 		}
@@ -183,10 +187,13 @@ func SynthesizeGeminiVirtualAuths(primary *coreauth.Auth, metadata map[string]an
 		if authPath != "" {
 			attrs["path"] = authPath
 		}
-		// Propagate priority from primary auth to virtual auths
+		// Propagate priority and weight from primary auth to virtual auths
 		if priorityVal, hasPriority := primary.Attributes["priority"]; hasPriority && priorityVal != "" {
 			attrs["priority"] = priorityVal
 		}
+		if weightVal, hasWeight := primary.Attributes["weight"]; hasWeight && weightVal != "" {
+			attrs["weight"] = weightVal
+		}
 		metadataCopy := map[string]any{
 			"email":             email,
 			"project_id":        projectID,
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
@@ -118,3 +119,22 @@ func addConfigHeadersToAttrs(headers map[string]string, attrs map[string]string)
 		attrs["header:"+key] = val
 	}
 }
+
+// addConfigTimeoutsToAttrs adds credential-level upstream timeouts to auth attributes.
+// Each phase that is set is stored in seconds under a "timeout:" prefixed key.
+func addConfigTimeoutsToAttrs(timeouts *config.Timeouts, attrs map[string]string) {
+	if timeouts == nil || attrs == nil {
+		return
+	}
+	for key, seconds := range map[string]int{
+		"connect":       timeouts.Connect,
+		"tls-handshake": timeouts.TLSHandshake,
+		"first-byte":    timeouts.FirstByte,
+		"idle":          timeouts.Idle,
+		"total":         timeouts.Total,
+	} {
+		if seconds > 0 {
+			attrs["timeout:"+key] = strconv.Itoa(seconds)
+		}
+	}
+}
@@ -12,6 +12,8 @@ type AuthErrorCode string
 const (
 	AuthErrorCodeNoCredentials     AuthErrorCode = "no_credentials"
 	AuthErrorCodeInvalidCredential AuthErrorCode = "invalid_credential"
+	AuthErrorCodeExpiredCredential AuthErrorCode = "expired_credential"
+	AuthErrorCodeQuotaExceeded     AuthErrorCode = "quota_exceeded"
 	AuthErrorCodeNotHandled        AuthErrorCode = "not_handled"
 	AuthErrorCodeInternal          AuthErrorCode = "internal_error"
 )
@@ -70,6 +72,18 @@ func NewInvalidCredentialError() *AuthError {
 	return newAuthError(AuthErrorCodeInvalidCredential, "Invalid API key", http.StatusUnauthorized, nil)
 }
 
+func NewExpiredCredentialError() *AuthError {
+	return newAuthError(AuthErrorCodeExpiredCredential, "API key expired", http.StatusUnauthorized, nil)
+}
+
+func NewQuotaExceededError(message string) *AuthError {
+	normalizedMessage := strings.TrimSpace(message)
+	if normalizedMessage == "" {
+		normalizedMessage = "API key quota exceeded"
+	}
+	return newAuthError(AuthErrorCodeQuotaExceeded, normalizedMessage, http.StatusTooManyRequests, nil)
+}
+
 func NewNotHandledError() *AuthError {
 	return newAuthError(AuthErrorCodeNotHandled, "authentication provider did not handle request", 0, nil)
 }
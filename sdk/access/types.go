@@ -1,5 +1,10 @@
 package access
 
+import (
+	"encoding/json"
+	"strings"
+)
+
 // AccessConfig groups request authentication providers.
 type AccessConfig struct {
 	// Providers lists configured authentication providers.
@@ -32,6 +37,34 @@ const (
 	DefaultAccessProviderName = "config-inline"
 )
 
+// Result metadata keys populated for structured client keys. List values are JSON-encoded string arrays.
+const (
+	// MetadataKeyClientName carries the configured name of the client key.
+	MetadataKeyClientName = "client-name"
+	// MetadataKeyAllowedModels carries the model globs the client may call.
+	MetadataKeyAllowedModels = "allowed-models"
+	// MetadataKeyAllowedCredentialPrefixes carries the credential prefixes the client may route to.
+	MetadataKeyAllowedCredentialPrefixes = "allowed-credential-prefixes"
+	// MetadataKeyExpiresAt carries the key expiry as an RFC 3339 timestamp.
+	MetadataKeyExpiresAt = "expires-at"
+	// MetadataKeyMonthlyTokenBudget carries the monthly token budget of the key.
+	MetadataKeyMonthlyTokenBudget = "monthly-token-budget"
+)
+
+// MetadataList decodes a JSON-encoded string array stored under key in result metadata.
+// The boolean reports whether the key was present and well-formed.
+func MetadataList(metadata map[string]string, key string) ([]string, bool) {
+	raw, ok := metadata[key]
+	if !ok || strings.TrimSpace(raw) == "" {
+		return nil, false
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil, false
+	}
+	return values, true
+}
+
 // MakeInlineAPIKeyProvider constructs an inline API key provider configuration.
 // It returns nil when no keys are supplied.
 func MakeInlineAPIKeyProvider(keys []string) *AccessProvider {
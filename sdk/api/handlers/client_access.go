@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// accessMetadataFromGin returns the metadata recorded by the access middleware.
+func accessMetadataFromGin(c *gin.Context) map[string]string {
+	if c == nil {
+		return nil
+	}
+	raw, exists := c.Get("accessMetadata")
+	if !exists {
+		return nil
+	}
+	metadata, _ := raw.(map[string]string)
+	return metadata
+}
+
+func accessMetadataFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok {
+		return nil
+	}
+	return accessMetadataFromGin(ginCtx)
+}
+
+// clientModelAllowed reports whether model matches one of the allowed globs. The thinking
+// suffix is ignored so "gpt-5(high)" is governed by the same entries as "gpt-5".
+func clientModelAllowed(allowed []string, model string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	model = strings.TrimSpace(model)
+	base := strings.TrimSpace(thinking.ParseSuffix(model).ModelName)
+	for _, pattern := range allowed {
+		if util.MatchModelPattern(pattern, model) || (base != "" && util.MatchModelPattern(pattern, base)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ModelMatchesPatterns reports whether model matches one of patterns, with the glob and thinking
+// suffix rules of client key allow-lists. An empty pattern list matches nothing.
+func ModelMatchesPatterns(patterns []string, model string) bool {
+	return len(patterns) > 0 && clientModelAllowed(patterns, model)
+}
+
+// checkClientAccess enforces the caller's model allow-list and forwards it, together with the
+// credential prefix restrictions, to the auth manager through the execution metadata so model
+// fallbacks stay within the list.
+func checkClientAccess(ctx context.Context, handlerType, model string, reqMeta map[string]any) *interfaces.ErrorMessage {
+	metadata := accessMetadataFromContext(ctx)
+	if len(metadata) == 0 {
+		return nil
+	}
+	if allowed, ok := sdkaccess.MetadataList(metadata, sdkaccess.MetadataKeyAllowedModels); ok {
+		if !clientModelAllowed(allowed, model) {
+			message := fmt.Sprintf("API key is not allowed to use model %s", model)
+			return &interfaces.ErrorMessage{
+				StatusCode: http.StatusForbidden,
+				Error:      errors.New(string(protocolErrorBody(handlerType, http.StatusForbidden, message))),
+			}
+		}
+		if reqMeta != nil {
+			reqMeta[coreexecutor.AllowedModelsMetadataKey] = allowed
+		}
+	}
+	if prefixes, ok := sdkaccess.MetadataList(metadata, sdkaccess.MetadataKeyAllowedCredentialPrefixes); ok && reqMeta != nil {
+		reqMeta[coreexecutor.AllowedAuthPrefixesMetadataKey] = prefixes
+	}
+	return nil
+}
+
+// FilterModelsForClient drops models the authenticated client key is not allowed to call.
+// Model identifiers are read from the "id" field, falling back to "name" with any "models/"
+// prefix removed.
+func (h *BaseAPIHandler) FilterModelsForClient(c *gin.Context, models []map[string]any) []map[string]any {
+	allowed, ok := sdkaccess.MetadataList(accessMetadataFromGin(c), sdkaccess.MetadataKeyAllowedModels)
+	if !ok {
+		return models
+	}
+	filtered := make([]map[string]any, 0, len(models))
+	for _, model := range models {
+		id, _ := model["id"].(string)
+		if id == "" {
+			name, _ := model["name"].(string)
+			id = strings.TrimPrefix(name, "models/")
+		}
+		if clientModelAllowed(allowed, id) {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
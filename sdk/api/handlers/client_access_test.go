@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/tidwall/gjson"
+)
+
+func newClientAccessContext(metadata map[string]string) (*gin.Context, context.Context) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	c.Set("accessMetadata", metadata)
+	return c, context.WithValue(context.Background(), "gin", c)
+}
+
+func TestClientModelAllowed(t *testing.T) {
+	allowed := []string{"claude-*", "teamA/gpt-5"}
+	cases := map[string]bool{
+		"claude-sonnet-4-5":        true,
+		"Claude-Opus-4-5(high)":    true,
+		"teamA/gpt-5":              true,
+		"gpt-5":                    false,
+		"gemini-2.5-pro":           false,
+		"teamA/gpt-5(8192)":        true,
+		"prefix-claude-sonnet-4-5": false,
+	}
+	for model, want := range cases {
+		if got := clientModelAllowed(allowed, model); got != want {
+			t.Fatalf("clientModelAllowed(%q) = %t, want %t", model, got, want)
+		}
+	}
+	if !clientModelAllowed(nil, "anything") {
+		t.Fatal("clientModelAllowed(nil) = false, want true")
+	}
+}
+
+func TestCheckClientAccess_RejectsDisallowedModel(t *testing.T) {
+	_, ctx := newClientAccessContext(map[string]string{
+		sdkaccess.MetadataKeyAllowedModels:             `["claude-*"]`,
+		sdkaccess.MetadataKeyAllowedCredentialPrefixes: `["teamA"]`,
+	})
+
+	errMsg := checkClientAccess(ctx, constant.Claude, "gpt-5", map[string]any{})
+	if errMsg == nil {
+		t.Fatal("checkClientAccess() = nil, want forbidden")
+	}
+	if errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("StatusCode = %d, want %d", errMsg.StatusCode, http.StatusForbidden)
+	}
+	if got := gjson.Get(errMsg.Error.Error(), "error.type").String(); got != "permission_error" {
+		t.Fatalf("error.type = %q, want %q", got, "permission_error")
+	}
+
+	reqMeta := map[string]any{}
+	if errMsg = checkClientAccess(ctx, constant.Claude, "claude-sonnet-4-5", reqMeta); errMsg != nil {
+		t.Fatalf("checkClientAccess() error = %v", errMsg.Error)
+	}
+	prefixes, _ := reqMeta[coreexecutor.AllowedAuthPrefixesMetadataKey].([]string)
+	if len(prefixes) != 1 || prefixes[0] != "teamA" {
+		t.Fatalf("allowed prefixes = %v, want [teamA]", prefixes)
+	}
+	models, _ := reqMeta[coreexecutor.AllowedModelsMetadataKey].([]string)
+	if len(models) != 1 || models[0] != "claude-*" {
+		t.Fatalf("allowed models = %v, want [claude-*] forwarded for model fallbacks", models)
+	}
+}
+
+func TestFilterModelsForClient(t *testing.T) {
+	c, _ := newClientAccessContext(map[string]string{sdkaccess.MetadataKeyAllowedModels: `["gemini-*"]`})
+	handler := NewBaseAPIHandlers(nil, nil)
+
+	models := []map[string]any{
+		{"id": "claude-sonnet-4-5"},
+		{"id": "gemini-2.5-pro"},
+		{"name": "models/gemini-2.5-flash"},
+	}
+	filtered := handler.FilterModelsForClient(c, models)
+	if len(filtered) != 2 {
+		t.Fatalf("FilterModelsForClient() = %v, want 2 gemini models", filtered)
+	}
+
+	unrestricted, _ := newClientAccessContext(nil)
+	if got := handler.FilterModelsForClient(unrestricted, models); len(got) != len(models) {
+		t.Fatalf("FilterModelsForClient() without restrictions = %d models, want %d", len(got), len(models))
+	}
+}
+
+// exhaustedPrimaryExecutor rate-limits the primary model and answers every other model with its
+// name.
+type exhaustedPrimaryExecutor struct {
+	primary string
+}
+
+func (e *exhaustedPrimaryExecutor) Identifier() string { return "agreement" }
+
+func (e *exhaustedPrimaryExecutor) Execute(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	if req.Model == e.primary {
+		return coreexecutor.Response{}, &coreauth.Error{HTTPStatus: http.StatusTooManyRequests, Message: "quota exhausted"}
+	}
+	return coreexecutor.Response{Payload: []byte(req.Model)}, nil
+}
+
+func (e *exhaustedPrimaryExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "ExecuteStream not implemented"}
+}
+
+func (e *exhaustedPrimaryExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *exhaustedPrimaryExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *exhaustedPrimaryExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func TestClientAllowListAndModelFallbackAgree(t *testing.T) {
+	const primary, fallback = "agreement-primary", "agreement-fallback-pro"
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("agreement-auth", "agreement", []*registry.ModelInfo{{ID: primary}, {ID: fallback}})
+	t.Cleanup(func() { reg.UnregisterClient("agreement-auth") })
+
+	for _, patterns := range [][]string{
+		{"agreement-fallback-pro"},
+		{"AGREEMENT-FALLBACK-*"},
+		{" agreement-*-pro "},
+		{"*"},
+		{"agreement-primary"},
+		{"Agreement-Fallback"},
+		{"other-*"},
+	} {
+		manager := coreauth.NewManager(nil, &coreauth.FillFirstSelector{}, nil)
+		manager.SetConfig(&internalconfig.Config{ModelFallbacks: map[string][]string{primary: {fallback}}})
+		manager.RegisterExecutor(&exhaustedPrimaryExecutor{primary: primary})
+		if _, err := manager.Register(context.Background(), &coreauth.Auth{ID: "agreement-auth", Provider: "agreement", Status: coreauth.StatusActive}); err != nil {
+			t.Fatalf("manager.Register: %v", err)
+		}
+
+		opts := coreexecutor.Options{Metadata: map[string]any{coreexecutor.AllowedModelsMetadataKey: patterns}}
+		resp, err := manager.Execute(context.Background(), []string{"agreement"}, coreexecutor.Request{Model: primary}, opts)
+		fellBack := err == nil && string(resp.Payload) == fallback
+		if allowed := clientModelAllowed(patterns, fallback); fellBack != allowed {
+			t.Errorf("patterns %q: client allow-list admits %s = %t, model fallback served it = %t", patterns, fallback, allowed, fellBack)
+		}
+	}
+}
@@ -0,0 +1,520 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// defaultFanOutMaxChoices caps fan-out when fan-out.max-choices is not configured.
+const defaultFanOutMaxChoices = 8
+
+// singleChoiceProviders return one choice per request whatever n or candidateCount asks for.
+var singleChoiceProviders = map[string]struct{}{
+	constant.Claude:      {},
+	constant.Codex:       {},
+	constant.GeminiCLI:   {},
+	constant.Antigravity: {},
+}
+
+// fanOut serves a multiple-choice request with one single-choice execution per choice. Each
+// branch runs through the auth manager on its own, so the selector spreads branches across
+// credentials; with fan-out.distinct-credentials set they are pinned to distinct ones instead.
+type fanOut struct {
+	handlerType string
+	choices     int
+	authIDs     []string
+	metas       []map[string]any
+}
+
+// fanOutChoices reads the number of choices a request asks for and reports whether the proxy
+// has to fan it out: only OpenAI chat and Gemini requests carry a choice count, and only
+// providers in singleChoiceProviders ignore it.
+func fanOutChoices(cfg *config.SDKConfig, handlerType, alt string, providers []string, rawJSON []byte) (int, *interfaces.ErrorMessage) {
+	if alt == "embeddings" {
+		return 1, nil
+	}
+	var choices int64
+	switch handlerType {
+	case constant.OpenAI:
+		choices = gjson.GetBytes(rawJSON, "n").Int()
+	case constant.Gemini:
+		choices = gjson.GetBytes(rawJSON, "generationConfig.candidateCount").Int()
+	}
+	if choices <= 1 {
+		return 1, nil
+	}
+	singleChoice := false
+	for _, provider := range providers {
+		if _, ok := singleChoiceProviders[provider]; ok {
+			singleChoice = true
+			break
+		}
+	}
+	if !singleChoice {
+		return 1, nil
+	}
+	maxChoices := defaultFanOutMaxChoices
+	if cfg != nil && cfg.FanOut.MaxChoices > 0 {
+		maxChoices = cfg.FanOut.MaxChoices
+	}
+	if choices > int64(maxChoices) {
+		message := fmt.Sprintf("at most %d choices can be requested for this model, got %d", maxChoices, choices)
+		return 0, &interfaces.ErrorMessage{
+			StatusCode: http.StatusBadRequest,
+			Error:      errors.New(string(protocolErrorBody(handlerType, http.StatusBadRequest, message))),
+		}
+	}
+	return int(choices), nil
+}
+
+// newFanOut prepares a fan-out of choices branches. When distinct credentials are requested and
+// the caller has not pinned one, the usable credentials for the model are listed up front and the
+// branches are assigned to them round-robin.
+func (h *BaseAPIHandler) newFanOut(handlerType string, choices int, providers []string, model string, meta map[string]any) *fanOut {
+	fan := &fanOut{handlerType: handlerType, choices: choices, metas: make([]map[string]any, choices)}
+	if h.Cfg == nil || !h.Cfg.FanOut.DistinctCredentials || h.AuthManager == nil {
+		return fan
+	}
+	if pinned, _ := meta[coreexecutor.PinnedAuthMetadataKey].(string); pinned != "" {
+		return fan
+	}
+	allowedPrefixes, _ := meta[coreexecutor.AllowedAuthPrefixesMetadataKey].([]string)
+	modelKey := model
+	if parsed := thinking.ParseSuffix(model); parsed.ModelName != "" {
+		modelKey = parsed.ModelName
+	}
+	for _, auth := range h.AuthManager.List() {
+		if auth == nil || auth.Disabled || !containsString(providers, auth.Provider) {
+			continue
+		}
+		if len(allowedPrefixes) > 0 && !containsFold(allowedPrefixes, strings.TrimSpace(auth.Prefix)) {
+			continue
+		}
+		if !registry.GetGlobalRegistry().ClientSupportsModel(auth.ID, modelKey) {
+			continue
+		}
+		fan.authIDs = append(fan.authIDs, auth.ID)
+	}
+	if len(fan.authIDs) < 2 {
+		fan.authIDs = nil
+	}
+	return fan
+}
+
+// branch returns the request and options for one branch: the choice count is dropped from the
+// payload, and the branch gets its own metadata map because the auth manager writes to it.
+func (f *fanOut) branch(i int, req coreexecutor.Request, opts coreexecutor.Options, pinned bool) (coreexecutor.Request, coreexecutor.Options) {
+	payload := req.Payload
+	switch f.handlerType {
+	case constant.OpenAI:
+		payload, _ = sjson.DeleteBytes(payload, "n")
+	case constant.Gemini:
+		payload, _ = sjson.DeleteBytes(payload, "generationConfig.candidateCount")
+	}
+	req.Payload = payload
+	opts.OriginalRequest = payload
+
+	meta := make(map[string]any, len(opts.Metadata)+1)
+	for key, value := range opts.Metadata {
+		meta[key] = value
+	}
+	if key, ok := meta[idempotencyKeyMetadataKey].(string); ok {
+		meta[idempotencyKeyMetadataKey] = fmt.Sprintf("%s-%d", key, i)
+	}
+	if pinned && len(f.authIDs) > 0 {
+		meta[coreexecutor.PinnedAuthMetadataKey] = f.authIDs[i%len(f.authIDs)]
+	}
+	opts.Metadata = meta
+	f.metas[i] = meta
+	return req, opts
+}
+
+// recordServedModel copies the model that served the first branch into the request metadata.
+func (f *fanOut) recordServedModel(meta map[string]any) {
+	if meta == nil || f.metas[0] == nil {
+		return
+	}
+	if served, ok := f.metas[0][coreexecutor.ServedModelMetadataKey]; ok {
+		meta[coreexecutor.ServedModelMetadataKey] = served
+	}
+}
+
+// executeFanOut runs every branch in parallel and merges the responses. The first failure cancels the
+// remaining branches and fails the request. A branch pinned to a credential that fails is retried
+// once without the pin so distinct-credential routing never costs availability.
+func (h *BaseAPIHandler) executeFanOut(ctx context.Context, fan *fanOut, providers []string, req coreexecutor.Request, opts coreexecutor.Options) (coreexecutor.Response, error) {
+	responses := make([]coreexecutor.Response, fan.choices)
+	errRun := runParallel(ctx, fan.choices, func(ctx context.Context, i int) error {
+		branchReq, branchOpts := fan.branch(i, req, opts, true)
+		resp, err := h.AuthManager.Execute(ctx, providers, branchReq, branchOpts)
+		if err != nil && len(fan.authIDs) > 0 && ctx.Err() == nil {
+			branchReq, branchOpts = fan.branch(i, req, opts, false)
+			resp, err = h.AuthManager.Execute(ctx, providers, branchReq, branchOpts)
+		}
+		responses[i] = resp
+		return err
+	})
+	if errRun != nil {
+		return coreexecutor.Response{}, errRun
+	}
+	fan.recordServedModel(opts.Metadata)
+
+	payloads := make([][]byte, len(responses))
+	for i, resp := range responses {
+		payloads[i] = resp.Payload
+	}
+	return coreexecutor.Response{Payload: fan.merge(payloads), Headers: responses[0].Headers}, nil
+}
+
+// runParallel calls run for every index in its own goroutine. The first failure cancels the context
+// passed to the remaining calls and is returned once all of them have stopped.
+func runParallel(ctx context.Context, count int, run func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for i := range count {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := run(ctx, i); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// ExecuteBatchWithAuthManager executes one non-streaming request per payload in parallel and
+// returns the responses in payload order. Handlers use it when a single client request needs
+// several upstream requests, such as batched completion prompts or multiple generated images.
+// The batch is checked and rate limited as one client request; the first failure cancels the
+// remaining requests and fails the batch. The returned headers are those of the first response.
+func (h *BaseAPIHandler) ExecuteBatchWithAuthManager(ctx context.Context, handlerType, modelName string, payloads [][]byte, alt string) ([][]byte, http.Header, *interfaces.ErrorMessage) {
+	if len(payloads) == 1 {
+		resp, headers, errMsg := h.ExecuteWithAuthManager(ctx, handlerType, modelName, payloads[0], alt)
+		if errMsg != nil {
+			return nil, nil, errMsg
+		}
+		return [][]byte{resp}, headers, nil
+	}
+	for _, payload := range payloads {
+		if errInvalid := validateClientRequest(handlerType, alt, payload); errInvalid != nil {
+			return nil, nil, errInvalid
+		}
+	}
+	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
+	if errMsg != nil {
+		return nil, nil, errMsg
+	}
+	choices := make([]int, len(payloads))
+	for i, payload := range payloads {
+		var errChoices *interfaces.ErrorMessage
+		if choices[i], errChoices = fanOutChoices(h.Cfg, handlerType, alt, providers, payload); errChoices != nil {
+			return nil, nil, errChoices
+		}
+	}
+	reqMeta := requestExecutionMetadata(ctx)
+	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
+	if errAccess := checkClientAccess(ctx, handlerType, normalizedModel, reqMeta); errAccess != nil {
+		return nil, nil, errAccess
+	}
+	release, errLimit := h.acquireClientRateLimit(ctx, handlerType, false)
+	if errLimit != nil {
+		return nil, nil, errLimit
+	}
+	defer release()
+
+	ctx, span := tracing.Start(ctx, "cliproxy.handler.execute", handlerSpanAttributes(handlerType, normalizedModel, false)...)
+	defer span.End()
+	responses := make([]coreexecutor.Response, len(payloads))
+	metas := make([]map[string]any, len(payloads))
+	errRun := runParallel(ctx, len(payloads), func(ctx context.Context, i int) error {
+		meta := make(map[string]any, len(reqMeta))
+		for key, value := range reqMeta {
+			meta[key] = value
+		}
+		if key, ok := meta[idempotencyKeyMetadataKey].(string); ok {
+			meta[idempotencyKeyMetadataKey] = fmt.Sprintf("%s-%d", key, i)
+		}
+		metas[i] = meta
+		req := coreexecutor.Request{Model: normalizedModel, Payload: payloads[i]}
+		opts := coreexecutor.Options{
+			Alt:             alt,
+			Headers:         requestHeadersFromContext(ctx),
+			OriginalRequest: payloads[i],
+			SourceFormat:    sdktranslator.FromString(handlerType),
+			Metadata:        meta,
+		}
+		var err error
+		responses[i], err = h.executeRequest(ctx, handlerType, choices[i], providers, req, opts)
+		return err
+	})
+	if errRun != nil {
+		tracing.RecordError(span, errRun)
+		return nil, nil, executionErrorMessage(errRun)
+	}
+	setServedModelHeader(ctx, normalizedModel, metas[0])
+
+	out := make([][]byte, len(responses))
+	for i, resp := range responses {
+		out[i] = resp.Payload
+	}
+	if !PassthroughHeadersEnabled(h.Cfg) {
+		return out, nil, nil
+	}
+	return out, FilterUpstreamHeaders(responses[0].Headers), nil
+}
+
+// merge combines single-choice responses into one response carrying every choice.
+func (f *fanOut) merge(payloads [][]byte) []byte {
+	listPath, usagePath := f.paths()
+	out := payloads[0]
+	items := "[]"
+	usage := ""
+	for _, payload := range payloads {
+		gjson.GetBytes(payload, listPath).ForEach(func(_, item gjson.Result) bool {
+			updated, _ := sjson.Set(item.Raw, "index", gjson.Get(items, "#").Int())
+			items, _ = sjson.SetRaw(items, "-1", updated)
+			return true
+		})
+		if value := gjson.GetBytes(payload, usagePath); value.IsObject() {
+			usage = addUsage(usage, value)
+		}
+	}
+	out, _ = sjson.SetRawBytes(out, listPath, []byte(items))
+	if usage != "" {
+		out, _ = sjson.SetRawBytes(out, usagePath, []byte(usage))
+	}
+	return out
+}
+
+func (f *fanOut) paths() (listPath, usagePath string) {
+	if f.handlerType == constant.Gemini {
+		return "candidates", "usageMetadata"
+	}
+	return "choices", "usage"
+}
+
+// executeStreamFanOut opens every branch stream and interleaves their chunks as they arrive. The
+// request fails if any branch cannot be opened.
+func (h *BaseAPIHandler) executeStreamFanOut(ctx context.Context, fan *fanOut, providers []string, req coreexecutor.Request, opts coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	branchCtx, cancel := context.WithCancel(ctx)
+	results := make([]*coreexecutor.StreamResult, fan.choices)
+	errs := make([]error, fan.choices)
+	var wg sync.WaitGroup
+	for i := range fan.choices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			branchReq, branchOpts := fan.branch(i, req, opts, true)
+			results[i], errs[i] = h.AuthManager.ExecuteStream(branchCtx, providers, branchReq, branchOpts)
+			if errs[i] != nil && len(fan.authIDs) > 0 {
+				branchReq, branchOpts = fan.branch(i, req, opts, false)
+				results[i], errs[i] = h.AuthManager.ExecuteStream(branchCtx, providers, branchReq, branchOpts)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+	fan.recordServedModel(opts.Metadata)
+
+	type indexedChunk struct {
+		branch int
+		chunk  coreexecutor.StreamChunk
+	}
+	incoming := make(chan indexedChunk)
+	var branches sync.WaitGroup
+	for i, result := range results {
+		branches.Add(1)
+		go func() {
+			defer branches.Done()
+			for chunk := range result.Chunks {
+				select {
+				case incoming <- indexedChunk{branch: i, chunk: chunk}:
+				case <-branchCtx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		branches.Wait()
+		close(incoming)
+	}()
+
+	out := make(chan coreexecutor.StreamChunk)
+	go func() {
+		defer close(out)
+		defer cancel()
+		merger := newFanOutStreamMerger(fan)
+		send := func(chunk coreexecutor.StreamChunk) bool {
+			select {
+			case out <- chunk:
+				return true
+			case <-branchCtx.Done():
+				return false
+			}
+		}
+		for item := range incoming {
+			if item.chunk.Err != nil {
+				send(item.chunk)
+				return
+			}
+			if payload := merger.rewrite(item.branch, item.chunk.Payload); len(payload) > 0 {
+				if !send(coreexecutor.StreamChunk{Payload: payload}) {
+					return
+				}
+			}
+		}
+		if payload := merger.finish(); len(payload) > 0 {
+			send(coreexecutor.StreamChunk{Payload: payload})
+		}
+	}()
+	return &coreexecutor.StreamResult{Headers: results[0].Headers, Chunks: out}, nil
+}
+
+// fanOutStreamMerger rewrites branch chunks into one stream. Choice indexes follow the branch,
+// OpenAI chunks share the first branch's id, and usage is aggregated: Gemini chunks carry the
+// running total of every branch, while OpenAI usage is held back and sent in one final chunk.
+type fanOutStreamMerger struct {
+	fan   *fanOut
+	id    string
+	last  gjson.Result
+	usage []gjson.Result
+}
+
+func newFanOutStreamMerger(fan *fanOut) *fanOutStreamMerger {
+	return &fanOutStreamMerger{fan: fan, usage: make([]gjson.Result, fan.choices)}
+}
+
+func (m *fanOutStreamMerger) rewrite(branch int, payload []byte) []byte {
+	trimmed := strings.TrimSpace(string(payload))
+	if !strings.HasPrefix(trimmed, "{") || !gjson.Valid(trimmed) {
+		return payload
+	}
+	chunk := trimmed
+	listPath, usagePath := m.fan.paths()
+	root := gjson.Parse(chunk)
+	root.Get(listPath).ForEach(func(key, _ gjson.Result) bool {
+		chunk, _ = sjson.Set(chunk, listPath+"."+key.String()+".index", branch)
+		return true
+	})
+	if usage := root.Get(usagePath); usage.IsObject() {
+		m.usage[branch] = usage
+		if m.fan.handlerType == constant.Gemini {
+			chunk, _ = sjson.SetRaw(chunk, usagePath, m.totalUsage())
+		} else {
+			chunk, _ = sjson.Delete(chunk, usagePath)
+		}
+	}
+	if m.fan.handlerType == constant.OpenAI {
+		if m.id == "" {
+			m.id = root.Get("id").String()
+		} else if root.Get("id").Exists() {
+			chunk, _ = sjson.Set(chunk, "id", m.id)
+		}
+		if root.Get("created").Exists() {
+			m.last = root
+		}
+		if len(root.Get(listPath).Array()) == 0 && root.Get(usagePath).Exists() {
+			return nil
+		}
+	}
+	return []byte(chunk)
+}
+
+// finish returns the closing OpenAI usage chunk, or nil when no branch reported usage.
+func (m *fanOutStreamMerger) finish() []byte {
+	if m.fan.handlerType != constant.OpenAI {
+		return nil
+	}
+	usage := m.totalUsage()
+	if usage == "" {
+		return nil
+	}
+	chunk := `{"id":"","object":"chat.completion.chunk","created":0,"model":"","choices":[]}`
+	chunk, _ = sjson.Set(chunk, "id", m.id)
+	chunk, _ = sjson.Set(chunk, "created", m.last.Get("created").Int())
+	chunk, _ = sjson.Set(chunk, "model", m.last.Get("model").String())
+	chunk, _ = sjson.SetRaw(chunk, "usage", usage)
+	return []byte(chunk)
+}
+
+func (m *fanOutStreamMerger) totalUsage() string {
+	total := ""
+	for _, usage := range m.usage {
+		if usage.IsObject() {
+			total = addUsage(total, usage)
+		}
+	}
+	return total
+}
+
+// addUsage adds every numeric field of usage to total, recursing into nested detail objects.
+// Fields that are not numbers keep the first value seen.
+func addUsage(total string, usage gjson.Result) string {
+	if total == "" {
+		return usage.Raw
+	}
+	usage.ForEach(func(key, value gjson.Result) bool {
+		path := key.String()
+		current := gjson.Get(total, path)
+		switch {
+		case value.Type == gjson.Number && current.Type == gjson.Number:
+			total, _ = sjson.Set(total, path, current.Int()+value.Int())
+		case value.IsObject() && current.IsObject():
+			total, _ = sjson.SetRaw(total, path, addUsage(current.Raw, value))
+		case !current.Exists():
+			total, _ = sjson.SetRaw(total, path, value.Raw)
+		}
+		return true
+	})
+	return total
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimSpace(value), target) {
+			return true
+		}
+	}
+	return false
+}
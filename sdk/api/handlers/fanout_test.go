@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+// singleChoiceExecutor answers every request with one choice naming the auth that served it.
+type singleChoiceExecutor struct {
+	mu       sync.Mutex
+	payloads [][]byte
+	authIDs  []string
+}
+
+func (e *singleChoiceExecutor) Identifier() string { return "codex" }
+
+func (e *singleChoiceExecutor) record(auth *coreauth.Auth, req coreexecutor.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.payloads = append(e.payloads, req.Payload)
+	e.authIDs = append(e.authIDs, auth.ID)
+}
+
+func (e *singleChoiceExecutor) Execute(_ context.Context, auth *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	e.record(auth, req)
+	resp := `{"id":"chatcmpl-` + auth.ID + `","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"` + auth.ID + `"},"finish_reason":"stop"}],"usage":{"prompt_tokens":4,"completion_tokens":2,"total_tokens":6,"completion_tokens_details":{"reasoning_tokens":1}}}`
+	return coreexecutor.Response{Payload: []byte(resp)}, nil
+}
+
+func (e *singleChoiceExecutor) ExecuteStream(_ context.Context, auth *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	e.record(auth, req)
+	ch := make(chan coreexecutor.StreamChunk, 2)
+	ch <- coreexecutor.StreamChunk{Payload: []byte(`{"candidates":[{"content":{"parts":[{"text":"hi"}]},"index":0}],"usageMetadata":{"promptTokenCount":4,"totalTokenCount":5}}`)}
+	ch <- coreexecutor.StreamChunk{Payload: []byte(`{"candidates":[{"content":{"parts":[{"text":"!"}]},"finishReason":"STOP","index":0}],"usageMetadata":{"promptTokenCount":4,"totalTokenCount":6}}`)}
+	close(ch)
+	return &coreexecutor.StreamResult{Chunks: ch}, nil
+}
+
+func (e *singleChoiceExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *singleChoiceExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *singleChoiceExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func newFanOutTestHandler(t *testing.T, cfg *sdkconfig.SDKConfig, authIDs ...string) (*BaseAPIHandler, *singleChoiceExecutor) {
+	t.Helper()
+	executor := &singleChoiceExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	for _, id := range authIDs {
+		auth := &coreauth.Auth{ID: id, Provider: executor.Identifier(), Status: coreauth.StatusActive}
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("manager.Register(%s): %v", id, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, auth.Provider, []*registry.ModelInfo{{ID: "fanout-model"}})
+	}
+	t.Cleanup(func() {
+		for _, id := range authIDs {
+			registry.GetGlobalRegistry().UnregisterClient(id)
+		}
+	})
+	return NewBaseAPIHandlers(cfg, manager), executor
+}
+
+func TestExecuteWithAuthManager_FansOutChoices(t *testing.T) {
+	handler, executor := newFanOutTestHandler(t, &sdkconfig.SDKConfig{
+		FanOut: sdkconfig.FanOutConfig{DistinctCredentials: true},
+	}, "fanout-a", "fanout-b", "fanout-c")
+
+	body := []byte(`{"model":"fanout-model","n":3,"messages":[{"role":"user","content":"hi"}]}`)
+	resp, _, errMsg := handler.ExecuteWithAuthManager(context.Background(), constant.OpenAI, "fanout-model", body, "")
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %+v", errMsg)
+	}
+
+	if len(executor.payloads) != 3 {
+		t.Fatalf("executions = %d, want 3", len(executor.payloads))
+	}
+	for _, payload := range executor.payloads {
+		if gjson.GetBytes(payload, "n").Exists() {
+			t.Fatalf("n forwarded to a single-choice branch: %s", payload)
+		}
+	}
+	served := map[string]bool{}
+	for _, id := range executor.authIDs {
+		served[id] = true
+	}
+	if len(served) != 3 {
+		t.Fatalf("branches served by %v, want three distinct credentials", executor.authIDs)
+	}
+
+	out := gjson.ParseBytes(resp)
+	choices := out.Get("choices").Array()
+	if len(choices) != 3 {
+		t.Fatalf("choices = %d, want 3: %s", len(choices), resp)
+	}
+	for i, choice := range choices {
+		if choice.Get("index").Int() != int64(i) {
+			t.Fatalf("choices.%d.index = %d", i, choice.Get("index").Int())
+		}
+	}
+	if got := out.Get("usage.total_tokens").Int(); got != 18 {
+		t.Fatalf("usage.total_tokens = %d, want 18", got)
+	}
+	if got := out.Get("usage.completion_tokens_details.reasoning_tokens").Int(); got != 3 {
+		t.Fatalf("usage.completion_tokens_details.reasoning_tokens = %d, want 3", got)
+	}
+}
+
+func TestExecuteStreamWithAuthManager_InterleavesGeminiCandidates(t *testing.T) {
+	handler, executor := newFanOutTestHandler(t, &sdkconfig.SDKConfig{}, "fanout-stream")
+
+	body := []byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]}],"generationConfig":{"candidateCount":2}}`)
+	dataChan, _, errChan := handler.ExecuteStreamWithAuthManager(context.Background(), constant.Gemini, "fanout-model", body, "")
+
+	perIndex := map[int64]int{}
+	var lastTotal int64
+	for chunk := range dataChan {
+		root := gjson.ParseBytes(chunk)
+		perIndex[root.Get("candidates.0.index").Int()]++
+		lastTotal = root.Get("usageMetadata.totalTokenCount").Int()
+	}
+	for msg := range errChan {
+		if msg != nil {
+			t.Fatalf("unexpected error: %+v", msg)
+		}
+	}
+
+	if len(executor.payloads) != 2 {
+		t.Fatalf("executions = %d, want 2", len(executor.payloads))
+	}
+	if gjson.GetBytes(executor.payloads[0], "generationConfig.candidateCount").Exists() {
+		t.Fatalf("candidateCount forwarded to a single-choice branch: %s", executor.payloads[0])
+	}
+	if perIndex[0] != 2 || perIndex[1] != 2 {
+		t.Fatalf("chunks per candidate index = %v, want two each for 0 and 1", perIndex)
+	}
+	if lastTotal != 12 {
+		t.Fatalf("final usageMetadata.totalTokenCount = %d, want 12", lastTotal)
+	}
+}
+
+func TestFanOutChoices(t *testing.T) {
+	body := []byte(`{"n":3}`)
+	if got, errMsg := fanOutChoices(nil, constant.OpenAI, "", []string{"openai-compat"}, body); errMsg != nil || got != 1 {
+		t.Fatalf("native multi-choice provider fanned out: %d, %+v", got, errMsg)
+	}
+	if got, errMsg := fanOutChoices(nil, constant.OpenAI, "", []string{constant.Claude}, body); errMsg != nil || got != 3 {
+		t.Fatalf("choices = %d, %+v; want 3", got, errMsg)
+	}
+
+	cfg := &sdkconfig.SDKConfig{FanOut: sdkconfig.FanOutConfig{MaxChoices: 2}}
+	_, errMsg := fanOutChoices(cfg, constant.OpenAI, "", []string{constant.Claude}, body)
+	if errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("choices above max-choices accepted: %+v", errMsg)
+	}
+}
+
+func TestFanOutStreamMergerHoldsOpenAIUsage(t *testing.T) {
+	merger := newFanOutStreamMerger(&fanOut{handlerType: constant.OpenAI, choices: 2})
+	for branch := range 2 {
+		id := "chatcmpl-" + strconv.Itoa(branch)
+		chunk := merger.rewrite(branch, []byte(`{"id":"`+id+`","created":7,"model":"m","choices":[{"index":0,"delta":{"content":"x"}}]}`))
+		if got := gjson.GetBytes(chunk, "choices.0.index").Int(); got != int64(branch) {
+			t.Fatalf("branch %d chunk index = %d", branch, got)
+		}
+		if got := gjson.GetBytes(chunk, "id").String(); got != "chatcmpl-0" {
+			t.Fatalf("branch %d chunk id = %q, want chatcmpl-0", branch, got)
+		}
+		if usage := merger.rewrite(branch, []byte(`{"id":"`+id+`","choices":[],"usage":{"total_tokens":5}}`)); usage != nil {
+			t.Fatalf("usage-only chunk forwarded: %s", usage)
+		}
+	}
+	final := gjson.ParseBytes(merger.finish())
+	if got := final.Get("usage.total_tokens").Int(); got != 10 {
+		t.Fatalf("final usage.total_tokens = %d, want 10", got)
+	}
+	if final.Get("created").Int() != 7 || final.Get("model").String() != "m" {
+		t.Fatalf("final chunk lost created/model: %s", final.Raw)
+	}
+}
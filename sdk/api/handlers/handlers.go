@@ -14,14 +14,18 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/context"
 )
 
@@ -47,9 +51,13 @@ type ErrorDetail struct {
 
 const idempotencyKeyMetadataKey = "idempotency_key"
 
+// ServedModelHeader is the response header carrying the model that served the request.
+const ServedModelHeader = "X-Served-Model"
+
 const (
-	defaultStreamingKeepAliveSeconds = 0
-	defaultStreamingBootstrapRetries = 0
+	defaultStreamingKeepAliveSeconds    = 0
+	defaultStreamingBootstrapRetries    = 0
+	defaultStreamingContinuationRetries = 0
 )
 
 type pinnedAuthContextKey struct{}
@@ -141,6 +149,56 @@ func BuildErrorResponseBody(status int, errText string) []byte {
 	return payload
 }
 
+// protocolErrorBody renders an error body in the error format of the inbound protocol so
+// errors raised by the proxy itself look like upstream errors to each client.
+func protocolErrorBody(handlerType string, status int, message string) []byte {
+	var payload any
+	switch handlerType {
+	case constant.Claude:
+		errType := "api_error"
+		switch status {
+		case http.StatusTooManyRequests:
+			errType = "rate_limit_error"
+		case http.StatusForbidden:
+			errType = "permission_error"
+		case http.StatusUnauthorized:
+			errType = "authentication_error"
+		case http.StatusNotFound:
+			errType = "not_found_error"
+		case http.StatusBadRequest:
+			errType = "invalid_request_error"
+		}
+		payload = map[string]any{
+			"type":  "error",
+			"error": map[string]any{"type": errType, "message": message},
+		}
+	case constant.Gemini, constant.GeminiCLI:
+		errStatus := "INTERNAL"
+		switch status {
+		case http.StatusTooManyRequests:
+			errStatus = "RESOURCE_EXHAUSTED"
+		case http.StatusForbidden:
+			errStatus = "PERMISSION_DENIED"
+		case http.StatusUnauthorized:
+			errStatus = "UNAUTHENTICATED"
+		case http.StatusNotFound:
+			errStatus = "NOT_FOUND"
+		case http.StatusBadRequest:
+			errStatus = "INVALID_ARGUMENT"
+		}
+		payload = map[string]any{
+			"error": map[string]any{"code": status, "message": message, "status": errStatus},
+		}
+	default:
+		return BuildErrorResponseBody(status, message)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return BuildErrorResponseBody(status, message)
+	}
+	return body
+}
+
 // StreamingKeepAliveInterval returns the SSE keep-alive interval for this server.
 // Returning 0 disables keep-alives (default when unset).
 func StreamingKeepAliveInterval(cfg *config.SDKConfig) time.Duration {
@@ -179,6 +237,19 @@ func StreamingBootstrapRetries(cfg *config.SDKConfig) int {
 	return retries
 }
 
+// StreamingContinuationRetries returns how many times a streaming request that fails after bytes
+// were sent may be continued on another credential.
+func StreamingContinuationRetries(cfg *config.SDKConfig) int {
+	retries := defaultStreamingContinuationRetries
+	if cfg != nil {
+		retries = cfg.Streaming.ContinuationRetries
+	}
+	if retries < 0 {
+		retries = 0
+	}
+	return retries
+}
+
 // PassthroughHeadersEnabled returns whether upstream response headers should be forwarded to clients.
 // Default is false.
 func PassthroughHeadersEnabled(cfg *config.SDKConfig) bool {
@@ -211,6 +282,44 @@ func requestExecutionMetadata(ctx context.Context) map[string]any {
 	return meta
 }
 
+// credentialHeaders carry the client's proxy credentials and never leave the proxy.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "X-Api-Key", "X-Goog-Api-Key", "X-Management-Key", "Cookie"}
+
+// requestHeadersFromContext returns a copy of the inbound client request headers, if any, without
+// the client's credentials. The conductor uses them for request-time routing decisions such as
+// session affinity.
+func requestHeadersFromContext(ctx context.Context) http.Header {
+	if ctx == nil {
+		return nil
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil || ginCtx.Request == nil {
+		return nil
+	}
+	headers := ginCtx.Request.Header.Clone()
+	for _, name := range credentialHeaders {
+		headers.Del(name)
+	}
+	return headers
+}
+
+// setServedModelHeader reports the model that actually served the request, which differs from
+// the requested model when the auth manager walked a model-fallbacks chain.
+func setServedModelHeader(ctx context.Context, requestedModel string, meta map[string]any) {
+	if ctx == nil {
+		return
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return
+	}
+	served := requestedModel
+	if model, okModel := meta[coreexecutor.ServedModelMetadataKey].(string); okModel && model != "" {
+		served = model
+	}
+	ginCtx.Header(ServedModelHeader, served)
+}
+
 func pinnedAuthIDFromContext(ctx context.Context) string {
 	if ctx == nil {
 		return ""
@@ -261,6 +370,9 @@ type BaseAPIHandler struct {
 
 	// Cfg holds the current application configuration.
 	Cfg *config.SDKConfig
+
+	// Pipeline, when set, runs its translation middleware for every request executed by these handlers.
+	Pipeline *sdktranslator.Pipeline
 }
 
 // NewBaseAPIHandlers creates a new API handlers instance.
@@ -338,6 +450,11 @@ func (h *BaseAPIHandler) GetContextWithCancel(handler interfaces.APIHandler, c *
 			parentCtx = logging.WithRequestID(parentCtx, requestID)
 		}
 	}
+	if requestCtx != nil && !trace.SpanContextFromContext(parentCtx).IsValid() {
+		if span := trace.SpanFromContext(requestCtx); span.SpanContext().IsValid() {
+			parentCtx = trace.ContextWithSpan(parentCtx, span)
+		}
+	}
 	newCtx, cancel := context.WithCancel(parentCtx)
 	if requestCtx != nil && requestCtx != parentCtx {
 		go func() {
@@ -350,6 +467,9 @@ func (h *BaseAPIHandler) GetContextWithCancel(handler interfaces.APIHandler, c *
 	}
 	newCtx = context.WithValue(newCtx, "gin", c)
 	newCtx = context.WithValue(newCtx, "handler", handler)
+	if sdktranslator.PipelineFromContext(newCtx) == nil {
+		newCtx = sdktranslator.WithPipeline(newCtx, h.Pipeline)
+	}
 	return newCtx, func(params ...interface{}) {
 		if h.Cfg.RequestLog && len(params) == 1 {
 			if existing, exists := c.Get("API_RESPONSE"); exists {
@@ -468,12 +588,27 @@ func appendAPIResponse(c *gin.Context, data []byte) {
 // ExecuteWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, http.Header, *interfaces.ErrorMessage) {
+	if errInvalid := validateClientRequest(handlerType, alt, rawJSON); errInvalid != nil {
+		return nil, nil, errInvalid
+	}
 	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
 	if errMsg != nil {
 		return nil, nil, errMsg
 	}
+	choices, errChoices := fanOutChoices(h.Cfg, handlerType, alt, providers, rawJSON)
+	if errChoices != nil {
+		return nil, nil, errChoices
+	}
 	reqMeta := requestExecutionMetadata(ctx)
 	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
+	if errAccess := checkClientAccess(ctx, handlerType, normalizedModel, reqMeta); errAccess != nil {
+		return nil, nil, errAccess
+	}
+	release, errLimit := h.acquireClientRateLimit(ctx, handlerType, false)
+	if errLimit != nil {
+		return nil, nil, errLimit
+	}
+	defer release()
 	payload := rawJSON
 	if len(payload) == 0 {
 		payload = nil
@@ -485,41 +620,73 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 	opts := coreexecutor.Options{
 		Stream:          false,
 		Alt:             alt,
+		Headers:         requestHeadersFromContext(ctx),
 		OriginalRequest: rawJSON,
 		SourceFormat:    sdktranslator.FromString(handlerType),
 	}
 	opts.Metadata = reqMeta
-	resp, err := h.AuthManager.Execute(ctx, providers, req, opts)
+	ctx, span := tracing.Start(ctx, "cliproxy.handler.execute", handlerSpanAttributes(handlerType, normalizedModel, false)...)
+	defer span.End()
+	resp, err := h.executeRequest(ctx, handlerType, choices, providers, req, opts)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
-			if code := se.StatusCode(); code > 0 {
-				status = code
-			}
-		}
-		var addon http.Header
-		if he, ok := err.(interface{ Headers() http.Header }); ok && he != nil {
-			if hdr := he.Headers(); hdr != nil {
-				addon = hdr.Clone()
-			}
-		}
-		return nil, nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+		tracing.RecordError(span, err)
+		return nil, nil, executionErrorMessage(err)
 	}
+	setServedModelHeader(ctx, normalizedModel, reqMeta)
 	if !PassthroughHeadersEnabled(h.Cfg) {
 		return resp.Payload, nil, nil
 	}
 	return resp.Payload, FilterUpstreamHeaders(resp.Headers), nil
 }
 
+// executeRequest runs one non-streaming request through the auth manager, fanning it out when
+// the providers cannot return the requested number of choices themselves.
+func (h *BaseAPIHandler) executeRequest(ctx context.Context, handlerType string, choices int, providers []string, req coreexecutor.Request, opts coreexecutor.Options) (coreexecutor.Response, error) {
+	if choices > 1 {
+		fan := h.newFanOut(handlerType, choices, providers, req.Model, opts.Metadata)
+		return h.executeFanOut(ctx, fan, providers, req, opts)
+	}
+	return h.AuthManager.Execute(ctx, providers, req, opts)
+}
+
+// executionErrorMessage converts an auth manager error into the error returned to the client,
+// keeping the upstream status code and headers when the error carries them.
+func executionErrorMessage(err error) *interfaces.ErrorMessage {
+	status := http.StatusInternalServerError
+	if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
+		if code := se.StatusCode(); code > 0 {
+			status = code
+		}
+	}
+	var addon http.Header
+	if he, ok := err.(interface{ Headers() http.Header }); ok && he != nil {
+		if hdr := he.Headers(); hdr != nil {
+			addon = hdr.Clone()
+		}
+	}
+	return &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+}
+
 // ExecuteCountWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, http.Header, *interfaces.ErrorMessage) {
+	if errInvalid := validateClientRequest(handlerType, alt, rawJSON); errInvalid != nil {
+		return nil, nil, errInvalid
+	}
 	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
 	if errMsg != nil {
 		return nil, nil, errMsg
 	}
 	reqMeta := requestExecutionMetadata(ctx)
 	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
+	if errAccess := checkClientAccess(ctx, handlerType, normalizedModel, reqMeta); errAccess != nil {
+		return nil, nil, errAccess
+	}
+	release, errLimit := h.acquireClientRateLimit(ctx, handlerType, false)
+	if errLimit != nil {
+		return nil, nil, errLimit
+	}
+	defer release()
 	payload := rawJSON
 	if len(payload) == 0 {
 		payload = nil
@@ -531,12 +698,16 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 	opts := coreexecutor.Options{
 		Stream:          false,
 		Alt:             alt,
+		Headers:         requestHeadersFromContext(ctx),
 		OriginalRequest: rawJSON,
 		SourceFormat:    sdktranslator.FromString(handlerType),
 	}
 	opts.Metadata = reqMeta
+	ctx, span := tracing.Start(ctx, "cliproxy.handler.count", handlerSpanAttributes(handlerType, normalizedModel, false)...)
+	defer span.End()
 	resp, err := h.AuthManager.ExecuteCount(ctx, providers, req, opts)
 	if err != nil {
+		tracing.RecordError(span, err)
 		status := http.StatusInternalServerError
 		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
 			if code := se.StatusCode(); code > 0 {
@@ -561,6 +732,12 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 // This path is the only supported execution route.
 // The returned http.Header carries upstream response headers captured before streaming begins.
 func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, http.Header, <-chan *interfaces.ErrorMessage) {
+	if errInvalid := validateClientRequest(handlerType, alt, rawJSON); errInvalid != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errInvalid
+		close(errChan)
+		return nil, nil, errChan
+	}
 	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
 	if errMsg != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
@@ -568,8 +745,28 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 		close(errChan)
 		return nil, nil, errChan
 	}
+	choices, errChoices := fanOutChoices(h.Cfg, handlerType, alt, providers, rawJSON)
+	if errChoices != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errChoices
+		close(errChan)
+		return nil, nil, errChan
+	}
 	reqMeta := requestExecutionMetadata(ctx)
 	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
+	if errAccess := checkClientAccess(ctx, handlerType, normalizedModel, reqMeta); errAccess != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errAccess
+		close(errChan)
+		return nil, nil, errChan
+	}
+	release, errLimit := h.acquireClientRateLimit(ctx, handlerType, true)
+	if errLimit != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errLimit
+		close(errChan)
+		return nil, nil, errChan
+	}
 	payload := rawJSON
 	if len(payload) == 0 {
 		payload = nil
@@ -581,12 +778,25 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	opts := coreexecutor.Options{
 		Stream:          true,
 		Alt:             alt,
+		Headers:         requestHeadersFromContext(ctx),
 		OriginalRequest: rawJSON,
 		SourceFormat:    sdktranslator.FromString(handlerType),
 	}
 	opts.Metadata = reqMeta
-	streamResult, err := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
+	// The stream span stays open until the last chunk has been forwarded to the client.
+	ctx, span := tracing.Start(ctx, "cliproxy.handler.stream", handlerSpanAttributes(handlerType, normalizedModel, true)...)
+	// Bootstrap retries reopen the stream the same way, fanning out again when needed.
+	executeStream := func() (*coreexecutor.StreamResult, error) {
+		if choices > 1 {
+			fan := h.newFanOut(handlerType, choices, providers, normalizedModel, reqMeta)
+			return h.executeStreamFanOut(ctx, fan, providers, req, opts)
+		}
+		return h.AuthManager.ExecuteStream(ctx, providers, req, opts)
+	}
+	streamResult, err := executeStream()
 	if err != nil {
+		release()
+		tracing.End(span, err)
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		status := http.StatusInternalServerError
 		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
@@ -604,6 +814,7 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 		close(errChan)
 		return nil, nil, errChan
 	}
+	setServedModelHeader(ctx, normalizedModel, reqMeta)
 	passthroughHeadersEnabled := PassthroughHeadersEnabled(h.Cfg)
 	// Capture upstream headers from the initial connection synchronously before the goroutine starts.
 	// Keep a mutable map so bootstrap retries can replace it before first payload is sent.
@@ -618,11 +829,24 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	dataChan := make(chan []byte)
 	errChan := make(chan *interfaces.ErrorMessage, 1)
 	go func() {
+		defer release()
 		defer close(dataChan)
 		defer close(errChan)
+		defer span.End()
 		sentPayload := false
 		bootstrapRetries := 0
 		maxBootstrapRetries := StreamingBootstrapRetries(h.Cfg)
+		// Continuation recovery: a stream that fails after bytes were sent is re-issued on another
+		// credential with the emitted text handed back, and the continued output is stitched in.
+		var continuation streamContinuation
+		if choices <= 1 {
+			continuation = newStreamContinuation(handlerType, alt)
+		}
+		continuationRetries := 0
+		maxContinuationRetries := StreamingContinuationRetries(h.Cfg)
+		continuationMeta := reqMeta
+		var excludedAuths []string
+		stitching := false
 
 		sendErr := func(msg *interfaces.ErrorMessage) bool {
 			if ctx == nil {
@@ -688,7 +912,7 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 					if !sentPayload {
 						if bootstrapRetries < maxBootstrapRetries && bootstrapEligible(streamErr) {
 							bootstrapRetries++
-							retryResult, retryErr := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
+							retryResult, retryErr := executeStream()
 							if retryErr == nil {
 								if passthroughHeadersEnabled {
 									replaceHeader(upstreamHeaders, FilterUpstreamHeaders(retryResult.Headers))
@@ -698,6 +922,27 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 							}
 							streamErr = retryErr
 						}
+					} else if continuation != nil && continuationRetries < maxContinuationRetries && bootstrapEligible(streamErr) {
+						if contPayload, ok := continuation.request(rawJSON, continuationPrefill(providers, normalizedModel)); ok {
+							continuationRetries++
+							if failed, _ := continuationMeta[coreexecutor.SelectedAuthMetadataKey].(string); failed != "" {
+								excludedAuths = append(excludedAuths, failed)
+							}
+							continuationMeta = continuationMetadata(reqMeta, excludedAuths)
+							contReq := req
+							contReq.Payload = contPayload
+							contOpts := opts
+							contOpts.OriginalRequest = contPayload
+							contOpts.Metadata = continuationMeta
+							contResult, contErr := h.AuthManager.ExecuteStream(ctx, providers, contReq, contOpts)
+							if contErr == nil {
+								span.AddEvent("cliproxy.stream.continued")
+								continuation.begin()
+								stitching = true
+								chunks = contResult.Chunks
+								continue outer
+							}
+						}
 					}
 
 					status := http.StatusInternalServerError
@@ -712,12 +957,20 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 							addon = hdr.Clone()
 						}
 					}
+					tracing.RecordError(span, streamErr)
 					_ = sendErr(&interfaces.ErrorMessage{StatusCode: status, Error: streamErr, Addon: addon})
 					return
 				}
-				if len(chunk.Payload) > 0 {
+				payload := chunk.Payload
+				if stitching {
+					payload = continuation.stitch(payload)
+				}
+				if len(payload) > 0 {
 					sentPayload = true
-					if okSendData := sendData(cloneBytes(chunk.Payload)); !okSendData {
+					if continuation != nil {
+						continuation.observe(payload)
+					}
+					if okSendData := sendData(cloneBytes(payload)); !okSendData {
 						return
 					}
 				}
@@ -727,6 +980,28 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	return dataChan, upstreamHeaders, errChan
 }
 
+// continuationMetadata returns the execution metadata for a continued stream: the original
+// request's, minus its idempotency key, with the credentials that already failed excluded.
+func continuationMetadata(reqMeta map[string]any, excluded []string) map[string]any {
+	meta := make(map[string]any, len(reqMeta)+1)
+	for key, value := range reqMeta {
+		if key == idempotencyKeyMetadataKey || key == coreexecutor.SelectedAuthMetadataKey {
+			continue
+		}
+		meta[key] = value
+	}
+	meta[coreexecutor.ExcludedAuthsMetadataKey] = append([]string(nil), excluded...)
+	return meta
+}
+
+func handlerSpanAttributes(handlerType, model string, stream bool) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("cliproxy.handler", handlerType),
+		attribute.String("cliproxy.model", model),
+		attribute.Bool("cliproxy.stream", stream),
+	}
+}
+
 func statusFromError(err error) int {
 	if err == nil {
 		return 0
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/gin-gonic/gin"
 )
 
 func TestFilterUpstreamHeaders_RemovesConnectionScopedHeaders(t *testing.T) {
@@ -53,3 +57,27 @@ func TestFilterUpstreamHeaders_ReturnsNilWhenAllHeadersBlocked(t *testing.T) {
 		t.Fatalf("expected nil when all headers are filtered, got %#v", filtered)
 	}
 }
+
+func TestRequestHeadersFromContext_DropsClientCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	ginCtx.Request.Header.Set("Authorization", "Bearer client-key")
+	ginCtx.Request.Header.Set("X-Api-Key", "client-key")
+	ginCtx.Request.Header.Set("X-Goog-Api-Key", "client-key")
+	ginCtx.Request.Header.Set("X-Session-ID", "conv-1")
+	ctx := context.WithValue(context.Background(), "gin", ginCtx)
+
+	headers := requestHeadersFromContext(ctx)
+	for _, name := range []string{"Authorization", "X-Api-Key", "X-Goog-Api-Key"} {
+		if got := headers.Get(name); got != "" {
+			t.Fatalf("%s = %q, want the client credential dropped", name, got)
+		}
+	}
+	if got := headers.Get("X-Session-ID"); got != "conv-1" {
+		t.Fatalf("X-Session-ID = %q, want it kept for session affinity", got)
+	}
+	if ginCtx.Request.Header.Get("Authorization") == "" {
+		t.Fatal("inbound request headers were modified")
+	}
+}
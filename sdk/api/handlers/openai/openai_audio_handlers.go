@@ -0,0 +1,353 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// maxSpeechInputLength matches the OpenAI speech API limit on input.
+const maxSpeechInputLength = 4096
+
+// transcriptionInstruction is the system prompt that turns a multimodal chat model into a
+// transcriber.
+const transcriptionInstruction = "Transcribe the audio verbatim. Reply with the transcript only, " +
+	"without commentary, timestamps or speaker labels."
+
+// speechContentTypes are the Content-Type headers of the speech response formats.
+var speechContentTypes = map[string]string{
+	"mp3":  "audio/mpeg",
+	"opus": "audio/opus",
+	"aac":  "audio/aac",
+	"flac": "audio/flac",
+	"wav":  "audio/wav",
+	"pcm":  "audio/pcm",
+}
+
+// transcriptionRequest is a parsed /v1/audio/transcriptions form.
+type transcriptionRequest struct {
+	model          string
+	filename       string
+	contentType    string
+	audio          []byte
+	language       string
+	prompt         string
+	responseFormat string
+	// fields keeps every form value other than file for passthrough.
+	fields [][2]string
+}
+
+// AudioTranscriptions handles the /v1/audio/transcriptions endpoint.
+// Models served only by openai-compatibility providers get the upload forwarded to the provider's
+// own transcription endpoint. Any other model transcribes through a Chat Completions request with
+// the audio attached as an input_audio part, which the Gemini translators turn into inline audio.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIAPIHandler) AudioTranscriptions(c *gin.Context) {
+	req, err := parseTranscriptionForm(c)
+	if err != nil {
+		writeAudioBadRequest(c, err)
+		return
+	}
+
+	if servedByOpenAICompatibility(req.model) {
+		contentType := "text/plain; charset=utf-8"
+		if req.responseFormat == "" || req.responseFormat == "json" || req.responseFormat == "verbose_json" {
+			contentType = "application/json"
+		}
+		h.forwardAudioRequest(c, req.model, "audio/transcriptions", transcriptionPassthroughPayload(req), contentType)
+		return
+	}
+
+	switch req.responseFormat {
+	case "", "json", "text":
+	default:
+		writeAudioBadRequest(c, fmt.Errorf("response_format %q is only supported by OpenAI-compatible providers", req.responseFormat))
+		return
+	}
+
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	resp, upstreamHeaders, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), req.model, convertTranscriptionToChatCompletions(req), "")
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	root := gjson.ParseBytes(resp)
+	text := strings.TrimSpace(root.Get("choices.0.message.content").String())
+
+	handlers.WriteUpstreamHeaders(c.Writer.Header(), upstreamHeaders)
+	if req.responseFormat == "text" {
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(text))
+		cliCancel()
+		return
+	}
+	out := `{"text":""}`
+	out, _ = sjson.Set(out, "text", text)
+	if usage := root.Get("usage"); usage.Exists() {
+		out, _ = sjson.Set(out, "usage.type", "tokens")
+		out, _ = sjson.Set(out, "usage.input_tokens", usage.Get("prompt_tokens").Int())
+		out, _ = sjson.Set(out, "usage.output_tokens", usage.Get("completion_tokens").Int())
+		out, _ = sjson.Set(out, "usage.total_tokens", usage.Get("total_tokens").Int())
+	}
+	c.Data(http.StatusOK, "application/json", []byte(out))
+	cliCancel()
+}
+
+// AudioSpeech handles the /v1/audio/speech endpoint.
+// Models served only by openai-compatibility providers get the request forwarded unchanged. Any
+// other model speaks through a Chat Completions request with audio output, which Gemini speech
+// models answer with raw PCM; the proxy can wrap that as wav or return it as pcm but cannot
+// encode compressed formats, so the default response format is wav instead of mp3.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIAPIHandler) AudioSpeech(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		writeAudioBadRequest(c, err)
+		return
+	}
+	if !gjson.ValidBytes(rawJSON) {
+		writeAudioBadRequest(c, errors.New("body must be a JSON object"))
+		return
+	}
+	root := gjson.ParseBytes(rawJSON)
+	modelName := root.Get("model").String()
+	input := root.Get("input").String()
+	responseFormat := root.Get("response_format").String()
+	switch {
+	case modelName == "":
+		writeAudioBadRequest(c, errors.New("model is required"))
+		return
+	case strings.TrimSpace(input) == "":
+		writeAudioBadRequest(c, errors.New("input is required"))
+		return
+	case len([]rune(input)) > maxSpeechInputLength:
+		writeAudioBadRequest(c, fmt.Errorf("input must be at most %d characters", maxSpeechInputLength))
+		return
+	case root.Get("stream_format").String() == "sse":
+		writeAudioBadRequest(c, errors.New("streaming speech is not supported"))
+		return
+	}
+
+	if servedByOpenAICompatibility(modelName) {
+		format := responseFormat
+		if format == "" {
+			format = "mp3"
+		}
+		contentType, ok := speechContentTypes[format]
+		if !ok {
+			contentType = "application/octet-stream"
+		}
+		h.forwardAudioRequest(c, modelName, "audio/speech", rawJSON, contentType)
+		return
+	}
+
+	chatFormat := "wav"
+	switch responseFormat {
+	case "", "wav":
+		responseFormat = "wav"
+	case "pcm":
+		chatFormat = "pcm16"
+	default:
+		writeAudioBadRequest(c, fmt.Errorf("response_format %q is only supported by OpenAI-compatible providers, use wav or pcm", responseFormat))
+		return
+	}
+
+	chat := `{"model":"","modalities":["audio"],"audio":{"voice":"","format":""},"messages":[{"role":"user","content":""}]}`
+	chat, _ = sjson.Set(chat, "model", modelName)
+	voice := root.Get("voice").String()
+	if voice == "" {
+		voice = "alloy"
+	}
+	chat, _ = sjson.Set(chat, "audio.voice", voice)
+	chat, _ = sjson.Set(chat, "audio.format", chatFormat)
+	// Gemini speech models take style directions as a prefix of the text to read.
+	if instructions := strings.TrimSpace(root.Get("instructions").String()); instructions != "" {
+		input = instructions + ": " + input
+	}
+	chat, _ = sjson.Set(chat, "messages.0.content", input)
+
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	resp, upstreamHeaders, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), modelName, []byte(chat), "")
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	audio, err := base64.StdEncoding.DecodeString(gjson.GetBytes(resp, "choices.0.message.audio.data").String())
+	if err != nil || len(audio) == 0 {
+		err = errors.New("the model returned no audio")
+		h.WriteErrorResponse(c, &interfaces.ErrorMessage{StatusCode: http.StatusBadGateway, Error: err})
+		cliCancel(err)
+		return
+	}
+	handlers.WriteUpstreamHeaders(c.Writer.Header(), upstreamHeaders)
+	c.Data(http.StatusOK, speechContentTypes[responseFormat], audio)
+	cliCancel()
+}
+
+func writeAudioBadRequest(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+		Error: handlers.ErrorDetail{
+			Message: fmt.Sprintf("Invalid request: %v", err),
+			Type:    "invalid_request_error",
+		},
+	})
+}
+
+// servedByOpenAICompatibility reports whether every provider registered for the model is an
+// openai-compatibility provider, whose own audio endpoints can take the request unchanged.
+func servedByOpenAICompatibility(modelName string) bool {
+	baseModel := thinking.ParseSuffix(util.ResolveAutoModel(modelName)).ModelName
+	providers := util.GetProviderName(baseModel)
+	if len(providers) == 0 {
+		return false
+	}
+	for _, provider := range providers {
+		info := registry.GetGlobalRegistry().GetModelInfo(baseModel, provider)
+		if info == nil || info.Type != "openai-compatibility" {
+			return false
+		}
+	}
+	return true
+}
+
+// forwardAudioRequest sends an audio request to the provider's endpoint named by alt and writes
+// the provider's answer back as-is.
+func (h *OpenAIAPIHandler) forwardAudioRequest(c *gin.Context, modelName, alt string, payload []byte, contentType string) {
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	resp, upstreamHeaders, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), modelName, payload, alt)
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	c.Header("Content-Type", contentType)
+	handlers.WriteUpstreamHeaders(c.Writer.Header(), upstreamHeaders)
+	_, _ = c.Writer.Write(resp)
+	cliCancel()
+}
+
+// parseTranscriptionForm reads the multipart form of a transcription request.
+func parseTranscriptionForm(c *gin.Context) (transcriptionRequest, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return transcriptionRequest{}, err
+	}
+	var req transcriptionRequest
+	for name, values := range form.Value {
+		for _, value := range values {
+			req.fields = append(req.fields, [2]string{name, value})
+		}
+		if len(values) == 0 {
+			continue
+		}
+		switch name {
+		case "model":
+			req.model = values[0]
+		case "language":
+			req.language = values[0]
+		case "prompt":
+			req.prompt = values[0]
+		case "response_format":
+			req.responseFormat = values[0]
+		case "stream":
+			if values[0] == "true" {
+				return req, errors.New("streaming transcription is not supported")
+			}
+		}
+	}
+	files := form.File["file"]
+	switch {
+	case req.model == "":
+		return req, errors.New("model is required")
+	case len(files) == 0:
+		return req, errors.New("file is required")
+	}
+	req.filename = files[0].Filename
+	req.contentType = files[0].Header.Get("Content-Type")
+	if req.audio, err = readFormFile(files[0]); err != nil {
+		return req, err
+	}
+	return req, nil
+}
+
+func readFormFile(file *multipart.FileHeader) ([]byte, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", file.Filename, err)
+	}
+	defer func() { _ = f.Close() }()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", file.Filename, err)
+	}
+	return data, nil
+}
+
+// transcriptionPassthroughPayload carries the upload through the auth manager as JSON; the
+// OpenAI-compatible executor rebuilds the multipart form from it.
+func transcriptionPassthroughPayload(req transcriptionRequest) []byte {
+	out := `{"model":"","file":{"filename":"","content_type":"","data":""},"fields":[]}`
+	out, _ = sjson.Set(out, "model", req.model)
+	out, _ = sjson.Set(out, "file.filename", req.filename)
+	out, _ = sjson.Set(out, "file.content_type", req.contentType)
+	out, _ = sjson.Set(out, "file.data", base64.StdEncoding.EncodeToString(req.audio))
+	for _, field := range req.fields {
+		entry := `{"name":"","value":""}`
+		entry, _ = sjson.Set(entry, "name", field[0])
+		entry, _ = sjson.Set(entry, "value", field[1])
+		out, _ = sjson.SetRaw(out, "fields.-1", entry)
+	}
+	return []byte(out)
+}
+
+// convertTranscriptionToChatCompletions builds the Chat Completions request that transcribes
+// the upload. The audio format comes from the file extension, or from the part's content type
+// when the file has none.
+func convertTranscriptionToChatCompletions(req transcriptionRequest) []byte {
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(req.filename), "."))
+	if format == "" {
+		format = misc.AudioFormat(req.contentType)
+	}
+	out := `{"model":"","messages":[{"role":"system","content":""},{"role":"user","content":[]}]}`
+	out, _ = sjson.Set(out, "model", req.model)
+	out, _ = sjson.Set(out, "messages.0.content", transcriptionInstruction)
+	audio := `{"type":"input_audio","input_audio":{"data":"","format":""}}`
+	audio, _ = sjson.Set(audio, "input_audio.data", base64.StdEncoding.EncodeToString(req.audio))
+	audio, _ = sjson.Set(audio, "input_audio.format", format)
+	out, _ = sjson.SetRaw(out, "messages.1.content.-1", audio)
+
+	var hints []string
+	if req.language != "" {
+		hints = append(hints, "The audio is in the language with ISO-639-1 code "+req.language+".")
+	}
+	if req.prompt != "" {
+		hints = append(hints, "Context and spelling hints: "+req.prompt)
+	}
+	if len(hints) > 0 {
+		text := `{"type":"text","text":""}`
+		text, _ = sjson.Set(text, "text", strings.Join(hints, "\n"))
+		out, _ = sjson.SetRaw(out, "messages.1.content.-1", text)
+	}
+	return []byte(out)
+}
@@ -0,0 +1,145 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+// audioChatExecutor answers chat requests with a transcript, plus audio when audio output is asked for.
+type audioChatExecutor struct {
+	payloads [][]byte
+}
+
+func (e *audioChatExecutor) Identifier() string { return "audio-provider" }
+
+func (e *audioChatExecutor) Execute(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	e.payloads = append(e.payloads, req.Payload)
+	resp := `{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":" hello world \n"},"finish_reason":"stop"}],"usage":{"prompt_tokens":30,"completion_tokens":2,"total_tokens":32}}`
+	if gjson.GetBytes(req.Payload, "audio").Exists() {
+		resp = `{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":null,"audio":{"id":"audio_1","data":"UklGRg==","expires_at":0,"transcript":""}},"finish_reason":"stop"}]}`
+	}
+	return coreexecutor.Response{Payload: []byte(resp)}, nil
+}
+
+func (e *audioChatExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (e *audioChatExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *audioChatExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, errors.New("not implemented")
+}
+
+func (e *audioChatExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func newAudioTestRouter(t *testing.T) (*gin.Engine, *audioChatExecutor) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	executor := &audioChatExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+
+	auth := &coreauth.Auth{ID: "audio-auth", Provider: executor.Identifier(), Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("Register auth: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "audio-model"}})
+	t.Cleanup(func() {
+		registry.GetGlobalRegistry().UnregisterClient(auth.ID)
+	})
+
+	h := NewOpenAIAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, manager))
+	router := gin.New()
+	router.POST("/v1/audio/transcriptions", h.AudioTranscriptions)
+	router.POST("/v1/audio/speech", h.AudioSpeech)
+	return router, executor
+}
+
+func TestAudioTranscriptions(t *testing.T) {
+	router, executor := newAudioTestRouter(t)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	_ = form.WriteField("model", "audio-model")
+	_ = form.WriteField("language", "en")
+	file, _ := form.CreateFormFile("file", "memo.m4a")
+	_, _ = file.Write([]byte("audio"))
+	_ = form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/audio/transcriptions", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", resp.Code, resp.Body.String())
+	}
+	chat := gjson.ParseBytes(executor.payloads[0])
+	audio := chat.Get("messages.1.content.0.input_audio")
+	if audio.Get("format").String() != "m4a" || audio.Get("data").String() != "YXVkaW8=" {
+		t.Fatalf("input_audio = %s", audio.Raw)
+	}
+	if hint := chat.Get("messages.1.content.1.text").String(); !strings.Contains(hint, "en") {
+		t.Fatalf("language hint = %q", hint)
+	}
+	out := gjson.Parse(resp.Body.String())
+	if got := out.Get("text").String(); got != "hello world" {
+		t.Fatalf("text = %q", got)
+	}
+	if out.Get("usage.type").String() != "tokens" || out.Get("usage.input_tokens").Int() != 30 {
+		t.Fatalf("usage = %s", out.Get("usage").Raw)
+	}
+}
+
+func TestAudioSpeech(t *testing.T) {
+	router, executor := newAudioTestRouter(t)
+
+	body := `{"model":"audio-model","input":"Good morning","voice":"nova","instructions":"Say cheerfully"}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/audio/speech", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", resp.Code, resp.Body.String())
+	}
+	chat := gjson.ParseBytes(executor.payloads[0])
+	if chat.Get("modalities.0").String() != "audio" || chat.Get("audio.voice").String() != "nova" || chat.Get("audio.format").String() != "wav" {
+		t.Fatalf("chat request = %s", chat.Raw)
+	}
+	if got := chat.Get("messages.0.content").String(); got != "Say cheerfully: Good morning" {
+		t.Fatalf("chat content = %q", got)
+	}
+	if got := resp.Header().Get("Content-Type"); got != "audio/wav" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+	if got := resp.Body.String(); got != "RIFF" {
+		t.Fatalf("body = %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/audio/speech", strings.NewReader(`{"model":"audio-model","input":"hi","response_format":"mp3"}`))
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("mp3 from a chat model: status = %d", resp.Code)
+	}
+}
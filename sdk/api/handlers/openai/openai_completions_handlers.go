@@ -0,0 +1,494 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Instructions that turn a chat model into a text completion engine. Every backend is reached
+// through Chat Completions, so the legacy prompt and suffix are rewritten as a conversation.
+// Models listed in completions.raw-prompt-models get plain prompts without the instruction.
+const (
+	completionInstruction = "You are a text completion engine. Continue the user's text from exactly where it stops. " +
+		"Reply with the continuation only: do not repeat the text, add commentary or wrap it in code fences."
+	insertionInstruction = "You are a text completion engine. The user sends the text before and after a gap, " +
+		"delimited by <prefix> and <suffix> tags. Reply with only the text that fills the gap, so that prefix, " +
+		"reply and suffix read as one document. Do not repeat the prefix or suffix, add commentary or use the tags."
+	emptyPromptFallback = "Complete this:"
+)
+
+// completionsRequest holds the parts of a legacy completions request that Chat Completions cannot
+// express and that are restored when the response is converted back.
+type completionsRequest struct {
+	root      gjson.Result
+	prompts   []string
+	suffix    string
+	echo      bool
+	n         int
+	rawPrompt bool
+}
+
+// Completions handles the /v1/completions endpoint.
+// The legacy request is converted into one Chat Completions request per prompt, so every backend
+// that serves chat can answer it, and the chat responses are converted back into text completions.
+// Several prompts are sent in parallel.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIAPIHandler) Completions(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	// If data retrieval fails, return a 400 Bad Request error.
+	if err != nil {
+		c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+			Error: handlers.ErrorDetail{
+				Message: fmt.Sprintf("Invalid request: %v", err),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+
+	req, errParse := parseCompletionsRequest(rawJSON)
+	if errParse == nil && req.root.Get("stream").Bool() && len(req.prompts) > 1 {
+		errParse = errors.New("streaming is not supported with more than one prompt")
+	}
+	if errParse != nil {
+		c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+			Error: handlers.ErrorDetail{
+				Message: fmt.Sprintf("Invalid request: %v", errParse),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+
+	if h.Cfg != nil {
+		req.rawPrompt = handlers.ModelMatchesPatterns(h.Cfg.Completions.RawPromptModels, req.root.Get("model").String())
+	}
+
+	if req.root.Get("stream").Bool() {
+		h.handleCompletionsStreamingResponse(c, req)
+	} else {
+		h.handleCompletionsNonStreamingResponse(c, req)
+	}
+}
+
+// parseCompletionsRequest validates the prompt of a completions request. A prompt may be a string
+// or a list of strings; token-array prompts need the model's tokenizer and are rejected.
+func parseCompletionsRequest(rawJSON []byte) (completionsRequest, error) {
+	root := gjson.ParseBytes(rawJSON)
+	req := completionsRequest{
+		root:   root,
+		suffix: root.Get("suffix").String(),
+		echo:   root.Get("echo").Bool(),
+		n:      1,
+	}
+	if n := root.Get("n").Int(); n > 1 {
+		req.n = int(n)
+	}
+
+	prompt := root.Get("prompt")
+	switch {
+	case !prompt.Exists() || prompt.Type == gjson.Null:
+		req.prompts = []string{""}
+	case prompt.Type == gjson.String:
+		req.prompts = []string{prompt.String()}
+	case prompt.IsArray():
+		for _, item := range prompt.Array() {
+			if item.Type != gjson.String {
+				return req, errors.New("token prompts are not supported, send the prompt as text")
+			}
+			req.prompts = append(req.prompts, item.String())
+		}
+		if len(req.prompts) == 0 {
+			return req, errors.New("prompt must not be empty")
+		}
+	default:
+		return req, errors.New("prompt must be a string or an array of strings")
+	}
+	return req, nil
+}
+
+// convertCompletionsRequestToChatCompletions builds the Chat Completions request for one prompt.
+// The legacy logprobs count becomes logprobs plus top_logprobs; echo and suffix are handled by
+// the proxy and are not forwarded. Raw-prompt requests without a suffix send the prompt alone.
+//
+// Parameters:
+//   - req: The parsed completions request
+//   - prompt: The prompt to complete
+//
+// Returns:
+//   - []byte: The converted chat completions request
+func convertCompletionsRequestToChatCompletions(req completionsRequest, prompt string) []byte {
+	root := req.root
+
+	out := `{"model":"","messages":[{"role":"system","content":""},{"role":"user","content":""}]}`
+	out, _ = sjson.Set(out, "model", root.Get("model").String())
+	if req.suffix != "" {
+		out, _ = sjson.Set(out, "messages.0.content", insertionInstruction)
+		out, _ = sjson.Set(out, "messages.1.content", "<prefix>"+prompt+"</prefix><suffix>"+req.suffix+"</suffix>")
+	} else {
+		if prompt == "" {
+			prompt = emptyPromptFallback
+		}
+		out, _ = sjson.Set(out, "messages.0.content", completionInstruction)
+		out, _ = sjson.Set(out, "messages.1.content", prompt)
+		if req.rawPrompt {
+			out, _ = sjson.Delete(out, "messages.0")
+		}
+	}
+
+	// Copy sampling parameters that Chat Completions shares with completions
+	if maxTokens := root.Get("max_tokens"); maxTokens.Exists() {
+		out, _ = sjson.Set(out, "max_tokens", maxTokens.Int())
+	}
+	for _, key := range []string{"temperature", "top_p", "frequency_penalty", "presence_penalty"} {
+		if value := root.Get(key); value.Exists() {
+			out, _ = sjson.Set(out, key, value.Float())
+		}
+	}
+	for _, key := range []string{"stop", "seed", "user", "logit_bias", "stream_options"} {
+		if value := root.Get(key); value.Exists() {
+			out, _ = sjson.SetRaw(out, key, value.Raw)
+		}
+	}
+	if stream := root.Get("stream"); stream.Exists() {
+		out, _ = sjson.Set(out, "stream", stream.Bool())
+	}
+	if req.n > 1 {
+		out, _ = sjson.Set(out, "n", req.n)
+	}
+
+	// logprobs is the number of alternatives per token in the legacy API
+	if logprobs := root.Get("logprobs"); logprobs.Exists() && logprobs.Type == gjson.Number {
+		out, _ = sjson.Set(out, "logprobs", true)
+		if top := logprobs.Int(); top > 0 {
+			out, _ = sjson.Set(out, "top_logprobs", top)
+		}
+	}
+
+	return []byte(out)
+}
+
+// convertChatCompletionsResponseToCompletions merges the chat responses for every prompt of req
+// into one completions response. Choices are numbered prompt by prompt, as the legacy API does
+// for batched prompts, and token usage is summed.
+//
+// Parameters:
+//   - req: The parsed completions request
+//   - responses: One chat completions response per prompt, in prompt order
+//
+// Returns:
+//   - []byte: The converted completions response
+func convertChatCompletionsResponseToCompletions(req completionsRequest, responses [][]byte) []byte {
+	out := `{"id":"","object":"text_completion","created":0,"model":"","choices":[]}`
+
+	var usage struct{ prompt, completion, total int64 }
+	hasUsage := false
+	for promptIndex, rawJSON := range responses {
+		root := gjson.ParseBytes(rawJSON)
+		if promptIndex == 0 {
+			out, _ = sjson.Set(out, "id", root.Get("id").String())
+			out, _ = sjson.Set(out, "created", root.Get("created").Int())
+			out, _ = sjson.Set(out, "model", root.Get("model").String())
+		}
+		if u := root.Get("usage"); u.Exists() {
+			hasUsage = true
+			usage.prompt += u.Get("prompt_tokens").Int()
+			usage.completion += u.Get("completion_tokens").Int()
+			usage.total += u.Get("total_tokens").Int()
+		}
+
+		prompt := req.prompts[promptIndex]
+		root.Get("choices").ForEach(func(_, choice gjson.Result) bool {
+			text := choice.Get("message.content").String()
+			offset := 0
+			if req.echo {
+				text = prompt + text
+				offset = utf8.RuneCountInString(prompt)
+			}
+			completionsChoice := map[string]any{
+				"index":         int64(promptIndex*req.n) + choice.Get("index").Int(),
+				"text":          text,
+				"finish_reason": nil,
+				"logprobs":      nil,
+			}
+			if finishReason := choice.Get("finish_reason"); finishReason.Type == gjson.String {
+				completionsChoice["finish_reason"] = finishReason.String()
+			}
+			if logprobs := choice.Get("logprobs"); logprobs.IsObject() {
+				completionsChoice["logprobs"], _ = convertChatLogprobs(logprobs, offset)
+			}
+			choiceJSON, _ := json.Marshal(completionsChoice)
+			out, _ = sjson.SetRaw(out, "choices.-1", string(choiceJSON))
+			return true
+		})
+	}
+
+	if hasUsage {
+		out, _ = sjson.Set(out, "usage.prompt_tokens", usage.prompt)
+		out, _ = sjson.Set(out, "usage.completion_tokens", usage.completion)
+		out, _ = sjson.Set(out, "usage.total_tokens", usage.total)
+	}
+	return []byte(out)
+}
+
+// convertChatLogprobs converts Chat Completions logprobs into the legacy layout of parallel token,
+// logprob, alternative and text-offset lists. offset is the character offset of the first token;
+// the offset after the last token is returned for the next chunk of a stream.
+func convertChatLogprobs(logprobs gjson.Result, offset int) (map[string]any, int) {
+	tokens := []string{}
+	tokenLogprobs := []float64{}
+	topLogprobs := []map[string]float64{}
+	textOffsets := []int{}
+	logprobs.Get("content").ForEach(func(_, entry gjson.Result) bool {
+		token := entry.Get("token").String()
+		tokens = append(tokens, token)
+		tokenLogprobs = append(tokenLogprobs, entry.Get("logprob").Float())
+		alternatives := map[string]float64{}
+		entry.Get("top_logprobs").ForEach(func(_, alternative gjson.Result) bool {
+			alternatives[alternative.Get("token").String()] = alternative.Get("logprob").Float()
+			return true
+		})
+		topLogprobs = append(topLogprobs, alternatives)
+		textOffsets = append(textOffsets, offset)
+		offset += utf8.RuneCountInString(token)
+		return true
+	})
+	return map[string]any{
+		"tokens":         tokens,
+		"token_logprobs": tokenLogprobs,
+		"top_logprobs":   topLogprobs,
+		"text_offset":    textOffsets,
+	}, offset
+}
+
+// completionsStreamConverter converts the chunks of one chat completions stream. It echoes the
+// prompt ahead of the first text of each choice and keeps the text offsets of streamed logprobs.
+type completionsStreamConverter struct {
+	req     completionsRequest
+	started map[int64]bool
+	offsets map[int64]int
+}
+
+func newCompletionsStreamConverter(req completionsRequest) *completionsStreamConverter {
+	return &completionsStreamConverter{
+		req:     req,
+		started: make(map[int64]bool),
+		offsets: make(map[int64]int),
+	}
+}
+
+// convert converts a streaming chat completions chunk to completions format.
+// Chunks without text, a finish reason or usage are filtered out.
+//
+// Parameters:
+//   - chunkData: The raw JSON bytes of a single chat completions stream chunk
+//
+// Returns:
+//   - []byte: The converted completions stream chunk, or nil if should be filtered out
+func (s *completionsStreamConverter) convert(chunkData []byte) []byte {
+	root := gjson.ParseBytes(chunkData)
+
+	out := `{"id":"","object":"text_completion","created":0,"model":"","choices":[]}`
+	out, _ = sjson.Set(out, "id", root.Get("id").String())
+	out, _ = sjson.Set(out, "created", root.Get("created").Int())
+	out, _ = sjson.Set(out, "model", root.Get("model").String())
+
+	hasContent := false
+	root.Get("choices").ForEach(func(_, choice gjson.Result) bool {
+		index := choice.Get("index").Int()
+		text := choice.Get("delta.content").String()
+		finishReason := choice.Get("finish_reason")
+		logprobs := choice.Get("logprobs")
+		hasFinish := finishReason.Type == gjson.String && finishReason.String() != ""
+		if text == "" && !hasFinish && !logprobs.IsObject() {
+			return true
+		}
+		hasContent = true
+
+		if !s.started[index] {
+			s.started[index] = true
+			if s.req.echo {
+				prompt := s.req.prompts[0]
+				text = prompt + text
+				s.offsets[index] = utf8.RuneCountInString(prompt)
+			}
+		}
+		completionsChoice := map[string]any{
+			"index":         index,
+			"text":          text,
+			"finish_reason": nil,
+			"logprobs":      nil,
+		}
+		if hasFinish {
+			completionsChoice["finish_reason"] = finishReason.String()
+		}
+		if logprobs.IsObject() {
+			completionsChoice["logprobs"], s.offsets[index] = convertChatLogprobs(logprobs, s.offsets[index])
+		}
+		choiceJSON, _ := json.Marshal(completionsChoice)
+		out, _ = sjson.SetRaw(out, "choices.-1", string(choiceJSON))
+		return true
+	})
+
+	usage := root.Get("usage")
+	if !hasContent && !usage.IsObject() {
+		return nil
+	}
+	if usage.IsObject() {
+		out, _ = sjson.SetRaw(out, "usage", usage.Raw)
+	}
+	return []byte(out)
+}
+
+// handleCompletionsNonStreamingResponse handles non-streaming completions responses.
+// Each prompt is sent to the backend as its own chat completions request, in parallel; the
+// responses are merged into a single completions response.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+//   - req: The parsed completions request
+func (h *OpenAIAPIHandler) handleCompletionsNonStreamingResponse(c *gin.Context, req completionsRequest) {
+	c.Header("Content-Type", "application/json")
+
+	modelName := req.root.Get("model").String()
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	stopKeepAlive := h.StartNonStreamingKeepAlive(c, cliCtx)
+	payloads := make([][]byte, len(req.prompts))
+	for i, prompt := range req.prompts {
+		payloads[i] = convertCompletionsRequestToChatCompletions(req, prompt)
+	}
+	responses, upstreamHeaders, errMsg := h.ExecuteBatchWithAuthManager(cliCtx, h.HandlerType(), modelName, payloads, "")
+	stopKeepAlive()
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	handlers.WriteUpstreamHeaders(c.Writer.Header(), upstreamHeaders)
+	_, _ = c.Writer.Write(convertChatCompletionsResponseToCompletions(req, responses))
+	cliCancel()
+}
+
+// handleCompletionsStreamingResponse handles streaming completions responses.
+// It converts completions request to chat completions format, streams from backend,
+// then converts each response chunk back to completions format before sending to client.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+//   - req: The parsed completions request, which carries a single prompt
+func (h *OpenAIAPIHandler) handleCompletionsStreamingResponse(c *gin.Context, req completionsRequest) {
+	// Get the http.Flusher interface to manually flush the response.
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, handlers.ErrorResponse{
+			Error: handlers.ErrorDetail{
+				Message: "Streaming not supported",
+				Type:    "server_error",
+			},
+		})
+		return
+	}
+
+	// Convert completions request to chat completions format
+	chatCompletionsJSON := convertCompletionsRequestToChatCompletions(req, req.prompts[0])
+	converter := newCompletionsStreamConverter(req)
+
+	modelName := req.root.Get("model").String()
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	dataChan, upstreamHeaders, errChan := h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, chatCompletionsJSON, "")
+
+	setSSEHeaders := func() {
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("Access-Control-Allow-Origin", "*")
+	}
+
+	// Peek at the first chunk
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			cliCancel(c.Request.Context().Err())
+			return
+		case errMsg, ok := <-errChan:
+			if !ok {
+				// Err channel closed cleanly; wait for data channel.
+				errChan = nil
+				continue
+			}
+			h.WriteErrorResponse(c, errMsg)
+			if errMsg != nil {
+				cliCancel(errMsg.Error)
+			} else {
+				cliCancel(nil)
+			}
+			return
+		case chunk, ok := <-dataChan:
+			if !ok {
+				setSSEHeaders()
+				handlers.WriteUpstreamHeaders(c.Writer.Header(), upstreamHeaders)
+				_, _ = fmt.Fprintf(c.Writer, "data: [DONE]\n\n")
+				flusher.Flush()
+				cliCancel(nil)
+				return
+			}
+
+			// Success! Set headers.
+			setSSEHeaders()
+			handlers.WriteUpstreamHeaders(c.Writer.Header(), upstreamHeaders)
+
+			// Write the first chunk
+			converted := converter.convert(chunk)
+			if converted != nil {
+				_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", string(converted))
+				flusher.Flush()
+			}
+
+			done := make(chan struct{})
+			var doneOnce sync.Once
+			stop := func() { doneOnce.Do(func() { close(done) }) }
+
+			convertedChan := make(chan []byte)
+			go func() {
+				defer close(convertedChan)
+				for {
+					select {
+					case <-done:
+						return
+					case chunk, ok := <-dataChan:
+						if !ok {
+							return
+						}
+						converted := converter.convert(chunk)
+						if converted == nil {
+							continue
+						}
+						select {
+						case <-done:
+							return
+						case convertedChan <- converted:
+						}
+					}
+				}
+			}()
+
+			h.handleStreamResult(c, flusher, func(err error) {
+				stop()
+				cliCancel(err)
+			}, convertedChan, errChan)
+			return
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+// completionsChatExecutor answers with the upper-cased user message. When barrier is set, every
+// call waits for it so a test can prove that prompts are executed concurrently.
+type completionsChatExecutor struct {
+	mu       sync.Mutex
+	payloads [][]byte
+	barrier  *sync.WaitGroup
+}
+
+func (e *completionsChatExecutor) Identifier() string { return "completions-provider" }
+
+func (e *completionsChatExecutor) Execute(ctx context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	e.mu.Lock()
+	e.payloads = append(e.payloads, req.Payload)
+	e.mu.Unlock()
+	if e.barrier != nil {
+		e.barrier.Done()
+		released := make(chan struct{})
+		go func() {
+			e.barrier.Wait()
+			close(released)
+		}()
+		select {
+		case <-released:
+		case <-time.After(5 * time.Second):
+			return coreexecutor.Response{}, errors.New("prompts were not executed concurrently")
+		case <-ctx.Done():
+			return coreexecutor.Response{}, ctx.Err()
+		}
+	}
+	reply := strings.ToUpper(gjson.GetBytes(req.Payload, `messages.#(role=="user").content`).String())
+	resp := `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"completions-model","choices":[{"index":0,"message":{"role":"assistant","content":"` + reply + `"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`
+	return coreexecutor.Response{Payload: []byte(resp)}, nil
+}
+
+func (e *completionsChatExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (e *completionsChatExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *completionsChatExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, errors.New("not implemented")
+}
+
+func (e *completionsChatExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func newCompletionsTestRouter(t *testing.T, cfg *sdkconfig.SDKConfig, executor *completionsChatExecutor) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+
+	auth := &coreauth.Auth{ID: "completions-auth", Provider: executor.Identifier(), Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("Register auth: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "completions-model"}})
+	t.Cleanup(func() {
+		registry.GetGlobalRegistry().UnregisterClient(auth.ID)
+	})
+
+	base := handlers.NewBaseAPIHandlers(cfg, manager)
+	h := NewOpenAIAPIHandler(base)
+	router := gin.New()
+	router.POST("/v1/completions", h.Completions)
+	return router
+}
+
+func TestCompletionsBatchedPromptsWithEcho(t *testing.T) {
+	barrier := &sync.WaitGroup{}
+	barrier.Add(2)
+	executor := &completionsChatExecutor{barrier: barrier}
+	router := newCompletionsTestRouter(t, &sdkconfig.SDKConfig{}, executor)
+
+	body := `{"model":"completions-model","prompt":["ab","cd"],"echo":true,"max_tokens":8}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", resp.Code, resp.Body.String())
+	}
+	if len(executor.payloads) != 2 {
+		t.Fatalf("executor calls = %d, want 2", len(executor.payloads))
+	}
+	if gjson.GetBytes(executor.payloads[0], "echo").Exists() {
+		t.Fatalf("echo forwarded upstream: %s", executor.payloads[0])
+	}
+	out := gjson.Parse(resp.Body.String())
+	if out.Get("object").String() != "text_completion" {
+		t.Fatalf("object = %q", out.Get("object").String())
+	}
+	if got := out.Get("choices.1.text").String(); got != "cdCD" {
+		t.Fatalf("choices.1.text = %q, want %q", got, "cdCD")
+	}
+	if got := out.Get("choices.1.index").Int(); got != 1 {
+		t.Fatalf("choices.1.index = %d, want 1", got)
+	}
+	if got := out.Get("usage.total_tokens").Int(); got != 10 {
+		t.Fatalf("usage.total_tokens = %d, want 10", got)
+	}
+}
+
+func TestCompletionsRawPromptModelsSkipInstruction(t *testing.T) {
+	executor := &completionsChatExecutor{}
+	cfg := &sdkconfig.SDKConfig{Completions: sdkconfig.CompletionsConfig{RawPromptModels: []string{"completions-*"}}}
+	router := newCompletionsTestRouter(t, cfg, executor)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/completions", strings.NewReader(`{"model":"completions-model","prompt":"ab"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", resp.Code, resp.Body.String())
+	}
+	messages := gjson.GetBytes(executor.payloads[0], "messages").Array()
+	if len(messages) != 1 || messages[0].Get("role").String() != "user" || messages[0].Get("content").String() != "ab" {
+		t.Fatalf("messages = %s, want the prompt alone", gjson.GetBytes(executor.payloads[0], "messages").Raw)
+	}
+	if got := gjson.Get(resp.Body.String(), "choices.0.text").String(); got != "AB" {
+		t.Fatalf("choices.0.text = %q, want AB", got)
+	}
+}
+
+func TestCompletionsRejectsTokenPrompts(t *testing.T) {
+	if _, err := parseCompletionsRequest([]byte(`{"prompt":[1,2,3]}`)); err == nil {
+		t.Fatal("token prompt accepted")
+	}
+}
+
+func TestConvertCompletionsRequestToChatCompletions(t *testing.T) {
+	req, err := parseCompletionsRequest([]byte(`{"model":"m","prompt":"def add(a, b):","suffix":"\n\nprint(add(1, 2))","logprobs":3,"n":2,"stop":["\n\n"]}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	out := gjson.ParseBytes(convertCompletionsRequestToChatCompletions(req, req.prompts[0]))
+	if out.Get("messages.0.content").String() != insertionInstruction {
+		t.Fatalf("suffix request did not use the insertion instruction: %s", out.Raw)
+	}
+	if got := out.Get("messages.1.content").String(); !strings.Contains(got, "<suffix>\n\nprint(add(1, 2))</suffix>") {
+		t.Fatalf("user message = %q", got)
+	}
+	if !out.Get("logprobs").Bool() || out.Get("top_logprobs").Int() != 3 {
+		t.Fatalf("logprobs not mapped: %s", out.Raw)
+	}
+	if out.Get("n").Int() != 2 || out.Get("stop.0").String() != "\n\n" {
+		t.Fatalf("n or stop not copied: %s", out.Raw)
+	}
+	if out.Get("suffix").Exists() {
+		t.Fatalf("suffix forwarded upstream: %s", out.Raw)
+	}
+}
+
+func TestCompletionsStreamConverterEchoAndLogprobs(t *testing.T) {
+	req, err := parseCompletionsRequest([]byte(`{"prompt":"Hi","echo":true,"logprobs":1}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	converter := newCompletionsStreamConverter(req)
+
+	if got := converter.convert([]byte(`{"id":"c","choices":[{"index":0,"delta":{"role":"assistant"}}]}`)); got != nil {
+		t.Fatalf("role-only chunk not filtered: %s", got)
+	}
+	first := gjson.ParseBytes(converter.convert([]byte(`{"id":"c","choices":[{"index":0,"delta":{"content":" the"},"logprobs":{"content":[{"token":" the","logprob":-0.5,"top_logprobs":[{"token":" the","logprob":-0.5}]}]}}]}`)))
+	if got := first.Get("choices.0.text").String(); got != "Hi the" {
+		t.Fatalf("first text = %q, want %q", got, "Hi the")
+	}
+	if got := first.Get("choices.0.logprobs.text_offset.0").Int(); got != 2 {
+		t.Fatalf("first text_offset = %d, want 2", got)
+	}
+	if got := first.Get(`choices.0.logprobs.top_logprobs.0.\ the`).Float(); got != -0.5 {
+		t.Fatalf("top_logprobs = %v", first.Get("choices.0.logprobs.top_logprobs").Raw)
+	}
+	second := gjson.ParseBytes(converter.convert([]byte(`{"id":"c","choices":[{"index":0,"delta":{"content":" end"},"logprobs":{"content":[{"token":" end","logprob":-1}]},"finish_reason":"stop"}]}`)))
+	if got := second.Get("choices.0.text").String(); got != " end" {
+		t.Fatalf("second text = %q, want %q", got, " end")
+	}
+	if got := second.Get("choices.0.logprobs.text_offset.0").Int(); got != 6 {
+		t.Fatalf("second text_offset = %d, want 6", got)
+	}
+	if got := second.Get("choices.0.finish_reason").String(); got != "stop" {
+		t.Fatalf("finish_reason = %q, want stop", got)
+	}
+}
@@ -0,0 +1,422 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// maxImagesPerRequest matches the OpenAI images API limit on n.
+const maxImagesPerRequest = 10
+
+// maskInstruction tells the model how to use the mask of an edit request, which Gemini image
+// models have no dedicated parameter for.
+const maskInstruction = "The last image is a mask. Only change the areas where the mask is transparent " +
+	"and keep everything else identical to the first image."
+
+// geminiAspectRatios are the aspect ratios Gemini image models accept. OpenAI sizes are mapped
+// to the closest one.
+var geminiAspectRatios = []struct {
+	name  string
+	ratio float64
+}{
+	{"1:1", 1}, {"2:3", 2.0 / 3}, {"3:2", 3.0 / 2}, {"3:4", 3.0 / 4}, {"4:3", 4.0 / 3},
+	{"4:5", 4.0 / 5}, {"5:4", 5.0 / 4}, {"9:16", 9.0 / 16}, {"16:9", 16.0 / 9}, {"21:9", 21.0 / 9},
+}
+
+// imagesRequest is an image generation or edit request in endpoint-neutral form.
+type imagesRequest struct {
+	model          string
+	prompt         string
+	n              int
+	size           string
+	quality        string
+	responseFormat string
+	user           string
+	// images and mask hold the input images of an edit request as data URLs.
+	images []string
+	mask   string
+}
+
+// ImageGenerations handles the /v1/images/generations endpoint.
+// Each requested image is produced by a Chat Completions request with image output enabled, so
+// any image-capable model behind the chat translators (Gemini, Vertex, Antigravity) can serve it.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIAPIHandler) ImageGenerations(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		writeImagesBadRequest(c, err)
+		return
+	}
+	req, err := parseImageGenerationsRequest(rawJSON)
+	if err != nil {
+		writeImagesBadRequest(c, err)
+		return
+	}
+	h.handleImagesRequest(c, req)
+}
+
+// ImageEdits handles the /v1/images/edits endpoint.
+// It accepts the multipart form of the OpenAI API as well as the JSON form that references the
+// input images by URL; the images are sent to the model as chat image parts next to the prompt.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIAPIHandler) ImageEdits(c *gin.Context) {
+	var (
+		req imagesRequest
+		err error
+	)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		req, err = parseImageEditsForm(c)
+	} else {
+		var rawJSON []byte
+		if rawJSON, err = c.GetRawData(); err == nil {
+			req, err = parseImageEditsJSON(rawJSON)
+		}
+	}
+	if err != nil {
+		writeImagesBadRequest(c, err)
+		return
+	}
+	h.handleImagesRequest(c, req)
+}
+
+func writeImagesBadRequest(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, handlers.ErrorResponse{
+		Error: handlers.ErrorDetail{
+			Message: fmt.Sprintf("Invalid request: %v", err),
+			Type:    "invalid_request_error",
+		},
+	})
+}
+
+// parseImageGenerationsRequest reads a JSON image generation request.
+func parseImageGenerationsRequest(rawJSON []byte) (imagesRequest, error) {
+	if !gjson.ValidBytes(rawJSON) {
+		return imagesRequest{}, errors.New("body must be a JSON object")
+	}
+	root := gjson.ParseBytes(rawJSON)
+	if root.Get("stream").Bool() {
+		return imagesRequest{}, errors.New("streaming image generation is not supported")
+	}
+	req := imagesRequest{
+		model:          root.Get("model").String(),
+		prompt:         root.Get("prompt").String(),
+		size:           root.Get("size").String(),
+		quality:        root.Get("quality").String(),
+		responseFormat: root.Get("response_format").String(),
+		user:           root.Get("user").String(),
+		n:              1,
+	}
+	if n := root.Get("n"); n.Exists() {
+		req.n = int(n.Int())
+	}
+	return req, req.validate()
+}
+
+// parseImageEditsJSON reads the JSON form of an edit request, where images and mask are objects
+// carrying an image_url. Uploaded file IDs cannot be resolved and are rejected.
+func parseImageEditsJSON(rawJSON []byte) (imagesRequest, error) {
+	req, err := parseImageGenerationsRequest(rawJSON)
+	if err != nil {
+		return req, err
+	}
+	root := gjson.ParseBytes(rawJSON)
+	imageURL := func(value gjson.Result) (string, error) {
+		if value.Get("file_id").Exists() {
+			return "", errors.New("file_id image references are not supported, send image_url")
+		}
+		url := value.Get("image_url").String()
+		if url == "" {
+			return "", errors.New("image_url is required")
+		}
+		return url, nil
+	}
+	for _, image := range root.Get("images").Array() {
+		url, errURL := imageURL(image)
+		if errURL != nil {
+			return req, errURL
+		}
+		req.images = append(req.images, url)
+	}
+	if mask := root.Get("mask"); mask.IsObject() {
+		if req.mask, err = imageURL(mask); err != nil {
+			return req, err
+		}
+	}
+	if len(req.images) == 0 {
+		return req, errors.New("images is required")
+	}
+	return req, nil
+}
+
+// parseImageEditsForm reads the multipart form of an edit request. Images may be sent as one or
+// more "image" or "image[]" files.
+func parseImageEditsForm(c *gin.Context) (imagesRequest, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return imagesRequest{}, err
+	}
+	value := func(key string) string {
+		if values := form.Value[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	if value("stream") == "true" {
+		return imagesRequest{}, errors.New("streaming image edits are not supported")
+	}
+	req := imagesRequest{
+		model:          value("model"),
+		prompt:         value("prompt"),
+		size:           value("size"),
+		quality:        value("quality"),
+		responseFormat: value("response_format"),
+		user:           value("user"),
+		n:              1,
+	}
+	if n := value("n"); n != "" {
+		if req.n, err = strconv.Atoi(n); err != nil {
+			return req, fmt.Errorf("n must be an integer, got %q", n)
+		}
+	}
+	for _, key := range []string{"image", "image[]"} {
+		for _, file := range form.File[key] {
+			url, errRead := imageFileDataURL(file)
+			if errRead != nil {
+				return req, errRead
+			}
+			req.images = append(req.images, url)
+		}
+	}
+	if files := form.File["mask"]; len(files) > 0 {
+		if req.mask, err = imageFileDataURL(files[0]); err != nil {
+			return req, err
+		}
+	}
+	if len(req.images) == 0 {
+		return req, errors.New("image is required")
+	}
+	return req, req.validate()
+}
+
+// imageFileDataURL reads an uploaded image into a data URL, sniffing the type when the part
+// does not declare an image content type.
+func imageFileDataURL(file *multipart.FileHeader) (string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", file.Filename, err)
+	}
+	defer func() { _ = f.Close() }()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", file.Filename, err)
+	}
+	mimeType := file.Header.Get("Content-Type")
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", fmt.Errorf("%s is not an image", file.Filename)
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+func (r imagesRequest) validate() error {
+	switch {
+	case r.model == "":
+		return errors.New("model is required")
+	case strings.TrimSpace(r.prompt) == "":
+		return errors.New("prompt is required")
+	case r.n < 1 || r.n > maxImagesPerRequest:
+		return fmt.Errorf("n must be between 1 and %d", maxImagesPerRequest)
+	case r.responseFormat != "" && r.responseFormat != "b64_json" && r.responseFormat != "url":
+		return fmt.Errorf("unsupported response_format %q", r.responseFormat)
+	}
+	_, _, err := geminiImageConfig(r.size, r.quality)
+	return err
+}
+
+// geminiImageConfig maps an OpenAI size and quality to a Gemini aspect ratio and image size.
+// The image size is only set for high quality or sizes above 2048 pixels because older image
+// models reject it.
+func geminiImageConfig(size, quality string) (aspectRatio, imageSize string, err error) {
+	if size != "" && size != "auto" {
+		width, height, ok := strings.Cut(size, "x")
+		w, errW := strconv.Atoi(width)
+		hgt, errH := strconv.Atoi(height)
+		if !ok || errW != nil || errH != nil || w <= 0 || hgt <= 0 {
+			return "", "", fmt.Errorf("size must look like 1024x1024, got %q", size)
+		}
+		ratio := float64(w) / float64(hgt)
+		best := math.Inf(1)
+		for _, candidate := range geminiAspectRatios {
+			if diff := math.Abs(math.Log(ratio / candidate.ratio)); diff < best {
+				best, aspectRatio = diff, candidate.name
+			}
+		}
+		switch longest := max(w, hgt); {
+		case longest >= 4096:
+			imageSize = "4K"
+		case longest >= 2048:
+			imageSize = "2K"
+		}
+	}
+	if imageSize == "" && (quality == "high" || quality == "hd") {
+		imageSize = "2K"
+	}
+	return aspectRatio, imageSize, nil
+}
+
+// convertImagesRequestToChatCompletions builds the Chat Completions request that produces one
+// image. Input images come first so the prompt reads as an instruction about them.
+func convertImagesRequestToChatCompletions(req imagesRequest) []byte {
+	out := `{"model":"","messages":[{"role":"user","content":[]}],"modalities":["image","text"]}`
+	out, _ = sjson.Set(out, "model", req.model)
+	images := req.images
+	prompt := req.prompt
+	if req.mask != "" {
+		images = append(append([]string(nil), images...), req.mask)
+		prompt = maskInstruction + "\n\n" + prompt
+	}
+	for _, url := range images {
+		part := `{"type":"image_url","image_url":{"url":""}}`
+		part, _ = sjson.Set(part, "image_url.url", url)
+		out, _ = sjson.SetRaw(out, "messages.0.content.-1", part)
+	}
+	text := `{"type":"text","text":""}`
+	text, _ = sjson.Set(text, "text", prompt)
+	out, _ = sjson.SetRaw(out, "messages.0.content.-1", text)
+
+	aspectRatio, imageSize, _ := geminiImageConfig(req.size, req.quality)
+	if aspectRatio != "" {
+		out, _ = sjson.Set(out, "image_config.aspect_ratio", aspectRatio)
+	}
+	if imageSize != "" {
+		out, _ = sjson.Set(out, "image_config.image_size", imageSize)
+	}
+	if req.user != "" {
+		out, _ = sjson.Set(out, "user", req.user)
+	}
+	return []byte(out)
+}
+
+// convertChatCompletionsResponsesToImages collects the images of the chat responses into an
+// images API response. The proxy does not host files, so the "url" response format returns the
+// image as a data URL. Token usage is summed over the responses.
+func convertChatCompletionsResponsesToImages(req imagesRequest, responses [][]byte) ([]byte, error) {
+	out := `{"created":0,"data":[]}`
+	out, _ = sjson.Set(out, "created", time.Now().Unix())
+	var inputTokens, outputTokens, totalTokens int64
+	outputFormat := ""
+	var text strings.Builder
+	for _, resp := range responses {
+		root := gjson.ParseBytes(resp)
+		root.Get("choices").ForEach(func(_, choice gjson.Result) bool {
+			text.WriteString(choice.Get("message.content").String())
+			choice.Get("message.images").ForEach(func(_, image gjson.Result) bool {
+				url := image.Get("image_url.url").String()
+				mimeType, data, ok := parseImageDataURL(url)
+				if !ok {
+					return true
+				}
+				entry := `{}`
+				if req.responseFormat == "url" {
+					entry, _ = sjson.Set(entry, "url", url)
+				} else {
+					entry, _ = sjson.Set(entry, "b64_json", data)
+				}
+				out, _ = sjson.SetRaw(out, "data.-1", entry)
+				if outputFormat == "" {
+					outputFormat = strings.TrimPrefix(mimeType, "image/")
+				}
+				return true
+			})
+			return true
+		})
+		inputTokens += root.Get("usage.prompt_tokens").Int()
+		outputTokens += root.Get("usage.completion_tokens").Int()
+		totalTokens += root.Get("usage.total_tokens").Int()
+	}
+	if !gjson.Get(out, "data.0").Exists() {
+		if reply := strings.TrimSpace(text.String()); reply != "" {
+			return nil, fmt.Errorf("the model returned no image: %s", reply)
+		}
+		return nil, errors.New("the model returned no image")
+	}
+	if outputFormat != "" {
+		out, _ = sjson.Set(out, "output_format", outputFormat)
+	}
+	if totalTokens > 0 {
+		out, _ = sjson.Set(out, "usage.input_tokens", inputTokens)
+		out, _ = sjson.Set(out, "usage.output_tokens", outputTokens)
+		out, _ = sjson.Set(out, "usage.total_tokens", totalTokens)
+	}
+	return []byte(out), nil
+}
+
+// parseImageDataURL splits a base64 data URL into its MIME type and payload.
+func parseImageDataURL(url string) (mimeType, data string, ok bool) {
+	rest, found := strings.CutPrefix(url, "data:")
+	if !found {
+		return "", "", false
+	}
+	header, data, found := strings.Cut(rest, ",")
+	mimeType, isBase64 := strings.CutSuffix(header, ";base64")
+	if !found || !isBase64 || data == "" {
+		return "", "", false
+	}
+	return mimeType, data, true
+}
+
+// handleImagesRequest runs one chat request per requested image, in parallel, and writes the
+// merged images response.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+//   - req: The parsed images request
+func (h *OpenAIAPIHandler) handleImagesRequest(c *gin.Context, req imagesRequest) {
+	c.Header("Content-Type", "application/json")
+
+	chatCompletionsJSON := convertImagesRequestToChatCompletions(req)
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	stopKeepAlive := h.StartNonStreamingKeepAlive(c, cliCtx)
+	payloads := make([][]byte, req.n)
+	for i := range payloads {
+		payloads[i] = chatCompletionsJSON
+	}
+	responses, upstreamHeaders, errMsg := h.ExecuteBatchWithAuthManager(cliCtx, h.HandlerType(), req.model, payloads, "")
+	stopKeepAlive()
+	if errMsg != nil {
+		h.WriteErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+
+	out, err := convertChatCompletionsResponsesToImages(req, responses)
+	if err != nil {
+		h.WriteErrorResponse(c, &interfaces.ErrorMessage{StatusCode: http.StatusBadGateway, Error: err})
+		cliCancel(err)
+		return
+	}
+	handlers.WriteUpstreamHeaders(c.Writer.Header(), upstreamHeaders)
+	_, _ = c.Writer.Write(out)
+	cliCancel()
+}
@@ -0,0 +1,191 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+// imagesChatExecutor answers every request with one image. When barrier is set, every call waits
+// for it so a test can prove that images are generated concurrently.
+type imagesChatExecutor struct {
+	mu       sync.Mutex
+	payloads [][]byte
+	barrier  *sync.WaitGroup
+}
+
+func (e *imagesChatExecutor) Identifier() string { return "images-provider" }
+
+func (e *imagesChatExecutor) Execute(ctx context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	e.mu.Lock()
+	e.payloads = append(e.payloads, req.Payload)
+	e.mu.Unlock()
+	if e.barrier != nil {
+		e.barrier.Done()
+		released := make(chan struct{})
+		go func() {
+			e.barrier.Wait()
+			close(released)
+		}()
+		select {
+		case <-released:
+		case <-time.After(5 * time.Second):
+			return coreexecutor.Response{}, errors.New("images were not generated concurrently")
+		case <-ctx.Done():
+			return coreexecutor.Response{}, ctx.Err()
+		}
+	}
+	resp := `{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"Here you go.","images":[{"type":"image_url","index":0,"image_url":{"url":"data:image/webp;base64,aW1hZ2U="}}]},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":1290,"total_tokens":1300}}`
+	return coreexecutor.Response{Payload: []byte(resp)}, nil
+}
+
+func (e *imagesChatExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (e *imagesChatExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *imagesChatExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, errors.New("not implemented")
+}
+
+func (e *imagesChatExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func newImagesTestRouter(t *testing.T) (*gin.Engine, *imagesChatExecutor) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	executor := &imagesChatExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+
+	auth := &coreauth.Auth{ID: "images-auth", Provider: executor.Identifier(), Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("Register auth: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "image-model"}})
+	t.Cleanup(func() {
+		registry.GetGlobalRegistry().UnregisterClient(auth.ID)
+	})
+
+	h := NewOpenAIAPIHandler(handlers.NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, manager))
+	router := gin.New()
+	router.POST("/v1/images/generations", h.ImageGenerations)
+	router.POST("/v1/images/edits", h.ImageEdits)
+	return router, executor
+}
+
+func TestImageGenerations(t *testing.T) {
+	router, executor := newImagesTestRouter(t)
+	executor.barrier = &sync.WaitGroup{}
+	executor.barrier.Add(2)
+
+	body := `{"model":"image-model","prompt":"a red fox","n":2,"size":"1792x1024"}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/images/generations", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", resp.Code, resp.Body.String())
+	}
+	if len(executor.payloads) != 2 {
+		t.Fatalf("executor calls = %d, want 2", len(executor.payloads))
+	}
+	chat := gjson.ParseBytes(executor.payloads[0])
+	if chat.Get("modalities.0").String() != "image" || chat.Get("image_config.aspect_ratio").String() != "16:9" {
+		t.Fatalf("chat request = %s", chat.Raw)
+	}
+	if chat.Get("image_config.image_size").Exists() {
+		t.Fatalf("image_size set for a standard size: %s", chat.Raw)
+	}
+
+	out := gjson.Parse(resp.Body.String())
+	if got := len(out.Get("data").Array()); got != 2 {
+		t.Fatalf("data entries = %d, want 2: %s", got, out.Raw)
+	}
+	if got := out.Get("data.0.b64_json").String(); got != "aW1hZ2U=" {
+		t.Fatalf("data.0.b64_json = %q", got)
+	}
+	if got := out.Get("output_format").String(); got != "webp" {
+		t.Fatalf("output_format = %q, want webp", got)
+	}
+	if got := out.Get("usage.total_tokens").Int(); got != 2600 {
+		t.Fatalf("usage.total_tokens = %d, want 2600", got)
+	}
+}
+
+func TestImageEditsMultipart(t *testing.T) {
+	router, executor := newImagesTestRouter(t)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	_ = form.WriteField("model", "image-model")
+	_ = form.WriteField("prompt", "add a hat")
+	_ = form.WriteField("response_format", "url")
+	image, _ := form.CreateFormFile("image[]", "cat.png")
+	_, _ = image.Write([]byte("\x89PNG\r\n\x1a\n0000"))
+	mask, _ := form.CreateFormFile("mask", "mask.png")
+	_, _ = mask.Write([]byte("\x89PNG\r\n\x1a\n1111"))
+	_ = form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/images/edits", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", resp.Code, resp.Body.String())
+	}
+	content := gjson.GetBytes(executor.payloads[0], "messages.0.content").Array()
+	if len(content) != 3 {
+		t.Fatalf("content parts = %d, want image, mask and prompt", len(content))
+	}
+	if url := content[0].Get("image_url.url").String(); !strings.HasPrefix(url, "data:image/png;base64,") {
+		t.Fatalf("image part url = %q", url)
+	}
+	if text := content[2].Get("text").String(); !strings.HasPrefix(text, maskInstruction) || !strings.HasSuffix(text, "add a hat") {
+		t.Fatalf("prompt part = %q", text)
+	}
+	if got := gjson.Get(resp.Body.String(), "data.0.url").String(); got != "data:image/webp;base64,aW1hZ2U=" {
+		t.Fatalf("data.0.url = %q", got)
+	}
+}
+
+func TestGeminiImageConfig(t *testing.T) {
+	tests := []struct {
+		size, quality, aspectRatio, imageSize string
+	}{
+		{"1024x1024", "", "1:1", ""},
+		{"1024x1536", "high", "2:3", "2K"},
+		{"4096x2304", "", "16:9", "4K"},
+		{"auto", "hd", "", "2K"},
+	}
+	for _, tt := range tests {
+		aspectRatio, imageSize, err := geminiImageConfig(tt.size, tt.quality)
+		if err != nil || aspectRatio != tt.aspectRatio || imageSize != tt.imageSize {
+			t.Errorf("geminiImageConfig(%q, %q) = %q, %q, %v; want %q, %q", tt.size, tt.quality, aspectRatio, imageSize, err, tt.aspectRatio, tt.imageSize)
+		}
+	}
+	if _, _, err := geminiImageConfig("large", ""); err == nil {
+		t.Error("malformed size accepted")
+	}
+}
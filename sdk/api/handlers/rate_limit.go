@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+const (
+	rateLimitWindow = time.Minute
+	// rateLimitIdleKeys bounds how many idle client keys are retained before a sweep.
+	rateLimitIdleKeys = 1024
+)
+
+// clientLimiter is shared by every handler so limits apply across protocols.
+var clientLimiter = newClientRateLimiter()
+
+func init() {
+	coreusage.RegisterPlugin(clientLimiter)
+}
+
+type clientRateLimits struct {
+	requestsPerMinute int
+	tokensPerMinute   int
+	maxStreams        int
+}
+
+func (l clientRateLimits) enabled() bool {
+	return l.requestsPerMinute > 0 || l.tokensPerMinute > 0 || l.maxStreams > 0
+}
+
+type tokenSample struct {
+	at     time.Time
+	tokens int64
+}
+
+type clientRateState struct {
+	requests []time.Time
+	tokens   []tokenSample
+	streams  int
+	lastSeen time.Time
+}
+
+// clientRateLimiter enforces per-client-key request, token and stream limits. Token usage is
+// fed back from executor usage records through the coreusage.Plugin interface.
+type clientRateLimiter struct {
+	mu    sync.Mutex
+	state map[string]*clientRateState
+	now   func() time.Time
+}
+
+func newClientRateLimiter() *clientRateLimiter {
+	return &clientRateLimiter{state: make(map[string]*clientRateState), now: time.Now}
+}
+
+// rateLimitError describes a rejected request and when the client may retry.
+type rateLimitError struct {
+	message    string
+	retryAfter time.Duration
+}
+
+func (e *rateLimitError) Error() string { return e.message }
+
+func (e *rateLimitError) retryAfterSeconds() int {
+	seconds := int(math.Ceil(e.retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// acquire admits a request for key under limits. On success it returns a release function that
+// must be called once the request (or stream) has finished.
+func (l *clientRateLimiter) acquire(key string, limits clientRateLimits, stream bool) (func(), *rateLimitError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	st := l.state[key]
+	if st == nil {
+		if len(l.state) >= rateLimitIdleKeys {
+			l.sweepLocked(now)
+		}
+		st = &clientRateState{}
+		l.state[key] = st
+	}
+	st.lastSeen = now
+	st.prune(now)
+
+	if limits.requestsPerMinute > 0 && len(st.requests) >= limits.requestsPerMinute {
+		return nil, &rateLimitError{
+			message:    fmt.Sprintf("Rate limit exceeded: %d requests per minute", limits.requestsPerMinute),
+			retryAfter: st.requests[len(st.requests)-limits.requestsPerMinute].Add(rateLimitWindow).Sub(now),
+		}
+	}
+	if limits.tokensPerMinute > 0 {
+		if used := st.tokenTotal(); used >= int64(limits.tokensPerMinute) {
+			return nil, &rateLimitError{
+				message:    fmt.Sprintf("Rate limit exceeded: %d tokens per minute", limits.tokensPerMinute),
+				retryAfter: st.tokenRetryAfter(int64(limits.tokensPerMinute), now),
+			}
+		}
+	}
+	if stream && limits.maxStreams > 0 && st.streams >= limits.maxStreams {
+		return nil, &rateLimitError{
+			message:    fmt.Sprintf("Rate limit exceeded: %d concurrent streams", limits.maxStreams),
+			retryAfter: time.Second,
+		}
+	}
+
+	st.requests = append(st.requests, now)
+	if !stream {
+		return func() {}, nil
+	}
+	st.streams++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			if st.streams > 0 {
+				st.streams--
+			}
+			st.lastSeen = l.now()
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+// HandleUsage implements coreusage.Plugin by charging consumed tokens to the client key.
+func (l *clientRateLimiter) HandleUsage(_ context.Context, record coreusage.Record) {
+	key := strings.TrimSpace(record.APIKey)
+	if key == "" || record.Hedged {
+		return
+	}
+	tokens := record.Detail.TotalTokens
+	if tokens <= 0 {
+		tokens = record.Detail.InputTokens + record.Detail.OutputTokens + record.Detail.ReasoningTokens
+	}
+	if tokens <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Only keys admitted through acquire are tracked, so disabled limits keep no state.
+	st := l.state[key]
+	if st == nil {
+		return
+	}
+	now := l.now()
+	st.prune(now)
+	st.tokens = append(st.tokens, tokenSample{at: now, tokens: tokens})
+}
+
+func (l *clientRateLimiter) sweepLocked(now time.Time) {
+	for key, st := range l.state {
+		if st.streams == 0 && now.Sub(st.lastSeen) >= rateLimitWindow {
+			delete(l.state, key)
+		}
+	}
+}
+
+func (s *clientRateState) prune(now time.Time) {
+	cutoff := now.Add(-rateLimitWindow)
+	i := 0
+	for i < len(s.requests) && !s.requests[i].After(cutoff) {
+		i++
+	}
+	s.requests = s.requests[i:]
+	j := 0
+	for j < len(s.tokens) && !s.tokens[j].at.After(cutoff) {
+		j++
+	}
+	s.tokens = s.tokens[j:]
+}
+
+func (s *clientRateState) tokenTotal() int64 {
+	var total int64
+	for _, sample := range s.tokens {
+		total += sample.tokens
+	}
+	return total
+}
+
+// tokenRetryAfter returns how long until enough samples leave the window to drop below limit.
+func (s *clientRateState) tokenRetryAfter(limit int64, now time.Time) time.Duration {
+	total := s.tokenTotal()
+	for _, sample := range s.tokens {
+		total -= sample.tokens
+		if total < limit {
+			return sample.at.Add(rateLimitWindow).Sub(now)
+		}
+	}
+	return rateLimitWindow
+}
+
+// resolveClientRateLimits returns the effective limits for apiKey, applying per-key overrides.
+func resolveClientRateLimits(cfg *config.SDKConfig, apiKey string) clientRateLimits {
+	if cfg == nil {
+		return clientRateLimits{}
+	}
+	limits := clientRateLimits{
+		requestsPerMinute: cfg.RateLimit.RequestsPerMinute,
+		tokensPerMinute:   cfg.RateLimit.TokensPerMinute,
+		maxStreams:        cfg.RateLimit.MaxConcurrentStreams,
+	}
+	for i := range cfg.RateLimit.Overrides {
+		override := cfg.RateLimit.Overrides[i]
+		if strings.TrimSpace(override.APIKey) != apiKey {
+			continue
+		}
+		limits.requestsPerMinute = overrideRateLimit(limits.requestsPerMinute, override.RequestsPerMinute)
+		limits.tokensPerMinute = overrideRateLimit(limits.tokensPerMinute, override.TokensPerMinute)
+		limits.maxStreams = overrideRateLimit(limits.maxStreams, override.MaxConcurrentStreams)
+		break
+	}
+	return limits
+}
+
+func overrideRateLimit(global, override int) int {
+	switch {
+	case override < 0:
+		return 0
+	case override > 0:
+		return override
+	default:
+		return global
+	}
+}
+
+// clientAPIKeyFromContext returns the principal stored by the access middleware.
+func clientAPIKeyFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return ""
+	}
+	if v, exists := ginCtx.Get("apiKey"); exists {
+		if key, okKey := v.(string); okKey {
+			return strings.TrimSpace(key)
+		}
+	}
+	return ""
+}
+
+// acquireClientRateLimit admits the request against the caller's rate limits. The returned
+// release function is never nil and must be called when the request finishes.
+func (h *BaseAPIHandler) acquireClientRateLimit(ctx context.Context, handlerType string, stream bool) (func(), *interfaces.ErrorMessage) {
+	apiKey := clientAPIKeyFromContext(ctx)
+	if apiKey == "" {
+		return func() {}, nil
+	}
+	limits := resolveClientRateLimits(h.Cfg, apiKey)
+	if !limits.enabled() {
+		return func() {}, nil
+	}
+	release, errLimit := clientLimiter.acquire(apiKey, limits, stream)
+	if errLimit == nil {
+		return release, nil
+	}
+	retryAfter := strconv.Itoa(errLimit.retryAfterSeconds())
+	if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil {
+		ginCtx.Header("Retry-After", retryAfter)
+	}
+	addon := make(http.Header)
+	addon.Set("Retry-After", retryAfter)
+	return func() {}, &interfaces.ErrorMessage{
+		StatusCode: http.StatusTooManyRequests,
+		Error:      errors.New(string(protocolErrorBody(handlerType, http.StatusTooManyRequests, errLimit.message))),
+		Addon:      addon,
+	}
+}
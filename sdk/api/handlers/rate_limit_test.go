@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func newTestClientRateLimiter(now *time.Time) *clientRateLimiter {
+	limiter := newClientRateLimiter()
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestClientRateLimiter_RequestsPerMinute(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	limiter := newTestClientRateLimiter(&now)
+	limits := clientRateLimits{requestsPerMinute: 2}
+
+	for i := 0; i < 2; i++ {
+		if _, errLimit := limiter.acquire("key", limits, false); errLimit != nil {
+			t.Fatalf("acquire #%d error = %v", i, errLimit)
+		}
+		now = now.Add(10 * time.Second)
+	}
+	_, errLimit := limiter.acquire("key", limits, false)
+	if errLimit == nil {
+		t.Fatal("acquire error = nil, want rate limit")
+	}
+	if got := errLimit.retryAfterSeconds(); got != 40 {
+		t.Fatalf("retryAfterSeconds() = %d, want 40", got)
+	}
+	if _, errOther := limiter.acquire("other", limits, false); errOther != nil {
+		t.Fatalf("acquire(other) error = %v, want independent bucket", errOther)
+	}
+
+	now = now.Add(41 * time.Second)
+	if _, errLimit = limiter.acquire("key", limits, false); errLimit != nil {
+		t.Fatalf("acquire after window error = %v", errLimit)
+	}
+}
+
+func TestClientRateLimiter_TokensPerMinute(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	limiter := newTestClientRateLimiter(&now)
+	limits := clientRateLimits{tokensPerMinute: 100}
+
+	if _, errLimit := limiter.acquire("key", limits, false); errLimit != nil {
+		t.Fatalf("acquire error = %v", errLimit)
+	}
+	limiter.HandleUsage(context.Background(), coreusage.Record{APIKey: "key", Detail: coreusage.Detail{InputTokens: 80, OutputTokens: 30}})
+	now = now.Add(15 * time.Second)
+
+	_, errLimit := limiter.acquire("key", limits, false)
+	if errLimit == nil {
+		t.Fatal("acquire error = nil, want token limit")
+	}
+	if got := errLimit.retryAfterSeconds(); got != 45 {
+		t.Fatalf("retryAfterSeconds() = %d, want 45", got)
+	}
+}
+
+func TestClientRateLimiter_MaxConcurrentStreams(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	limiter := newTestClientRateLimiter(&now)
+	limits := clientRateLimits{maxStreams: 1}
+
+	release, errLimit := limiter.acquire("key", limits, true)
+	if errLimit != nil {
+		t.Fatalf("acquire error = %v", errLimit)
+	}
+	if _, errLimit = limiter.acquire("key", limits, true); errLimit == nil {
+		t.Fatal("second stream error = nil, want concurrency limit")
+	}
+	if _, errLimit = limiter.acquire("key", limits, false); errLimit != nil {
+		t.Fatalf("non-stream acquire error = %v, want streams limit to ignore it", errLimit)
+	}
+	release()
+	release()
+	if _, errLimit = limiter.acquire("key", limits, true); errLimit != nil {
+		t.Fatalf("acquire after release error = %v", errLimit)
+	}
+}
+
+func TestResolveClientRateLimits_Overrides(t *testing.T) {
+	cfg := &sdkconfig.SDKConfig{RateLimit: sdkconfig.RateLimitConfig{
+		RequestsPerMinute:    10,
+		TokensPerMinute:      1000,
+		MaxConcurrentStreams: 2,
+		Overrides: []sdkconfig.RateLimitOverride{
+			{APIKey: "vip", RequestsPerMinute: 100, TokensPerMinute: -1},
+		},
+	}}
+
+	if got := resolveClientRateLimits(cfg, "regular"); got != (clientRateLimits{requestsPerMinute: 10, tokensPerMinute: 1000, maxStreams: 2}) {
+		t.Fatalf("resolveClientRateLimits(regular) = %+v", got)
+	}
+	if got := resolveClientRateLimits(cfg, "vip"); got != (clientRateLimits{requestsPerMinute: 100, tokensPerMinute: 0, maxStreams: 2}) {
+		t.Fatalf("resolveClientRateLimits(vip) = %+v", got)
+	}
+}
+
+func TestAcquireClientRateLimit_WritesProtocolError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		handlerType string
+		path        string
+		want        string
+	}{
+		{handlerType: constant.Claude, path: "error.type", want: "rate_limit_error"},
+		{handlerType: constant.Gemini, path: "error.status", want: "RESOURCE_EXHAUSTED"},
+		{handlerType: constant.OpenAI, path: "error.code", want: "rate_limit_exceeded"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.handlerType, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+			c.Set("apiKey", "limited-"+tc.handlerType)
+			ctx := context.WithValue(context.Background(), "gin", c)
+
+			handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{RateLimit: sdkconfig.RateLimitConfig{MaxConcurrentStreams: 1}}, nil)
+			release, errMsg := handler.acquireClientRateLimit(ctx, tc.handlerType, true)
+			if errMsg != nil {
+				t.Fatalf("first acquire error = %v", errMsg.Error)
+			}
+			defer release()
+			_, errMsg = handler.acquireClientRateLimit(ctx, tc.handlerType, true)
+			if errMsg == nil {
+				t.Fatal("second acquire error = nil, want rate limit")
+			}
+			handler.WriteErrorResponse(c, errMsg)
+
+			if recorder.Code != http.StatusTooManyRequests {
+				t.Fatalf("status = %d, want %d", recorder.Code, http.StatusTooManyRequests)
+			}
+			if got := recorder.Header().Get("Retry-After"); got != "1" {
+				t.Fatalf("Retry-After = %q, want %q", got, "1")
+			}
+			if got := gjson.GetBytes(recorder.Body.Bytes(), tc.path).String(); got != tc.want {
+				t.Fatalf("%s = %q, want %q (body %s)", tc.path, got, tc.want, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+// validateClientRequest runs the inbound schema check registered for the handler's format.
+// Malformed requests are answered with a 400 in the client's protocol before any credential is
+// selected, so they neither reach an upstream nor use up a retry. Embedding and audio requests
+// have their own body shapes and are not checked.
+func validateClientRequest(handlerType, alt string, rawJSON []byte) *interfaces.ErrorMessage {
+	if alt == "embeddings" || strings.HasPrefix(alt, "audio/") {
+		return nil
+	}
+	err := sdktranslator.ValidateRequest(sdktranslator.FromString(handlerType), rawJSON)
+	if err == nil {
+		return nil
+	}
+	return &interfaces.ErrorMessage{
+		StatusCode: http.StatusBadRequest,
+		Error:      errors.New(string(protocolErrorBody(handlerType, http.StatusBadRequest, err.Error()))),
+	}
+}
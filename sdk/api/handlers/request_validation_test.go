@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestExecuteWithAuthManager_RejectsInvalidRequestBeforeDispatch(t *testing.T) {
+	format := sdktranslator.FromString(constant.Claude)
+	sdktranslator.RegisterValidator(format, func(rawJSON []byte) error {
+		if !gjson.GetBytes(rawJSON, "messages").IsArray() {
+			return errors.New("messages: Field required")
+		}
+		return nil
+	})
+	t.Cleanup(func() { sdktranslator.RegisterValidator(format, nil) })
+
+	// No auth manager is configured: reaching dispatch would panic.
+	handler := &BaseAPIHandler{}
+	_, _, errMsg := handler.ExecuteWithAuthManager(context.Background(), constant.Claude, "claude-sonnet-4-5", []byte(`{"model":"claude-sonnet-4-5"}`), "")
+	if errMsg == nil {
+		t.Fatal("ExecuteWithAuthManager() error = nil, want 400")
+	}
+	if errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("StatusCode = %d, want %d", errMsg.StatusCode, http.StatusBadRequest)
+	}
+	body := errMsg.Error.Error()
+	if gjson.Get(body, "type").String() != "error" || gjson.Get(body, "error.type").String() != "invalid_request_error" {
+		t.Fatalf("error body = %s, want Claude invalid_request_error", body)
+	}
+	if got := gjson.Get(body, "error.message").String(); got != "messages: Field required" {
+		t.Fatalf("error.message = %q", got)
+	}
+
+	_, _, errChan := handler.ExecuteStreamWithAuthManager(context.Background(), constant.Claude, "claude-sonnet-4-5", []byte(`{}`), "")
+	if streamErr := <-errChan; streamErr == nil || streamErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("stream error = %v, want 400", streamErr)
+	}
+}
+
+func TestValidateClientRequest_GeminiErrorShape(t *testing.T) {
+	format := sdktranslator.FromString(constant.Gemini)
+	sdktranslator.RegisterValidator(format, func([]byte) error { return errors.New("contents: expected a list of Content") })
+	t.Cleanup(func() { sdktranslator.RegisterValidator(format, nil) })
+
+	errMsg := validateClientRequest(constant.Gemini, "", []byte(`{"contents":{}}`))
+	if errMsg == nil {
+		t.Fatal("validateClientRequest() = nil, want error")
+	}
+	body := errMsg.Error.Error()
+	if gjson.Get(body, "error.code").Int() != http.StatusBadRequest || gjson.Get(body, "error.status").String() != "INVALID_ARGUMENT" {
+		t.Fatalf("error body = %s, want Gemini INVALID_ARGUMENT", body)
+	}
+	if errMsg := validateClientRequest(constant.Gemini, "embeddings", []byte(`{"content":{}}`)); errMsg != nil {
+		t.Fatalf("embedding request rejected: %v", errMsg.Error)
+	}
+}
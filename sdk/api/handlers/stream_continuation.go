@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// continuationPrompt asks a model that cannot be prefilled to pick up where the failed stream
+// stopped.
+const continuationPrompt = "Continue your previous response exactly where it stopped. Do not repeat any text that was already written."
+
+// streamContinuation follows a stream as it is forwarded to the client so that, when the upstream
+// fails half-way, the request can be re-issued with the text already emitted and the continued
+// output stitched into the same client stream. Chunks are in the client's format.
+type streamContinuation interface {
+	// observe records a chunk that was forwarded to the client.
+	observe(chunk []byte)
+	// request returns the client payload for the continuation, or false when the stream cannot be
+	// continued (it emitted a tool call, already finished, or has no text to hand back). With
+	// prefill the emitted text is handed back as the start of the assistant turn; otherwise a
+	// continuation prompt follows it.
+	request(rawJSON []byte, prefill bool) ([]byte, bool)
+	// begin resets the stitching state before a continued stream is forwarded.
+	begin()
+	// stitch rewrites a chunk of the continued stream so it reads as part of the original one.
+	// An empty result means the chunk is dropped.
+	stitch(chunk []byte) []byte
+}
+
+// newStreamContinuation returns the continuation for a client format, or nil when streams in that
+// format cannot be continued. Responses API streams are not continued: their events carry output
+// item ids and sequence numbers that a second upstream response cannot be stitched into.
+func newStreamContinuation(handlerType, alt string) streamContinuation {
+	switch handlerType {
+	case constant.OpenAI:
+		return &openAIContinuation{}
+	case constant.Claude:
+		return &claudeContinuation{openIndex: -1}
+	case constant.Gemini:
+		if alt == "" {
+			return &geminiContinuation{}
+		}
+	}
+	return nil
+}
+
+// continuationPrefill reports whether every provider serving the model accepts an assistant
+// prefill, so the continuation can resume mid-sentence without a prompt. That holds for Claude
+// and for anthropic-compatibility providers.
+func continuationPrefill(providers []string, model string) bool {
+	if len(providers) == 0 {
+		return false
+	}
+	baseModel := thinking.ParseSuffix(model).ModelName
+	for _, provider := range providers {
+		if provider == constant.Claude {
+			continue
+		}
+		info := registry.GetGlobalRegistry().GetModelInfo(baseModel, provider)
+		if info == nil || info.Type != "anthropic-compatibility" {
+			return false
+		}
+	}
+	return true
+}
+
+// continuationText returns the emitted text as it is handed back. Claude rejects a prefill that
+// ends in whitespace.
+func continuationText(text string, prefill bool) string {
+	if prefill {
+		return strings.TrimRightFunc(text, func(r rune) bool { return r == ' ' || r == '\t' || r == '\n' || r == '\r' })
+	}
+	return text
+}
+
+// openAIContinuation continues Chat Completions streams.
+type openAIContinuation struct {
+	id          string
+	text        strings.Builder
+	unsupported bool
+	finished    bool
+}
+
+func (c *openAIContinuation) observe(chunk []byte) {
+	if !gjson.ValidBytes(chunk) {
+		return
+	}
+	root := gjson.ParseBytes(chunk)
+	if c.id == "" {
+		c.id = root.Get("id").String()
+	}
+	choice := root.Get("choices.0")
+	c.text.WriteString(choice.Get("delta.content").String())
+	if choice.Get("delta.tool_calls").Exists() || choice.Get("delta.function_call").Exists() {
+		c.unsupported = true
+	}
+	if choice.Get("finish_reason").String() != "" {
+		c.finished = true
+	}
+}
+
+func (c *openAIContinuation) request(rawJSON []byte, prefill bool) ([]byte, bool) {
+	if c.unsupported || c.finished || !gjson.GetBytes(rawJSON, "messages").IsArray() {
+		return nil, false
+	}
+	text := continuationText(c.text.String(), prefill)
+	if text == "" {
+		return nil, false
+	}
+	out, _ := sjson.SetBytes(rawJSON, "messages.-1", map[string]any{"role": "assistant", "content": text})
+	if !prefill {
+		out, _ = sjson.SetBytes(out, "messages.-1", map[string]any{"role": "user", "content": continuationPrompt})
+	}
+	return out, true
+}
+
+func (c *openAIContinuation) begin() {}
+
+// stitch keeps the completion id of the original stream and drops the role announcement the
+// continued stream opens with.
+func (c *openAIContinuation) stitch(chunk []byte) []byte {
+	if !gjson.ValidBytes(chunk) {
+		return chunk
+	}
+	out := chunk
+	if c.id != "" && gjson.GetBytes(out, "id").Exists() {
+		out, _ = sjson.SetBytes(out, "id", c.id)
+	}
+	if gjson.GetBytes(out, "choices.0.delta.role").Exists() {
+		out, _ = sjson.DeleteBytes(out, "choices.0.delta.role")
+	}
+	return out
+}
+
+// geminiContinuation continues generateContent streams.
+type geminiContinuation struct {
+	text        strings.Builder
+	unsupported bool
+	finished    bool
+}
+
+func (c *geminiContinuation) observe(chunk []byte) {
+	if !gjson.ValidBytes(chunk) {
+		return
+	}
+	candidate := gjson.GetBytes(chunk, "candidates.0")
+	candidate.Get("content.parts").ForEach(func(_, part gjson.Result) bool {
+		if part.Get("functionCall").Exists() {
+			c.unsupported = true
+		}
+		if !part.Get("thought").Bool() {
+			c.text.WriteString(part.Get("text").String())
+		}
+		return true
+	})
+	if candidate.Get("finishReason").String() != "" {
+		c.finished = true
+	}
+}
+
+func (c *geminiContinuation) request(rawJSON []byte, prefill bool) ([]byte, bool) {
+	if c.unsupported || c.finished || !gjson.GetBytes(rawJSON, "contents").IsArray() {
+		return nil, false
+	}
+	text := continuationText(c.text.String(), prefill)
+	if text == "" {
+		return nil, false
+	}
+	out, _ := sjson.SetBytes(rawJSON, "contents.-1", map[string]any{"role": "model", "parts": []any{map[string]any{"text": text}}})
+	if !prefill {
+		out, _ = sjson.SetBytes(out, "contents.-1", map[string]any{"role": "user", "parts": []any{map[string]any{"text": continuationPrompt}}})
+	}
+	return out, true
+}
+
+func (c *geminiContinuation) begin() {}
+
+func (c *geminiContinuation) stitch(chunk []byte) []byte { return chunk }
+
+// claudeContinuation continues Messages streams. Chunks may carry whole SSE events or single
+// lines of one, so both sides work on complete lines.
+type claudeContinuation struct {
+	observed    []byte
+	text        strings.Builder
+	unsupported bool
+	finished    bool
+	// openIndex and openType describe the content block left open when the stream failed.
+	openIndex int
+	openType  string
+	nextIndex int
+
+	pending   []byte
+	eventLine []byte
+	dropEvent bool
+	indexes   map[int64]int
+	merged    bool
+	carried   bool
+	stitchAt  int
+}
+
+func (c *claudeContinuation) observe(chunk []byte) {
+	var lines [][]byte
+	c.observed, lines = splitSSELines(c.observed, chunk)
+	for _, line := range lines {
+		data, ok := sseData(line)
+		if !ok {
+			continue
+		}
+		root := gjson.ParseBytes(data)
+		index := int(root.Get("index").Int())
+		switch root.Get("type").String() {
+		case "content_block_start":
+			c.openIndex = index
+			c.openType = root.Get("content_block.type").String()
+			if c.openType == "tool_use" || c.openType == "server_tool_use" {
+				c.unsupported = true
+			}
+			if index >= c.nextIndex {
+				c.nextIndex = index + 1
+			}
+		case "content_block_delta":
+			if root.Get("delta.type").String() == "text_delta" {
+				c.text.WriteString(root.Get("delta.text").String())
+			}
+		case "content_block_stop":
+			if index == c.openIndex {
+				c.openIndex, c.openType = -1, ""
+			}
+		case "message_delta":
+			if root.Get("delta.stop_reason").String() != "" {
+				c.finished = true
+			}
+		case "message_stop":
+			c.finished = true
+		}
+	}
+}
+
+func (c *claudeContinuation) request(rawJSON []byte, prefill bool) ([]byte, bool) {
+	if c.unsupported || c.finished || !gjson.GetBytes(rawJSON, "messages").IsArray() {
+		return nil, false
+	}
+	text := continuationText(c.text.String(), prefill)
+	if text == "" {
+		return nil, false
+	}
+	out, _ := sjson.SetBytes(rawJSON, "messages.-1", map[string]any{"role": "assistant", "content": []any{map[string]any{"type": "text", "text": text}}})
+	if prefill {
+		// Extended thinking cannot be combined with a prefilled assistant turn.
+		out, _ = sjson.DeleteBytes(out, "thinking")
+	} else {
+		out, _ = sjson.SetBytes(out, "messages.-1", map[string]any{"role": "user", "content": continuationPrompt})
+	}
+	return out, true
+}
+
+func (c *claudeContinuation) begin() {
+	c.pending = nil
+	c.eventLine = nil
+	c.dropEvent = false
+	c.indexes = make(map[int64]int)
+	c.merged = false
+	c.carried = c.openIndex >= 0
+	c.stitchAt = c.nextIndex
+}
+
+// stitch drops the second message_start, merges the first text block of the continued stream
+// into the text block left open by the failure and moves the other blocks past the indexes the
+// client has already seen. A block of another kind left open is closed before anything new starts.
+func (c *claudeContinuation) stitch(chunk []byte) []byte {
+	var lines [][]byte
+	c.pending, lines = splitSSELines(c.pending, chunk)
+	var out bytes.Buffer
+	for _, line := range lines {
+		trimmed := bytes.TrimRight(line, "\r\n")
+		switch {
+		case len(trimmed) == 0:
+			if !c.dropEvent {
+				out.Write(c.eventLine)
+				out.Write(line)
+			}
+			c.eventLine, c.dropEvent = nil, false
+		case bytes.HasPrefix(trimmed, []byte("event:")):
+			c.eventLine = append([]byte(nil), line...)
+		case bytes.HasPrefix(trimmed, []byte("data:")):
+			data, _ := sseData(line)
+			rewritten, keep := c.stitchEvent(&out, data)
+			if !keep {
+				c.dropEvent = true
+				continue
+			}
+			out.Write(c.eventLine)
+			c.eventLine = nil
+			out.WriteString("data: ")
+			out.Write(rewritten)
+			out.WriteByte('\n')
+		default:
+			out.Write(line)
+		}
+	}
+	return out.Bytes()
+}
+
+func (c *claudeContinuation) stitchEvent(out *bytes.Buffer, data []byte) ([]byte, bool) {
+	root := gjson.ParseBytes(data)
+	index := root.Get("index").Int()
+	switch root.Get("type").String() {
+	case "message_start":
+		return nil, false
+	case "content_block_start":
+		if c.carried && !c.merged && c.openType == "text" && root.Get("content_block.type").String() == "text" {
+			c.merged = true
+			c.indexes[index] = c.openIndex
+			return nil, false
+		}
+		c.closeCarried(out)
+		c.indexes[index] = c.stitchAt
+		c.stitchAt++
+	case "content_block_delta", "content_block_stop":
+	case "message_delta", "message_stop":
+		c.closeCarried(out)
+		return data, true
+	default:
+		return data, true
+	}
+	if mapped, ok := c.indexes[index]; ok && int64(mapped) != index {
+		data, _ = sjson.SetBytes(data, "index", mapped)
+	}
+	return data, true
+}
+
+// closeCarried writes the stop event of the block left open by the failure, unless the continued
+// stream has taken it over.
+func (c *claudeContinuation) closeCarried(out *bytes.Buffer) {
+	if !c.carried || c.merged {
+		return
+	}
+	c.carried = false
+	fmt.Fprintf(out, "event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":%d}\n\n", c.openIndex)
+}
+
+// splitSSELines appends chunk to pending and returns the complete lines, newline included,
+// together with the incomplete remainder.
+func splitSSELines(pending, chunk []byte) ([]byte, [][]byte) {
+	pending = append(pending, chunk...)
+	var lines [][]byte
+	for {
+		i := bytes.IndexByte(pending, '\n')
+		if i < 0 {
+			break
+		}
+		lines = append(lines, pending[:i+1])
+		pending = pending[i+1:]
+	}
+	return append([]byte(nil), pending...), lines
+}
+
+func sseData(line []byte) ([]byte, bool) {
+	line = bytes.TrimRight(line, "\r\n")
+	if !bytes.HasPrefix(line, []byte("data:")) {
+		return nil, false
+	}
+	return bytes.TrimSpace(line[len("data:"):]), true
+}
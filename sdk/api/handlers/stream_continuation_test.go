@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+// dropMidStreamExecutor streams the start of an answer and then fails on the first credential it
+// is called with; every later call continues the answer.
+type dropMidStreamExecutor struct {
+	mu       sync.Mutex
+	auths    []string
+	payloads [][]byte
+}
+
+func (e *dropMidStreamExecutor) Identifier() string { return "claude" }
+
+func (e *dropMidStreamExecutor) Execute(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "Execute not implemented"}
+}
+
+func (e *dropMidStreamExecutor) ExecuteStream(_ context.Context, auth *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (*coreexecutor.StreamResult, error) {
+	e.mu.Lock()
+	e.auths = append(e.auths, auth.ID)
+	e.payloads = append(e.payloads, req.Payload)
+	call := len(e.auths)
+	e.mu.Unlock()
+
+	ch := make(chan coreexecutor.StreamChunk, 16)
+	if call == 1 {
+		ch <- coreexecutor.StreamChunk{Payload: []byte("event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\"}}\n\n")}
+		ch <- coreexecutor.StreamChunk{Payload: []byte("event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n")}
+		ch <- coreexecutor.StreamChunk{Payload: []byte("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hello \"}}\n\n")}
+		ch <- coreexecutor.StreamChunk{Err: &coreauth.Error{Code: "upstream", Message: "connection reset", HTTPStatus: http.StatusBadGateway}}
+	} else {
+		// A passthrough stream arrives one SSE line at a time.
+		for _, line := range []string{
+			"event: message_start\n", "data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_2\"}}\n", "\n",
+			"event: content_block_start\n", "data: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n", "\n",
+			"event: content_block_delta\n", "data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\" world\"}}\n", "\n",
+		} {
+			ch <- coreexecutor.StreamChunk{Payload: []byte(line)}
+		}
+		ch <- coreexecutor.StreamChunk{Payload: []byte("event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\nevent: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")}
+	}
+	close(ch)
+	return &coreexecutor.StreamResult{Chunks: ch}, nil
+}
+
+func (e *dropMidStreamExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *dropMidStreamExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *dropMidStreamExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func TestExecuteStreamWithAuthManager_ContinuesAfterMidStreamFailure(t *testing.T) {
+	const model = "continuation-test-model"
+	executor := &dropMidStreamExecutor{}
+	manager := coreauth.NewManager(nil, &coreauth.FillFirstSelector{}, nil)
+	manager.RegisterExecutor(executor)
+	for _, id := range []string{"cont-a", "cont-b"} {
+		if _, err := manager.Register(context.Background(), &coreauth.Auth{ID: id, Provider: "claude", Status: coreauth.StatusActive}); err != nil {
+			t.Fatalf("manager.Register(%s): %v", id, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, "claude", []*registry.ModelInfo{{ID: model}})
+		authID := id
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(authID) })
+	}
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{
+		Streaming: sdkconfig.StreamingConfig{ContinuationRetries: 1},
+	}, manager)
+	rawJSON := []byte(`{"model":"` + model + `","thinking":{"type":"enabled","budget_tokens":1024},"messages":[{"role":"user","content":"hi"}]}`)
+	dataChan, _, errChan := handler.ExecuteStreamWithAuthManager(context.Background(), "claude", model, rawJSON, "")
+
+	var got strings.Builder
+	for chunk := range dataChan {
+		got.Write(chunk)
+	}
+	for msg := range errChan {
+		if msg != nil {
+			t.Fatalf("unexpected error after continuation: %+v", msg)
+		}
+	}
+
+	want := "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\"}}\n\n" +
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n" +
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hello \"}}\n\n" +
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\" world\"}}\n\n" +
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n" +
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"
+	if got.String() != want {
+		t.Fatalf("stitched stream =\n%s\nwant\n%s", got.String(), want)
+	}
+
+	if len(executor.auths) != 2 || executor.auths[0] == executor.auths[1] {
+		t.Fatalf("executor auths = %v, want the continuation on another credential", executor.auths)
+	}
+	continued := gjson.ParseBytes(executor.payloads[1])
+	if last := continued.Get("messages.1"); last.Get("role").String() != "assistant" || last.Get("content.0.text").String() != "Hello" {
+		t.Fatalf("continuation messages = %s, want the emitted text as a trimmed prefill", continued.Get("messages").Raw)
+	}
+	if continued.Get("thinking").Exists() {
+		t.Fatalf("continuation payload kept thinking alongside a prefill: %s", executor.payloads[1])
+	}
+}
+
+func TestOpenAIContinuation(t *testing.T) {
+	c := newStreamContinuation("openai", "")
+	c.observe([]byte(`{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"role":"assistant","content":"Once upon"}}]}`))
+
+	payload, ok := c.request([]byte(`{"messages":[{"role":"user","content":"tell a story"}]}`), false)
+	if !ok {
+		t.Fatal("request() refused a plain text stream")
+	}
+	messages := gjson.GetBytes(payload, "messages").Array()
+	if len(messages) != 3 || messages[1].Get("content").String() != "Once upon" || messages[2].Get("content").String() != continuationPrompt {
+		t.Fatalf("continuation messages = %s", gjson.GetBytes(payload, "messages").Raw)
+	}
+
+	c.begin()
+	stitched := c.stitch([]byte(`{"id":"chatcmpl-2","choices":[{"index":0,"delta":{"role":"assistant","content":" a time"}}]}`))
+	if id := gjson.GetBytes(stitched, "id").String(); id != "chatcmpl-1" {
+		t.Fatalf("stitched id = %q, want the original completion id", id)
+	}
+	if gjson.GetBytes(stitched, "choices.0.delta.role").Exists() {
+		t.Fatalf("stitched chunk kept the role announcement: %s", stitched)
+	}
+
+	c.observe([]byte(`{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"name":"f"}}]}}]}`))
+	if _, ok := c.request([]byte(`{"messages":[]}`), false); ok {
+		t.Fatal("request() continued a stream that emitted a tool call")
+	}
+}
+
+func TestContinuationRefusesStreamWithoutText(t *testing.T) {
+	tests := []struct {
+		handlerType string
+		chunk       string
+		rawJSON     string
+	}{
+		{handlerType: "openai", chunk: `{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"role":"assistant","content":""}}]}`, rawJSON: `{"messages":[{"role":"user","content":"hi"}]}`},
+		{handlerType: "gemini", chunk: `{"candidates":[{"content":{"parts":[{"text":"pondering","thought":true}]}}]}`, rawJSON: `{"contents":[{"role":"user","parts":[{"text":"hi"}]}]}`},
+		{handlerType: "claude", chunk: "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\"}}\n\n", rawJSON: `{"messages":[{"role":"user","content":"hi"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.handlerType, func(t *testing.T) {
+			c := newStreamContinuation(tt.handlerType, "")
+			c.observe([]byte(tt.chunk))
+			for _, prefill := range []bool{false, true} {
+				if payload, ok := c.request([]byte(tt.rawJSON), prefill); ok {
+					t.Fatalf("request(prefill=%v) = %s, want no continuation without emitted text", prefill, payload)
+				}
+			}
+		})
+	}
+}
@@ -17,11 +17,14 @@ import (
 	"github.com/google/uuid"
 	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/metrics"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ProviderExecutor defines the contract required by Manager to execute provider calls.
@@ -94,6 +97,10 @@ type Result struct {
 	Success bool
 	// RetryAfter carries a provider supplied retry hint (e.g. 429 retryDelay).
 	RetryAfter *time.Duration
+	// Latency is the observed time to first byte for the attempt (zero when unknown).
+	Latency time.Duration
+	// Stream marks results of streaming executions, whose Latency is the time to first chunk.
+	Stream bool
 	// Error describes the failure when Success is false.
 	Error *Error
 }
@@ -103,6 +110,12 @@ type Selector interface {
 	Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error)
 }
 
+// ResultObserver is an optional Selector extension that receives every recorded
+// execution result so that future picks can adapt to observed credential health.
+type ResultObserver interface {
+	ObserveResult(result Result)
+}
+
 // Hook captures lifecycle callbacks for observing auth changes.
 type Hook interface {
 	// OnAuthRegistered fires when a new auth is registered.
@@ -135,6 +148,8 @@ type Manager struct {
 	auths     map[string]*Auth
 	// providerOffsets tracks per-model provider rotation state for multi-provider routing.
 	providerOffsets map[string]int
+	// sessions pins conversations to auths when routing session affinity is enabled.
+	sessions sessionAffinity
 
 	// Retry controls request retry behavior.
 	requestRetry     atomic.Int32
@@ -154,6 +169,9 @@ type Manager struct {
 	// Optional HTTP RoundTripper provider injected by host.
 	rtProvider RoundTripperProvider
 
+	// executionHook observes executor calls; nil when no hook is installed.
+	executionHook ExecutionHook
+
 	// Auto refresh state
 	refreshCancel context.CancelFunc
 }
@@ -313,6 +331,10 @@ func (m *Manager) rebuildAPIKeyModelAliasLocked(cfg *internalconfig.Config) {
 				compileAPIKeyModelAliasForModels(byAlias, entry.Models)
 			}
 		default:
+			if entry := resolveAnthropicCompatConfig(cfg, auth); entry != nil {
+				compileAPIKeyModelAliasForModels(byAlias, entry.Models)
+				break
+			}
 			// OpenAI-compat uses config selection from auth.Attributes.
 			providerKey := ""
 			compatName := ""
@@ -497,17 +519,23 @@ func (m *Manager) Load(ctx context.Context) error {
 
 // Execute performs a non-streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
+// When every credential for the model is exhausted, the configured model-fallbacks chain is tried.
 func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
+	return executeWithModelFallback(ctx, m, normalized, req, opts, m.executeWithRetry)
+}
 
+func (m *Manager) executeWithRetry(ctx context.Context, normalized []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	_, maxWait := m.retrySettings()
 
 	var lastErr error
 	for attempt := 0; ; attempt++ {
-		resp, errExec := m.executeMixedOnce(ctx, normalized, req, opts)
+		attemptCtx, span := startAttemptSpan(ctx, "cliproxy.execute.attempt", req.Model, attempt)
+		resp, errExec := m.executeMixedOnce(attemptCtx, normalized, req, opts)
+		tracing.End(span, errExec)
 		if errExec == nil {
 			return resp, nil
 		}
@@ -528,17 +556,23 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxye
 
 // ExecuteCount performs a non-streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
+// When every credential for the model is exhausted, the configured model-fallbacks chain is tried.
 func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
+	return executeWithModelFallback(ctx, m, normalized, req, opts, m.executeCountWithRetry)
+}
 
+func (m *Manager) executeCountWithRetry(ctx context.Context, normalized []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	_, maxWait := m.retrySettings()
 
 	var lastErr error
 	for attempt := 0; ; attempt++ {
-		resp, errExec := m.executeCountMixedOnce(ctx, normalized, req, opts)
+		attemptCtx, span := startAttemptSpan(ctx, "cliproxy.count.attempt", req.Model, attempt)
+		resp, errExec := m.executeCountMixedOnce(attemptCtx, normalized, req, opts)
+		tracing.End(span, errExec)
 		if errExec == nil {
 			return resp, nil
 		}
@@ -559,17 +593,23 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req clip
 
 // ExecuteStream performs a streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
+// When every credential for the model is exhausted, the configured model-fallbacks chain is tried.
 func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return nil, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
+	return executeWithModelFallback(ctx, m, normalized, req, opts, m.executeStreamWithRetry)
+}
 
+func (m *Manager) executeStreamWithRetry(ctx context.Context, normalized []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
 	_, maxWait := m.retrySettings()
 
 	var lastErr error
 	for attempt := 0; ; attempt++ {
-		result, errStream := m.executeStreamMixedOnce(ctx, normalized, req, opts)
+		attemptCtx, span := startAttemptSpan(ctx, "cliproxy.stream.attempt", req.Model, attempt)
+		result, errStream := m.executeStreamMixedOnce(attemptCtx, normalized, req, opts)
+		tracing.End(span, errStream)
 		if errStream == nil {
 			return result, nil
 		}
@@ -592,56 +632,76 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 	if len(providers) == 0 {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
+	if delay := m.hedgeDelay(req.Model); delay > 0 {
+		return executeHedged(ctx, m, providers, req, opts, delay, m.executeOnAuth, nil, nil)
+	}
 	routeModel := req.Model
 	opts = ensureRequestedModelMetadata(opts, routeModel)
-	tried := make(map[string]struct{})
+	tried := triedFromMetadata(opts.Metadata)
 	var lastErr error
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, errPick := m.selectAuth(ctx, providers, routeModel, opts, tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
 			}
 			return cliproxyexecutor.Response{}, errPick
 		}
-
-		entry := logEntryWithRequestID(ctx)
-		debugLogAuthSelection(entry, auth, provider, req.Model)
-		publishSelectedAuthMetadata(opts.Metadata, auth.ID)
-
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
+		resp, errExec := m.executeOnAuth(ctx, auth, executor, provider, req, opts)
+		if errExec == nil {
+			return resp, nil
 		}
-		execReq := req
-		execReq.Model = rewriteModelForAuth(routeModel, auth)
-		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
-		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
-		resp, errExec := executor.Execute(execCtx, auth, execReq, opts)
-		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
-		if errExec != nil {
-			if errCtx := execCtx.Err(); errCtx != nil {
-				return cliproxyexecutor.Response{}, errCtx
-			}
-			result.Error = &Error{Message: errExec.Error()}
-			if se, ok := errors.AsType[cliproxyexecutor.StatusError](errExec); ok && se != nil {
-				result.Error.HTTPStatus = se.StatusCode()
-			}
-			if ra := retryAfterFromError(errExec); ra != nil {
-				result.RetryAfter = ra
-			}
-			m.MarkResult(execCtx, result)
-			if isRequestInvalidError(errExec) {
-				return cliproxyexecutor.Response{}, errExec
-			}
-			lastErr = errExec
-			continue
+		if errCtx := ctx.Err(); errCtx != nil {
+			return cliproxyexecutor.Response{}, errCtx
+		}
+		if isRequestInvalidError(errExec) {
+			return cliproxyexecutor.Response{}, errExec
+		}
+		lastErr = errExec
+	}
+}
+
+// executeOnAuth runs one non-streaming attempt of req on auth and records its result. Attempts
+// whose context was cancelled are not recorded against the credential.
+func (m *Manager) executeOnAuth(ctx context.Context, auth *Auth, executor ProviderExecutor, provider string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	routeModel := req.Model
+	entry := logEntryWithRequestID(ctx)
+	debugLogAuthSelection(entry, auth, provider, req.Model)
+	publishSelectedAuthMetadata(opts.Metadata, auth.ID)
+
+	execCtx := ctx
+	if rt := m.roundTripperFor(auth); rt != nil {
+		execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
+		execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
+	}
+	execReq := req
+	execReq.Model = rewriteModelForAuth(routeModel, auth)
+	execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
+	execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
+	execCtx, span := startExecutionSpan(execCtx, "cliproxy.executor.execute", auth, provider, execReq.Model)
+	execCtx, hookCall, execReq, execOpts := m.beginExecution(execCtx, auth, provider, execReq, opts)
+	startedAt := time.Now()
+	resp, errExec := executor.Execute(execCtx, auth, execReq, execOpts)
+	tracing.End(span, errExec)
+	finishExecution(execCtx, hookCall, resp, errExec)
+	result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil, Latency: time.Since(startedAt)}
+	if errExec != nil {
+		if errCtx := execCtx.Err(); errCtx != nil {
+			return cliproxyexecutor.Response{}, errCtx
+		}
+		result.Error = &Error{Message: errExec.Error()}
+		if se, ok := errors.AsType[cliproxyexecutor.StatusError](errExec); ok && se != nil {
+			result.Error.HTTPStatus = se.StatusCode()
+		}
+		if ra := retryAfterFromError(errExec); ra != nil {
+			result.RetryAfter = ra
 		}
 		m.MarkResult(execCtx, result)
-		return resp, nil
+		return cliproxyexecutor.Response{}, errExec
 	}
+	m.MarkResult(execCtx, result)
+	return resp, nil
 }
 
 func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
@@ -650,10 +710,10 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 	}
 	routeModel := req.Model
 	opts = ensureRequestedModelMetadata(opts, routeModel)
-	tried := make(map[string]struct{})
+	tried := triedFromMetadata(opts.Metadata)
 	var lastErr error
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, errPick := m.selectAuth(ctx, providers, routeModel, opts, tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
@@ -675,7 +735,12 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 		execReq.Model = rewriteModelForAuth(routeModel, auth)
 		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
 		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
-		resp, errExec := executor.CountTokens(execCtx, auth, execReq, opts)
+		execCtx, span := startExecutionSpan(execCtx, "cliproxy.executor.count", auth, provider, execReq.Model)
+		execCtx, hookCall, execReq, execOpts := m.beginExecution(execCtx, auth, provider, execReq, opts)
+		resp, errExec := executor.CountTokens(execCtx, auth, execReq, execOpts)
+		tracing.End(span, errExec)
+		finishExecution(execCtx, hookCall, resp, errExec)
+		// Token counts are cheap, so their latency is left out of the selector's averages.
 		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
 		if errExec != nil {
 			if errCtx := execCtx.Err(); errCtx != nil {
@@ -704,86 +769,229 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 	if len(providers) == 0 {
 		return nil, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
+	if delay := m.hedgeDelay(req.Model); delay > 0 {
+		keep := func(a *streamAttempt, release func()) { a.release = release }
+		attempt, errHedged := executeHedged(ctx, m, providers, req, opts, delay, m.openStreamOnAuth, keep, (*streamAttempt).abandon)
+		if errHedged != nil {
+			return nil, errHedged
+		}
+		return m.forwardStream(attempt), nil
+	}
 	routeModel := req.Model
 	opts = ensureRequestedModelMetadata(opts, routeModel)
-	tried := make(map[string]struct{})
+	tried := triedFromMetadata(opts.Metadata)
 	var lastErr error
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, errPick := m.selectAuth(ctx, providers, routeModel, opts, tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return nil, lastErr
 			}
 			return nil, errPick
 		}
-
-		entry := logEntryWithRequestID(ctx)
-		debugLogAuthSelection(entry, auth, provider, req.Model)
-		publishSelectedAuthMetadata(opts.Metadata, auth.ID)
-
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
+		attempt, errStream := m.openStreamOnAuth(ctx, auth, executor, provider, req, opts)
+		if errStream == nil {
+			return m.forwardStream(attempt), nil
 		}
-		execReq := req
-		execReq.Model = rewriteModelForAuth(routeModel, auth)
-		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
-		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
-		streamResult, errStream := executor.ExecuteStream(execCtx, auth, execReq, opts)
-		if errStream != nil {
-			if errCtx := execCtx.Err(); errCtx != nil {
-				return nil, errCtx
-			}
-			rerr := &Error{Message: errStream.Error()}
-			if se, ok := errors.AsType[cliproxyexecutor.StatusError](errStream); ok && se != nil {
-				rerr.HTTPStatus = se.StatusCode()
-			}
-			result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: false, Error: rerr}
-			result.RetryAfter = retryAfterFromError(errStream)
-			m.MarkResult(execCtx, result)
-			if isRequestInvalidError(errStream) {
-				return nil, errStream
-			}
-			lastErr = errStream
-			continue
+		if errCtx := ctx.Err(); errCtx != nil {
+			return nil, errCtx
 		}
-		out := make(chan cliproxyexecutor.StreamChunk)
-		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamChunks <-chan cliproxyexecutor.StreamChunk) {
-			defer close(out)
-			var failed bool
-			forward := true
-			for chunk := range streamChunks {
-				if chunk.Err != nil && !failed {
-					failed = true
-					rerr := &Error{Message: chunk.Err.Error()}
-					if se, ok := errors.AsType[cliproxyexecutor.StatusError](chunk.Err); ok && se != nil {
-						rerr.HTTPStatus = se.StatusCode()
-					}
-					m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: false, Error: rerr})
-				}
-				if !forward {
-					continue
-				}
-				if streamCtx == nil {
-					out <- chunk
-					continue
+		if isRequestInvalidError(errStream) {
+			return nil, errStream
+		}
+		lastErr = errStream
+	}
+}
+
+// streamAttempt is an upstream stream that has been opened on one credential and has delivered
+// its first chunk (or ended) but has not been forwarded to the caller yet.
+type streamAttempt struct {
+	ctx        context.Context
+	auth       *Auth
+	provider   string
+	routeModel string
+	span       trace.Span
+	hookCall   *ExecutionCall
+	startedAt  time.Time
+	result     *cliproxyexecutor.StreamResult
+	first      cliproxyexecutor.StreamChunk
+	hasFirst   bool
+	firstByte  time.Duration
+	release    func()
+}
+
+// openStreamOnAuth starts req on auth and waits for the first chunk, so an upstream that fails
+// or times out before producing anything is reported as an error while nothing has reached the
+// client and the caller can fail over to the next credential. Which first-chunk errors count as
+// such a failure is decided by firstChunkFailsOver, for hedged and unhedged streams alike.
+func (m *Manager) openStreamOnAuth(ctx context.Context, auth *Auth, executor ProviderExecutor, provider string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (*streamAttempt, error) {
+	routeModel := req.Model
+	entry := logEntryWithRequestID(ctx)
+	debugLogAuthSelection(entry, auth, provider, req.Model)
+	publishSelectedAuthMetadata(opts.Metadata, auth.ID)
+
+	execCtx := ctx
+	if rt := m.roundTripperFor(auth); rt != nil {
+		execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
+		execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
+	}
+	execReq := req
+	execReq.Model = rewriteModelForAuth(routeModel, auth)
+	execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
+	execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
+	execCtx, span := startExecutionSpan(execCtx, "cliproxy.executor.stream", auth, provider, execReq.Model)
+	execCtx, hookCall, execReq, execOpts := m.beginExecution(execCtx, auth, provider, execReq, opts)
+	startedAt := time.Now()
+	streamResult, errStream := executor.ExecuteStream(execCtx, auth, execReq, execOpts)
+	if errStream != nil {
+		tracing.End(span, errStream)
+		finishExecution(execCtx, hookCall, cliproxyexecutor.Response{}, errStream)
+		if errCtx := execCtx.Err(); errCtx != nil {
+			return nil, errCtx
+		}
+		rerr := &Error{Message: errStream.Error()}
+		if se, ok := errors.AsType[cliproxyexecutor.StatusError](errStream); ok && se != nil {
+			rerr.HTTPStatus = se.StatusCode()
+		}
+		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: false, Error: rerr, Latency: time.Since(startedAt), Stream: true}
+		result.RetryAfter = retryAfterFromError(errStream)
+		m.MarkResult(execCtx, result)
+		return nil, errStream
+	}
+	first, hasFirst := waitFirstChunk(execCtx, streamResult.Chunks)
+	if hasFirst && firstChunkFailsOver(first.Err) {
+		errFirst := first.Err
+		go drainStreamChunks(streamResult.Chunks)
+		tracing.End(span, errFirst)
+		finishExecution(execCtx, hookCall, cliproxyexecutor.Response{}, errFirst)
+		rerr := &Error{Message: errFirst.Error(), HTTPStatus: statusCodeFromError(errFirst)}
+		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: false, Error: rerr, Latency: time.Since(startedAt), Stream: true}
+		result.RetryAfter = retryAfterFromError(errFirst)
+		m.MarkResult(execCtx, result)
+		return nil, errFirst
+	}
+	attempt := &streamAttempt{
+		ctx:        execCtx,
+		auth:       auth.Clone(),
+		provider:   provider,
+		routeModel: routeModel,
+		span:       span,
+		hookCall:   hookCall,
+		startedAt:  startedAt,
+		result:     streamResult,
+		first:      first,
+		hasFirst:   hasFirst,
+	}
+	if hasFirst {
+		attempt.firstByte = time.Since(startedAt)
+	}
+	return attempt, nil
+}
+
+// firstChunkFailsOver reports whether an error carried by the first chunk of a stream means the
+// credential failed before producing anything, so the request moves to the next credential.
+// That holds for upstream server errors (5xx), which include timeouts (504); other errors are
+// forwarded to the client inside the stream.
+func firstChunkFailsOver(err error) bool {
+	return err != nil && statusCodeFromError(err) >= http.StatusInternalServerError
+}
+
+// abandon discards an opened stream that will not be forwarded, such as the losing side of a
+// hedged request. Its context has been cancelled, so the executor winds down on its own.
+func (a *streamAttempt) abandon() {
+	if a == nil {
+		return
+	}
+	go drainStreamChunks(a.result.Chunks)
+	tracing.End(a.span, context.Canceled)
+	finishExecution(a.ctx, a.hookCall, cliproxyexecutor.Response{}, context.Canceled)
+	if a.release != nil {
+		a.release()
+	}
+}
+
+// forwardStream relays an opened stream to the caller, starting with the chunk that was already
+// received, and records the stream's outcome once it ends.
+func (m *Manager) forwardStream(a *streamAttempt) *cliproxyexecutor.StreamResult {
+	out := make(chan cliproxyexecutor.StreamChunk)
+	go func() {
+		defer close(out)
+		defer a.span.End()
+		if a.release != nil {
+			defer a.release()
+		}
+		streamCtx := a.ctx
+		firstByte := a.firstByte
+		var failed bool
+		var streamErr error
+		forward := true
+		pending := a.hasFirst
+		for {
+			var chunk cliproxyexecutor.StreamChunk
+			if pending {
+				chunk, pending = a.first, false
+			} else {
+				var ok bool
+				if chunk, ok = <-a.result.Chunks; !ok {
+					break
 				}
-				select {
-				case <-streamCtx.Done():
-					forward = false
-				case out <- chunk:
+			}
+			if firstByte == 0 {
+				firstByte = time.Since(a.startedAt)
+			}
+			observeStreamChunk(streamCtx, a.hookCall, chunk)
+			if chunk.Err != nil && !failed {
+				failed = true
+				streamErr = chunk.Err
+				tracing.RecordError(a.span, chunk.Err)
+				rerr := &Error{Message: chunk.Err.Error()}
+				if se, ok := errors.AsType[cliproxyexecutor.StatusError](chunk.Err); ok && se != nil {
+					rerr.HTTPStatus = se.StatusCode()
 				}
+				m.MarkResult(streamCtx, Result{AuthID: a.auth.ID, Provider: a.provider, Model: a.routeModel, Success: false, Error: rerr, Latency: firstByte, Stream: true})
 			}
-			if !failed {
-				m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: true})
+			if !forward {
+				continue
+			}
+			if streamCtx == nil {
+				out <- chunk
+				continue
 			}
-		}(execCtx, auth.Clone(), provider, streamResult.Chunks)
-		return &cliproxyexecutor.StreamResult{
-			Headers: streamResult.Headers,
-			Chunks:  out,
-		}, nil
+			select {
+			case <-streamCtx.Done():
+				forward = false
+			case out <- chunk:
+			}
+		}
+		if !failed {
+			m.MarkResult(streamCtx, Result{AuthID: a.auth.ID, Provider: a.provider, Model: a.routeModel, Success: true, Latency: firstByte, Stream: true})
+		}
+		finishExecution(streamCtx, a.hookCall, cliproxyexecutor.Response{Headers: a.result.Headers}, streamErr)
+	}()
+	return &cliproxyexecutor.StreamResult{
+		Headers: a.result.Headers,
+		Chunks:  out,
+	}
+}
+
+// waitFirstChunk blocks until chunks yields its first chunk, the channel closes or ctx ends.
+func waitFirstChunk(ctx context.Context, chunks <-chan cliproxyexecutor.StreamChunk) (cliproxyexecutor.StreamChunk, bool) {
+	if ctx == nil {
+		chunk, ok := <-chunks
+		return chunk, ok
+	}
+	select {
+	case chunk, ok := <-chunks:
+		return chunk, ok
+	case <-ctx.Done():
+		return cliproxyexecutor.StreamChunk{}, false
+	}
+}
+
+// drainStreamChunks consumes an abandoned stream so its producer can finish and exit.
+func drainStreamChunks(chunks <-chan cliproxyexecutor.StreamChunk) {
+	for range chunks {
 	}
 }
 
@@ -844,6 +1052,41 @@ func pinnedAuthIDFromMetadata(meta map[string]any) string {
 	}
 }
 
+// allowedAuthPrefixesFromMetadata returns the credential prefixes the caller may route to.
+// The boolean is false when no restriction applies.
+func allowedAuthPrefixesFromMetadata(meta map[string]any) ([]string, bool) {
+	if len(meta) == 0 {
+		return nil, false
+	}
+	prefixes, ok := meta[cliproxyexecutor.AllowedAuthPrefixesMetadataKey].([]string)
+	if !ok || len(prefixes) == 0 {
+		return nil, false
+	}
+	return prefixes, true
+}
+
+// triedFromMetadata seeds the set of already tried auths with the ones the caller excluded.
+func triedFromMetadata(meta map[string]any) map[string]struct{} {
+	tried := make(map[string]struct{})
+	excluded, _ := meta[cliproxyexecutor.ExcludedAuthsMetadataKey].([]string)
+	for _, id := range excluded {
+		if id = strings.TrimSpace(id); id != "" {
+			tried[id] = struct{}{}
+		}
+	}
+	return tried
+}
+
+func authPrefixAllowed(auth *Auth, allowed []string) bool {
+	prefix := strings.TrimSpace(auth.Prefix)
+	for _, candidate := range allowed {
+		if strings.EqualFold(strings.TrimSpace(candidate), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func publishSelectedAuthMetadata(meta map[string]any, authID string) {
 	if len(meta) == 0 {
 		return
@@ -912,6 +1155,10 @@ func (m *Manager) applyAPIKeyModelAlias(auth *Auth, requestedModel string) strin
 	case "vertex":
 		upstreamModel = resolveUpstreamModelForVertexAPIKey(cfg, auth, requestedModel)
 	default:
+		if entry := resolveAnthropicCompatConfig(cfg, auth); entry != nil {
+			upstreamModel = resolveModelAliasFromConfigModels(requestedModel, asModelAliasEntries(entry.Models))
+			break
+		}
 		upstreamModel = resolveUpstreamModelForOpenAICompatAPIKey(cfg, auth, requestedModel)
 	}
 
@@ -1071,6 +1318,24 @@ func resolveOpenAICompatConfig(cfg *internalconfig.Config, providerKey, compatNa
 	return nil
 }
 
+// resolveAnthropicCompatConfig returns the anthropic-compatibility entry auth was synthesized
+// from, or nil for any other auth.
+func resolveAnthropicCompatConfig(cfg *internalconfig.Config, auth *Auth) *internalconfig.AnthropicCompatibility {
+	if cfg == nil || auth == nil || len(auth.Attributes) == 0 {
+		return nil
+	}
+	compatName := strings.TrimSpace(auth.Attributes["anthropic_compat_name"])
+	if compatName == "" {
+		return nil
+	}
+	for i := range cfg.AnthropicCompatibility {
+		if strings.EqualFold(strings.TrimSpace(cfg.AnthropicCompatibility[i].Name), compatName) {
+			return &cfg.AnthropicCompatibility[i]
+		}
+	}
+	return nil
+}
+
 func asModelAliasEntries[T interface {
 	GetName() string
 	GetAlias() string
@@ -1216,6 +1481,7 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	setModelQuota := false
 
 	m.mu.Lock()
+	selector := m.selector
 	if auth, ok := m.auths[result.AuthID]; ok && auth != nil {
 		now := time.Now()
 
@@ -1322,6 +1588,10 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 		registry.GetGlobalRegistry().SuspendClientModel(result.AuthID, result.Model, suspendReason)
 	}
 
+	if observer, ok := selector.(ResultObserver); ok && observer != nil {
+		observer.ObserveResult(result)
+	}
+	metrics.ObserveUpstream(result.Provider, result.Model, resultMetricLabel(result), result.Stream, result.Latency)
 	m.hook.OnResult(ctx, result)
 }
 
@@ -1663,6 +1933,7 @@ func (m *Manager) CloseExecutionSession(sessionID string) {
 
 func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, error) {
 	pinnedAuthID := pinnedAuthIDFromMetadata(opts.Metadata)
+	allowedPrefixes, restrictPrefixes := allowedAuthPrefixesFromMetadata(opts.Metadata)
 
 	m.mu.RLock()
 	executor, okExecutor := m.executors[provider]
@@ -1687,6 +1958,9 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 		if pinnedAuthID != "" && candidate.ID != pinnedAuthID {
 			continue
 		}
+		if restrictPrefixes && !authPrefixAllowed(candidate, allowedPrefixes) {
+			continue
+		}
 		if _, used := tried[candidate.ID]; used {
 			continue
 		}
@@ -1723,6 +1997,7 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 
 func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, string, error) {
 	pinnedAuthID := pinnedAuthIDFromMetadata(opts.Metadata)
+	allowedPrefixes, restrictPrefixes := allowedAuthPrefixesFromMetadata(opts.Metadata)
 
 	providerSet := make(map[string]struct{}, len(providers))
 	for _, provider := range providers {
@@ -1754,6 +2029,9 @@ func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model s
 		if pinnedAuthID != "" && candidate.ID != pinnedAuthID {
 			continue
 		}
+		if restrictPrefixes && !authPrefixAllowed(candidate, allowedPrefixes) {
+			continue
+		}
 		providerKey := strings.TrimSpace(strings.ToLower(candidate.Provider))
 		if providerKey == "" {
 			continue
@@ -1776,15 +2054,30 @@ func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model s
 		m.mu.RUnlock()
 		return nil, nil, "", &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
-	selected, errPick := m.selector.Pick(ctx, "mixed", model, opts, candidates)
-	if errPick != nil {
-		m.mu.RUnlock()
-		return nil, nil, "", errPick
+	now := time.Now()
+	sessionKey, sessionTTL := "", time.Duration(0)
+	if pinnedAuthID == "" {
+		sessionKey, sessionTTL = m.sessionAffinityKey(model, opts)
+	}
+	var selected *Auth
+	if sessionKey != "" {
+		selected = m.sessions.preferred(sessionKey, model, candidates, now)
+	}
+	if selected == nil {
+		var errPick error
+		selected, errPick = m.selector.Pick(ctx, "mixed", model, opts, candidates)
+		if errPick != nil {
+			m.mu.RUnlock()
+			return nil, nil, "", errPick
+		}
 	}
 	if selected == nil {
 		m.mu.RUnlock()
 		return nil, nil, "", &Error{Code: "auth_not_found", Message: "selector returned no auth"}
 	}
+	if sessionKey != "" {
+		m.sessions.bind(sessionKey, selected.ID, sessionTTL, now)
+	}
 	providerKey := strings.TrimSpace(strings.ToLower(selected.Provider))
 	executor, okExecutor := m.executors[providerKey]
 	if !okExecutor {
@@ -2138,6 +2431,7 @@ func (m *Manager) refreshAuth(ctx context.Context, id string) {
 	log.Debugf("refreshed %s, %s, %v", auth.Provider, auth.ID, err)
 	now := time.Now()
 	if err != nil {
+		metrics.IncRefreshFailure(auth.Provider)
 		m.mu.Lock()
 		if current := m.auths[id]; current != nil {
 			current.NextRefreshAfter = now.Add(refreshFailureBackoff)
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestPickNextMixed_AllowedAuthPrefixes(t *testing.T) {
+	t.Parallel()
+
+	manager := NewManager(nil, &RoundRobinSelector{}, nil)
+	manager.RegisterExecutor(&replaceAwareExecutor{id: "claude"})
+	for _, auth := range []*Auth{
+		{ID: "plain", Provider: "claude"},
+		{ID: "team-a", Provider: "claude", Prefix: "teamA"},
+		{ID: "team-b", Provider: "claude", Prefix: "teamB"},
+	} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("Register(%s) error = %v", auth.ID, err)
+		}
+	}
+
+	opts := cliproxyexecutor.Options{Metadata: map[string]any{
+		cliproxyexecutor.AllowedAuthPrefixesMetadataKey: []string{"teamA", ""},
+	}}
+	seen := make(map[string]struct{})
+	for i := 0; i < 6; i++ {
+		auth, _, _, err := manager.pickNextMixed(context.Background(), []string{"claude"}, "", opts, map[string]struct{}{})
+		if err != nil {
+			t.Fatalf("pickNextMixed() error = %v", err)
+		}
+		seen[auth.ID] = struct{}{}
+	}
+	if _, ok := seen["team-b"]; ok {
+		t.Fatalf("picked auths = %v, want team-b excluded", seen)
+	}
+	if len(seen) != 2 {
+		t.Fatalf("picked auths = %v, want plain and team-a", seen)
+	}
+}
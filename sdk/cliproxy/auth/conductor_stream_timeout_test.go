@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// stallingExecutor times out before the first chunk for the stalled auth and streams normally
+// for every other one.
+type stallingExecutor struct {
+	stalled string
+	mu      sync.Mutex
+	calls   []string
+}
+
+func (e *stallingExecutor) Identifier() string { return "stalltest" }
+
+func (e *stallingExecutor) Execute(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, errors.New("not implemented")
+}
+
+func (e *stallingExecutor) ExecuteStream(_ context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+	e.mu.Lock()
+	e.calls = append(e.calls, auth.ID)
+	e.mu.Unlock()
+	ch := make(chan cliproxyexecutor.StreamChunk, 2)
+	if auth.ID == e.stalled {
+		ch <- cliproxyexecutor.StreamChunk{Err: &cliproxyexecutor.TimeoutError{Phase: "idle", Timeout: time.Second}}
+	} else {
+		ch <- cliproxyexecutor.StreamChunk{Payload: []byte("hello")}
+		ch <- cliproxyexecutor.StreamChunk{Payload: []byte("world")}
+	}
+	close(ch)
+	return &cliproxyexecutor.StreamResult{Chunks: ch}, nil
+}
+
+func (e *stallingExecutor) CountTokens(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, errors.New("not implemented")
+}
+
+func (e *stallingExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *stallingExecutor) HttpRequest(context.Context, *Auth, *http.Request) (*http.Response, error) {
+	return nil, nil
+}
+
+func TestManagerExecuteStream_FailsOverStallBeforeFirstChunk(t *testing.T) {
+	const model = "stall-failover-model"
+	executor := &stallingExecutor{stalled: "stall-a"}
+	manager := NewManager(nil, &FillFirstSelector{}, nil)
+	manager.RegisterExecutor(executor)
+	reg := registry.GetGlobalRegistry()
+	for _, id := range []string{"stall-a", "stall-b"} {
+		if _, err := manager.Register(context.Background(), &Auth{ID: id, Provider: "stalltest"}); err != nil {
+			t.Fatalf("Register(%s) error = %v", id, err)
+		}
+		reg.RegisterClient(id, "stalltest", []*registry.ModelInfo{{ID: model}})
+		authID := id
+		t.Cleanup(func() { reg.UnregisterClient(authID) })
+	}
+
+	result, err := manager.ExecuteStream(context.Background(), []string{"stalltest"}, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{})
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	var got string
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("unexpected stream error: %v", chunk.Err)
+		}
+		got += string(chunk.Payload)
+	}
+	if got != "helloworld" {
+		t.Fatalf("stream payload = %q, want %q", got, "helloworld")
+	}
+	if len(executor.calls) != 2 || executor.calls[0] != "stall-a" || executor.calls[1] != "stall-b" {
+		t.Fatalf("executor calls = %v, want stalled auth then fallback", executor.calls)
+	}
+}
+
+func TestManagerExecuteStream_ReturnsStallWhenNoCredentialLeft(t *testing.T) {
+	const model = "stall-only-model"
+	executor := &stallingExecutor{stalled: "stall-only"}
+	manager := NewManager(nil, &RoundRobinSelector{}, nil)
+	manager.RegisterExecutor(executor)
+	if _, err := manager.Register(context.Background(), &Auth{ID: "stall-only", Provider: "stalltest"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("stall-only", "stalltest", []*registry.ModelInfo{{ID: model}})
+	t.Cleanup(func() { reg.UnregisterClient("stall-only") })
+
+	_, err := manager.ExecuteStream(context.Background(), []string{"stalltest"}, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{})
+	var errTimeout *cliproxyexecutor.TimeoutError
+	if !errors.As(err, &errTimeout) {
+		t.Fatalf("ExecuteStream() error = %v, want TimeoutError", err)
+	}
+	if errTimeout.StatusCode() != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want %d", errTimeout.StatusCode(), http.StatusGatewayTimeout)
+	}
+}
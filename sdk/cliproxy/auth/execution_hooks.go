@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// ExecutionCall describes a single executor invocation made by the Manager.
+type ExecutionCall struct {
+	// Provider is the provider key of the selected executor.
+	Provider string
+	// Auth is the credential selected for the call.
+	Auth *Auth
+	// Request is handed to the executor; changes made in BeforeExecute are used for the call.
+	Request cliproxyexecutor.Request
+	// Options carries the execution flags; changes made in BeforeExecute are used for the call.
+	Options cliproxyexecutor.Options
+	// RoundTripper is the transport for the upstream call. Replacing it in BeforeExecute
+	// overrides the transport for this call only.
+	RoundTripper http.RoundTripper
+	// TranslatedRequest is the provider-facing payload the executor sent upstream. It is set
+	// before OnStreamChunk and AfterExecute when the executor reported one.
+	TranslatedRequest []byte
+	// State is owned by the hook and carried unchanged between its callbacks.
+	State any
+
+	hook     ExecutionHook
+	upstream *cliproxyexecutor.UpstreamRequest
+}
+
+// ExecutionHook observes every executor call made by Execute, ExecuteStream and ExecuteCount,
+// including retries on other credentials. Callbacks run synchronously on the request path.
+type ExecutionHook interface {
+	BeforeExecute(ctx context.Context, call *ExecutionCall)
+	AfterExecute(ctx context.Context, call *ExecutionCall, resp cliproxyexecutor.Response, err error)
+	OnStreamChunk(ctx context.Context, call *ExecutionCall, chunk cliproxyexecutor.StreamChunk)
+}
+
+// SetExecutionHook installs the hook invoked around executor calls. Passing nil removes it.
+func (m *Manager) SetExecutionHook(hook ExecutionHook) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.executionHook = hook
+	m.mu.Unlock()
+}
+
+// beginExecution runs BeforeExecute for a call about to be dispatched and returns the context,
+// request and options the executor must use. The returned call is nil when no hook is installed.
+func (m *Manager) beginExecution(ctx context.Context, auth *Auth, provider string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (context.Context, *ExecutionCall, cliproxyexecutor.Request, cliproxyexecutor.Options) {
+	m.mu.RLock()
+	hook := m.executionHook
+	m.mu.RUnlock()
+	if hook == nil {
+		return ctx, nil, req, opts
+	}
+	rt, _ := ctx.Value(roundTripperContextKey{}).(http.RoundTripper)
+	call := &ExecutionCall{
+		Provider:     provider,
+		Auth:         auth,
+		Request:      req,
+		Options:      opts,
+		RoundTripper: rt,
+		hook:         hook,
+	}
+	hook.BeforeExecute(ctx, call)
+	if call.RoundTripper != nil && call.RoundTripper != rt {
+		ctx = context.WithValue(ctx, roundTripperContextKey{}, call.RoundTripper)
+		ctx = context.WithValue(ctx, "cliproxy.roundtripper", call.RoundTripper)
+	}
+	ctx, call.upstream = cliproxyexecutor.WithUpstreamRequest(ctx)
+	return ctx, call, call.Request, call.Options
+}
+
+// finishExecution runs AfterExecute once the outcome of a call is known.
+func finishExecution(ctx context.Context, call *ExecutionCall, resp cliproxyexecutor.Response, err error) {
+	if call == nil {
+		return
+	}
+	call.captureTranslatedRequest()
+	call.hook.AfterExecute(ctx, call, resp, err)
+}
+
+// observeStreamChunk runs OnStreamChunk for a chunk about to be forwarded to the caller.
+func observeStreamChunk(ctx context.Context, call *ExecutionCall, chunk cliproxyexecutor.StreamChunk) {
+	if call == nil {
+		return
+	}
+	call.captureTranslatedRequest()
+	call.hook.OnStreamChunk(ctx, call, chunk)
+}
+
+func (c *ExecutionCall) captureTranslatedRequest() {
+	if body := c.upstream.Body(); body != nil {
+		c.TranslatedRequest = body
+	}
+}
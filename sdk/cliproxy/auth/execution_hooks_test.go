@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+type hookTestExecutor struct {
+	mu       sync.Mutex
+	payloads []string
+}
+
+func (e *hookTestExecutor) Identifier() string { return "hooktest" }
+
+func (e *hookTestExecutor) send(ctx context.Context, req cliproxyexecutor.Request) {
+	e.mu.Lock()
+	e.payloads = append(e.payloads, string(req.Payload))
+	e.mu.Unlock()
+	cliproxyexecutor.RecordUpstreamRequest(ctx, append([]byte("upstream:"), req.Payload...))
+}
+
+func (e *hookTestExecutor) Execute(ctx context.Context, _ *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.send(ctx, req)
+	return cliproxyexecutor.Response{Payload: []byte("ok")}, nil
+}
+
+func (e *hookTestExecutor) ExecuteStream(ctx context.Context, _ *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+	e.send(ctx, req)
+	ch := make(chan cliproxyexecutor.StreamChunk, 2)
+	ch <- cliproxyexecutor.StreamChunk{Payload: []byte("a")}
+	ch <- cliproxyexecutor.StreamChunk{Payload: []byte("b")}
+	close(ch)
+	return &cliproxyexecutor.StreamResult{Chunks: ch}, nil
+}
+
+func (e *hookTestExecutor) CountTokens(ctx context.Context, _ *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.send(ctx, req)
+	return cliproxyexecutor.Response{Payload: []byte("1")}, nil
+}
+
+func (e *hookTestExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *hookTestExecutor) HttpRequest(context.Context, *Auth, *http.Request) (*http.Response, error) {
+	return nil, nil
+}
+
+type recordingExecutionHook struct {
+	mu      sync.Mutex
+	before  int
+	after   []string
+	chunks  []string
+	authIDs []string
+}
+
+func (h *recordingExecutionHook) BeforeExecute(_ context.Context, call *ExecutionCall) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.before++
+	h.authIDs = append(h.authIDs, call.Auth.ID)
+	call.Request.Payload = []byte("rewritten")
+	call.State = "state"
+}
+
+func (h *recordingExecutionHook) AfterExecute(_ context.Context, call *ExecutionCall, _ cliproxyexecutor.Response, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err != nil || call.State != "state" {
+		h.after = append(h.after, "unexpected")
+		return
+	}
+	h.after = append(h.after, string(call.TranslatedRequest))
+}
+
+func (h *recordingExecutionHook) OnStreamChunk(_ context.Context, _ *ExecutionCall, chunk cliproxyexecutor.StreamChunk) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.chunks = append(h.chunks, string(chunk.Payload))
+}
+
+func newExecutionHookTestManager(t *testing.T, model string) (*Manager, *hookTestExecutor, *recordingExecutionHook) {
+	t.Helper()
+	executor := &hookTestExecutor{}
+	hook := &recordingExecutionHook{}
+	manager := NewManager(nil, &RoundRobinSelector{}, nil)
+	manager.RegisterExecutor(executor)
+	manager.SetExecutionHook(hook)
+	authID := "hooktest-" + model
+	if _, err := manager.Register(context.Background(), &Auth{ID: authID, Provider: "hooktest"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient(authID, "hooktest", []*registry.ModelInfo{{ID: model}})
+	t.Cleanup(func() { reg.UnregisterClient(authID) })
+	return manager, executor, hook
+}
+
+func TestManagerExecutionHook_Execute(t *testing.T) {
+	manager, executor, hook := newExecutionHookTestManager(t, "hook-exec-model")
+	req := cliproxyexecutor.Request{Model: "hook-exec-model", Payload: []byte("original")}
+	if _, err := manager.Execute(context.Background(), []string{"hooktest"}, req, cliproxyexecutor.Options{}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if _, err := manager.ExecuteCount(context.Background(), []string{"hooktest"}, req, cliproxyexecutor.Options{}); err != nil {
+		t.Fatalf("ExecuteCount() error = %v", err)
+	}
+	if got := executor.payloads; len(got) != 2 || got[0] != "rewritten" || got[1] != "rewritten" {
+		t.Fatalf("executor payloads = %v, want request rewritten by BeforeExecute", got)
+	}
+	if hook.before != 2 || len(hook.after) != 2 {
+		t.Fatalf("before = %d, after = %v, want one pair per call", hook.before, hook.after)
+	}
+	for _, got := range hook.after {
+		if got != "upstream:rewritten" {
+			t.Fatalf("translated request = %q, want %q", got, "upstream:rewritten")
+		}
+	}
+	if hook.authIDs[0] != "hooktest-hook-exec-model" {
+		t.Fatalf("auth = %q", hook.authIDs[0])
+	}
+}
+
+func TestManagerExecutionHook_Stream(t *testing.T) {
+	manager, _, hook := newExecutionHookTestManager(t, "hook-stream-model")
+	result, err := manager.ExecuteStream(context.Background(), []string{"hooktest"}, cliproxyexecutor.Request{Model: "hook-stream-model", Payload: []byte("original")}, cliproxyexecutor.Options{Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	for range result.Chunks {
+	}
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+	if len(hook.chunks) != 2 || hook.chunks[0] != "a" || hook.chunks[1] != "b" {
+		t.Fatalf("observed chunks = %v, want [a b]", hook.chunks)
+	}
+	if len(hook.after) != 1 || hook.after[0] != "upstream:rewritten" {
+		t.Fatalf("after = %v, want one call with the translated request", hook.after)
+	}
+}
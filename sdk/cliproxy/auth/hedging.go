@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+// hedgeDelay returns how long the first credential may go without answering before a hedged
+// attempt is launched for model, or 0 when no hedging rule matches. Streams answer with their
+// first chunk, non-streaming requests with the complete response.
+func (m *Manager) hedgeDelay(model string) time.Duration {
+	if m == nil {
+		return 0
+	}
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil {
+		return 0
+	}
+	model = strings.TrimSpace(model)
+	for _, rule := range cfg.Routing.Hedging {
+		if rule.DelayMS <= 0 {
+			continue
+		}
+		for _, pattern := range rule.Models {
+			if util.MatchModelPattern(pattern, model) {
+				return time.Duration(rule.DelayMS) * time.Millisecond
+			}
+		}
+	}
+	return 0
+}
+
+// hedgeOutcome is the result of one attempt of a hedged request.
+type hedgeOutcome[T any] struct {
+	value T
+	err   error
+	index int
+}
+
+// hedgeSlot tracks a launched attempt so the losers can be cancelled once a winner is known.
+type hedgeSlot struct {
+	authID string
+	cancel context.CancelFunc
+	lose   func()
+	done   bool
+}
+
+// executeHedged runs req on one credential and, when it has not succeeded within delay, launches
+// the same request on a second credential. The first attempt to succeed wins; the other is
+// cancelled and its usage is reported as hedged. A failed attempt is replaced by the next
+// credential, as in the unhedged path. keep receives the winner together with the function that
+// releases its context; when keep is nil the context is released right away. discard receives
+// attempts that succeed after the winner was chosen.
+func executeHedged[T any](ctx context.Context, m *Manager, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, delay time.Duration,
+	run func(context.Context, *Auth, ProviderExecutor, string, cliproxyexecutor.Request, cliproxyexecutor.Options) (T, error),
+	keep func(T, func()), discard func(T)) (T, error) {
+	var zero T
+	routeModel := req.Model
+	opts = ensureRequestedModelMetadata(opts, routeModel)
+	tried := triedFromMetadata(opts.Metadata)
+	results := make(chan hedgeOutcome[T])
+	var slots []*hedgeSlot
+	running := 0
+
+	launch := func() error {
+		auth, executor, provider, errPick := m.selectAuth(ctx, providers, routeModel, opts, tried)
+		if errPick != nil {
+			return errPick
+		}
+		tried[auth.ID] = struct{}{}
+		attemptCtx, cancel := context.WithCancel(ctx)
+		attemptCtx, lose := usage.WithHedgeAttempt(attemptCtx)
+		index := len(slots)
+		slots = append(slots, &hedgeSlot{authID: auth.ID, cancel: cancel, lose: lose})
+		running++
+		attemptOpts := hedgeAttemptOptions(opts)
+		go func() {
+			value, err := run(attemptCtx, auth, executor, provider, req, attemptOpts)
+			results <- hedgeOutcome[T]{value: value, err: err, index: index}
+		}()
+		return nil
+	}
+
+	// settle cancels every attempt except winner (-1 cancels all) and collects the outcomes
+	// still in flight in the background.
+	settle := func(winner int) {
+		for i, slot := range slots {
+			if i == winner || slot.done {
+				continue
+			}
+			slot.lose()
+			slot.cancel()
+		}
+		if running == 0 {
+			return
+		}
+		go func(pending int) {
+			for ; pending > 0; pending-- {
+				out := <-results
+				if out.err == nil && discard != nil {
+					discard(out.value)
+				}
+			}
+		}(running)
+	}
+
+	if errLaunch := launch(); errLaunch != nil {
+		return zero, errLaunch
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	hedged := false
+	var lastErr error
+	for {
+		select {
+		case <-ctx.Done():
+			settle(-1)
+			return zero, ctx.Err()
+		case <-timer.C:
+			if hedged {
+				continue
+			}
+			hedged = true
+			if errLaunch := launch(); errLaunch != nil {
+				logEntryWithRequestID(ctx).Debugf("hedging: no second credential for model %s: %v", routeModel, errLaunch)
+				continue
+			}
+			logEntryWithRequestID(ctx).Debugf("hedging: model %s had no answer after %s, launched a second attempt", routeModel, delay)
+		case out := <-results:
+			running--
+			slot := slots[out.index]
+			slot.done = true
+			if out.err == nil {
+				settle(out.index)
+				publishSelectedAuthMetadata(opts.Metadata, slot.authID)
+				if keep != nil {
+					keep(out.value, slot.cancel)
+				} else {
+					slot.cancel()
+				}
+				return out.value, nil
+			}
+			slot.cancel()
+			lastErr = out.err
+			if isRequestInvalidError(out.err) {
+				settle(-1)
+				return zero, out.err
+			}
+			if running > 0 {
+				continue
+			}
+			if errLaunch := launch(); errLaunch != nil {
+				return zero, lastErr
+			}
+		}
+	}
+}
+
+// hedgeAttemptOptions gives an attempt its own metadata map so concurrent attempts do not race on
+// it. The selection callback is left out; it is invoked once for the winning credential.
+func hedgeAttemptOptions(opts cliproxyexecutor.Options) cliproxyexecutor.Options {
+	if len(opts.Metadata) == 0 {
+		return opts
+	}
+	meta := make(map[string]any, len(opts.Metadata))
+	for k, v := range opts.Metadata {
+		if k == cliproxyexecutor.SelectedAuthCallbackMetadataKey {
+			continue
+		}
+		meta[k] = v
+	}
+	opts.Metadata = meta
+	return opts
+}
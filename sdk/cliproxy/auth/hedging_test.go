@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+type usageCapture chan usage.Record
+
+func (c usageCapture) HandleUsage(_ context.Context, record usage.Record) { c <- record }
+
+// hedgeTestExecutor hangs on the slow auth until its attempt is cancelled, reporting the partial
+// usage it consumed, streams failErr as the first chunk on the failing auth and answers
+// immediately on every other auth.
+type hedgeTestExecutor struct {
+	slow    string
+	failing string
+	failErr error
+	usage   *usage.Manager
+}
+
+func (e *hedgeTestExecutor) Identifier() string { return "hedgetest" }
+
+func (e *hedgeTestExecutor) wait(ctx context.Context, auth *Auth) error {
+	if auth.ID != e.slow {
+		return nil
+	}
+	<-ctx.Done()
+	e.usage.Publish(ctx, usage.Record{AuthID: auth.ID, Detail: usage.Detail{InputTokens: 7}})
+	return ctx.Err()
+}
+
+func (e *hedgeTestExecutor) Execute(ctx context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	if err := e.wait(ctx, auth); err != nil {
+		return cliproxyexecutor.Response{}, err
+	}
+	e.usage.Publish(ctx, usage.Record{AuthID: auth.ID, Detail: usage.Detail{InputTokens: 7, OutputTokens: 3}})
+	return cliproxyexecutor.Response{Payload: []byte(auth.ID)}, nil
+}
+
+func (e *hedgeTestExecutor) ExecuteStream(ctx context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+	ch := make(chan cliproxyexecutor.StreamChunk, 1)
+	go func() {
+		defer close(ch)
+		if err := e.wait(ctx, auth); err != nil {
+			ch <- cliproxyexecutor.StreamChunk{Err: err}
+			return
+		}
+		if auth.ID == e.failing {
+			ch <- cliproxyexecutor.StreamChunk{Err: e.failErr}
+			return
+		}
+		ch <- cliproxyexecutor.StreamChunk{Payload: []byte(auth.ID)}
+	}()
+	return &cliproxyexecutor.StreamResult{Chunks: ch}, nil
+}
+
+func (e *hedgeTestExecutor) CountTokens(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, errors.New("not implemented")
+}
+
+func (e *hedgeTestExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *hedgeTestExecutor) HttpRequest(context.Context, *Auth, *http.Request) (*http.Response, error) {
+	return nil, nil
+}
+
+func newHedgeTestManager(t *testing.T, model string, rules []internalconfig.HedgingRule) (*Manager, usageCapture) {
+	t.Helper()
+	return newHedgeTestManagerWith(t, model, rules, &hedgeTestExecutor{slow: "hedge-a"})
+}
+
+func newHedgeTestManagerWith(t *testing.T, model string, rules []internalconfig.HedgingRule, executor *hedgeTestExecutor) (*Manager, usageCapture) {
+	t.Helper()
+	records := make(usageCapture, 4)
+	usageManager := usage.NewManager(4)
+	usageManager.Register(records)
+	usageManager.Start(context.Background())
+	t.Cleanup(usageManager.Stop)
+
+	manager := NewManager(nil, &FillFirstSelector{}, nil)
+	manager.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{Hedging: rules}})
+	executor.usage = usageManager
+	manager.RegisterExecutor(executor)
+	reg := registry.GetGlobalRegistry()
+	for _, id := range []string{"hedge-a", "hedge-b"} {
+		if _, err := manager.Register(context.Background(), &Auth{ID: id, Provider: "hedgetest"}); err != nil {
+			t.Fatalf("Register(%s) error = %v", id, err)
+		}
+		reg.RegisterClient(id, "hedgetest", []*registry.ModelInfo{{ID: model}})
+		authID := id
+		t.Cleanup(func() { reg.UnregisterClient(authID) })
+	}
+	return manager, records
+}
+
+func expectHedgedUsage(t *testing.T, records usageCapture) {
+	t.Helper()
+	got := map[string]bool{}
+	for len(got) < 2 {
+		select {
+		case record := <-records:
+			got[record.AuthID] = record.Hedged
+		case <-time.After(2 * time.Second):
+			t.Fatalf("usage records = %v, want one per attempt", got)
+		}
+	}
+	if !got["hedge-a"] || got["hedge-b"] {
+		t.Fatalf("hedged flags = %v, want only the losing hedge-a marked", got)
+	}
+}
+
+func TestManagerExecute_HedgesSlowCredential(t *testing.T) {
+	const model = "hedge-exec-model"
+	manager, records := newHedgeTestManager(t, model, []internalconfig.HedgingRule{{Models: []string{"hedge-*"}, DelayMS: 20}})
+
+	selected := ""
+	opts := cliproxyexecutor.Options{Metadata: map[string]any{
+		cliproxyexecutor.SelectedAuthCallbackMetadataKey: func(id string) { selected = id },
+	}}
+	resp, err := manager.Execute(context.Background(), []string{"hedgetest"}, cliproxyexecutor.Request{Model: model}, opts)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if string(resp.Payload) != "hedge-b" {
+		t.Fatalf("payload = %q, want the hedged credential's answer", resp.Payload)
+	}
+	if selected != "hedge-b" {
+		t.Fatalf("selected auth = %q, want the winner only", selected)
+	}
+	expectHedgedUsage(t, records)
+}
+
+func TestManagerExecuteStream_HedgesSlowCredential(t *testing.T) {
+	const model = "hedge-stream-model"
+	manager, records := newHedgeTestManager(t, model, []internalconfig.HedgingRule{{Models: []string{"*-stream-*"}, DelayMS: 20}})
+
+	result, err := manager.ExecuteStream(context.Background(), []string{"hedgetest"}, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{})
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	var got string
+	for chunk := range result.Chunks {
+		if chunk.Err != nil {
+			t.Fatalf("unexpected stream error: %v", chunk.Err)
+		}
+		got += string(chunk.Payload)
+	}
+	if got != "hedge-b" {
+		t.Fatalf("stream payload = %q, want the hedged credential's answer", got)
+	}
+	select {
+	case record := <-records:
+		if record.AuthID != "hedge-a" || !record.Hedged {
+			t.Fatalf("usage record = %+v, want hedged usage of the loser", record)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no usage reported for the cancelled attempt")
+	}
+}
+
+func TestManagerExecuteStream_FirstChunkErrorFailover(t *testing.T) {
+	paths := map[string][]internalconfig.HedgingRule{
+		"unhedged": nil,
+		"hedged":   {{Models: []string{"hedge-first-chunk-*"}, DelayMS: 10000}},
+	}
+	tests := []struct {
+		name     string
+		err      error
+		wantText string
+		wantErr  int
+	}{
+		{name: "server error fails over", err: &Error{HTTPStatus: http.StatusBadGateway, Message: "bad gateway"}, wantText: "hedge-b"},
+		{name: "client error is forwarded", err: &Error{HTTPStatus: http.StatusBadRequest, Message: "bad request"}, wantErr: http.StatusBadRequest},
+	}
+	for path, rules := range paths {
+		for _, tt := range tests {
+			t.Run(path+"/"+tt.name, func(t *testing.T) {
+				model := "hedge-first-chunk-" + path
+				manager, _ := newHedgeTestManagerWith(t, model, rules, &hedgeTestExecutor{failing: "hedge-a", failErr: tt.err})
+
+				result, err := manager.ExecuteStream(context.Background(), []string{"hedgetest"}, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{})
+				if err != nil {
+					t.Fatalf("ExecuteStream() error = %v", err)
+				}
+				var got string
+				gotErr := 0
+				for chunk := range result.Chunks {
+					if chunk.Err != nil {
+						gotErr = statusCodeFromError(chunk.Err)
+						continue
+					}
+					got += string(chunk.Payload)
+				}
+				if got != tt.wantText || gotErr != tt.wantErr {
+					t.Fatalf("stream = %q with error status %d, want %q with error status %d", got, gotErr, tt.wantText, tt.wantErr)
+				}
+			})
+		}
+	}
+}
+
+func TestManagerHedgeDelay(t *testing.T) {
+	manager := NewManager(nil, &RoundRobinSelector{}, nil)
+	manager.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{Hedging: []internalconfig.HedgingRule{
+		{Models: []string{"gpt-4o-mini"}, DelayMS: 0},
+		{Models: []string{"gpt-*-mini", "*flash*"}, DelayMS: 300},
+	}}})
+	cases := map[string]time.Duration{
+		"gpt-4o-mini":      300 * time.Millisecond,
+		"Gemini-2.5-Flash": 300 * time.Millisecond,
+		"claude-sonnet-4":  0,
+	}
+	for model, want := range cases {
+		if got := manager.hedgeDelay(model); got != want {
+			t.Errorf("hedgeDelay(%q) = %s, want %s", model, got, want)
+		}
+	}
+}
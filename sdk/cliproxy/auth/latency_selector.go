@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+const (
+	// latencyEWMAAlpha controls how quickly the time-to-first-byte average follows new samples.
+	latencyEWMAAlpha = 0.3
+	// failureEWMAAlpha controls how quickly the failure rate average follows new samples.
+	failureEWMAAlpha = 0.2
+	// latencyFloor prevents near-zero latencies from producing unbounded weights.
+	latencyFloor = 50 * time.Millisecond
+	// failurePenalty scales how strongly the failure rate inflates the effective latency.
+	failurePenalty = 4.0
+)
+
+// LeastLatencySelector weights credential picks by the observed time-to-first-byte and
+// failure rate of each auth for the requested model. Statistics are kept as exponentially
+// weighted moving averages fed from Manager.MarkResult via the ResultObserver interface.
+// Only the highest available priority tier is considered, matching the other selectors.
+type LeastLatencySelector struct {
+	mu      sync.Mutex
+	stats   map[string]*latencyStats
+	maxKeys int
+	// random returns a value in [0, 1); nil falls back to math/rand/v2.
+	random func() float64
+}
+
+type latencyStats struct {
+	latency  float64
+	failures float64
+	samples  int
+}
+
+// Pick selects an auth with probability proportional to its configured weight and inversely
+// proportional to its effective latency, defined as the latency average inflated by the
+// failure rate. Auths without latency samples use the mean of their peers so new credentials
+// receive traffic and get measured.
+func (s *LeastLatencySelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = opts
+	now := time.Now()
+	available, err := getAvailableAuths(auths, provider, model, now)
+	if err != nil {
+		return nil, err
+	}
+	available = preferCodexWebsocketAuths(ctx, provider, available)
+	if len(available) == 1 {
+		return available[0], nil
+	}
+
+	modelKey := canonicalModelKey(model)
+	snapshot := make([]latencyStats, len(available))
+	var sum float64
+	var count int
+
+	s.mu.Lock()
+	for i := range available {
+		if stats := s.stats[latencyStatsKey(available[i].ID, modelKey)]; stats != nil {
+			snapshot[i] = *stats
+			if stats.latency > 0 {
+				sum += stats.latency
+				count++
+			}
+		}
+	}
+	random := s.random
+	s.mu.Unlock()
+
+	fallback := float64(latencyFloor.Milliseconds())
+	if count > 0 {
+		fallback = sum / float64(count)
+	}
+	weights := make([]float64, len(available))
+	var total float64
+	for i := range available {
+		weights[i] = float64(authWeight(available[i])) / effectiveLatency(snapshot[i], fallback)
+		total += weights[i]
+	}
+
+	if random == nil {
+		random = rand.Float64
+	}
+	target := random() * total
+	for i := range available {
+		target -= weights[i]
+		if target < 0 {
+			return available[i], nil
+		}
+	}
+	return available[len(available)-1], nil
+}
+
+// ObserveResult implements ResultObserver by folding the result into the auth/model averages.
+func (s *LeastLatencySelector) ObserveResult(result Result) {
+	authID := strings.TrimSpace(result.AuthID)
+	if authID == "" {
+		return
+	}
+	failed := !result.Success
+	if failed && !isCredentialFailure(result.Error) {
+		return
+	}
+	key := latencyStatsKey(authID, canonicalModelKey(result.Model))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stats == nil {
+		s.stats = make(map[string]*latencyStats)
+	}
+	stats := s.stats[key]
+	if stats == nil {
+		limit := s.maxKeys
+		if limit <= 0 {
+			limit = 4096
+		}
+		if len(s.stats) >= limit {
+			s.stats = make(map[string]*latencyStats)
+		}
+		stats = &latencyStats{}
+		s.stats[key] = stats
+	}
+
+	failure := 0.0
+	if failed {
+		failure = 1
+	}
+	if stats.samples == 0 {
+		stats.failures = failure
+	} else {
+		stats.failures += failureEWMAAlpha * (failure - stats.failures)
+	}
+	// Failed attempts tend to return quickly, so only successes update the latency average.
+	if !failed && result.Latency > 0 {
+		sample := float64(max(result.Latency, latencyFloor).Milliseconds())
+		if stats.latency == 0 {
+			stats.latency = sample
+		} else {
+			stats.latency += latencyEWMAAlpha * (sample - stats.latency)
+		}
+	}
+	stats.samples++
+}
+
+func latencyStatsKey(authID, model string) string {
+	return authID + "|" + model
+}
+
+func effectiveLatency(stats latencyStats, fallback float64) float64 {
+	latency := stats.latency
+	if latency <= 0 {
+		latency = fallback
+	}
+	if floor := float64(latencyFloor.Milliseconds()); latency < floor {
+		latency = floor
+	}
+	return latency * (1 + failurePenalty*stats.failures)
+}
+
+// isCredentialFailure reports whether a failed result reflects on the credential itself
+// rather than on the client request.
+func isCredentialFailure(err *Error) bool {
+	switch statusCodeFromResult(err) {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"strconv"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/metrics"
+)
+
+// resultMetricLabel classifies an execution result for the upstream request counter.
+func resultMetricLabel(result Result) string {
+	if result.Success {
+		return metrics.ResultSuccess
+	}
+	if result.Error != nil && result.Error.HTTPStatus > 0 {
+		return strconv.Itoa(result.Error.HTTPStatus)
+	}
+	return "error"
+}
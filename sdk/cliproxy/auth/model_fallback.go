@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// executeWithModelFallback runs exec for the requested model and, when every credential for it
+// is exhausted, walks the configured model-fallbacks chain in order. Fallback models are routed
+// to their own providers; executors translate the original request into the fallback provider's
+// format. Models outside the client's allow-list are skipped. Chains are not followed
+// recursively. When the whole chain fails, the error for the requested model is returned so
+// clients still see its cooldown details.
+func executeWithModelFallback[T any](ctx context.Context, m *Manager, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, exec func(context.Context, []string, cliproxyexecutor.Request, cliproxyexecutor.Options) (T, error)) (T, error) {
+	out, err := exec(ctx, providers, req, opts)
+	if err == nil || !m.isModelExhaustedError(err, providers, req.Model) || pinnedAuthIDFromMetadata(opts.Metadata) != "" {
+		return out, err
+	}
+	allowedModels, _ := opts.Metadata[cliproxyexecutor.AllowedModelsMetadataKey].([]string)
+	for _, model := range m.modelFallbackChain(req.Model) {
+		if ctx != nil && ctx.Err() != nil {
+			return out, err
+		}
+		if !fallbackModelAllowed(allowedModels, model) {
+			continue
+		}
+		fallbackProviders := m.normalizeProviders(util.GetProviderName(canonicalModelKey(model)))
+		if len(fallbackProviders) == 0 {
+			continue
+		}
+		fallbackReq := req
+		fallbackReq.Model = model
+		result, errFallback := exec(ctx, fallbackProviders, fallbackReq, modelFallbackOptions(opts, model))
+		if errFallback == nil {
+			logEntryWithRequestID(ctx).Infof("model %s exhausted, served by fallback model %s", req.Model, model)
+			publishServedModelMetadata(opts.Metadata, model)
+			return result, nil
+		}
+		if isRequestInvalidError(errFallback) {
+			return result, errFallback
+		}
+	}
+	return out, err
+}
+
+// isModelExhaustedError reports whether err means no credential can serve the model within the
+// request retry budget (max-retry-interval). Short rate limits that clear within the budget are
+// left to the retry loop rather than moving the request to another model.
+func (m *Manager) isModelExhaustedError(err error, providers []string, model string) bool {
+	_, maxWait := m.retrySettings()
+	if cooldown, ok := errors.AsType[*modelCooldownError](err); ok {
+		return cooldown.resetIn > maxWait
+	}
+	if statusCodeFromError(err) != http.StatusTooManyRequests {
+		return false
+	}
+	if retryAfter := retryAfterFromError(err); retryAfter != nil {
+		return *retryAfter > maxWait
+	}
+	if isQuotaExhaustedMessage(err.Error()) {
+		return true
+	}
+	wait, blocked := m.modelRecoveryWait(providers, model)
+	return blocked && wait > maxWait
+}
+
+// isQuotaExhaustedMessage reports whether a 429 body names an exhausted quota rather than a
+// short-lived rate limit.
+func isQuotaExhaustedMessage(message string) bool {
+	message = strings.ToLower(message)
+	for _, marker := range []string{"insufficient_quota", "resource_exhausted", "quota exceeded", "quota_exceeded", "exceeded your current quota", "usage limit"} {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// modelRecoveryWait returns how long it takes until the first credential of providers can serve
+// model again. It reports false when a credential is available now or none is cooling down.
+func (m *Manager) modelRecoveryWait(providers []string, model string) (time.Duration, bool) {
+	if m == nil {
+		return 0, false
+	}
+	providerSet := make(map[string]struct{}, len(providers))
+	for _, provider := range providers {
+		providerSet[strings.ToLower(strings.TrimSpace(provider))] = struct{}{}
+	}
+	modelKey := thinking.ParseSuffix(strings.TrimSpace(model)).ModelName
+	registryRef := registry.GetGlobalRegistry()
+	now := time.Now()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var (
+		found   bool
+		minWait time.Duration
+	)
+	for _, auth := range m.auths {
+		if auth == nil || auth.Disabled {
+			continue
+		}
+		if _, ok := providerSet[strings.ToLower(strings.TrimSpace(auth.Provider))]; !ok {
+			continue
+		}
+		if modelKey != "" && !registryRef.ClientSupportsModel(auth.ID, modelKey) {
+			continue
+		}
+		blocked, reason, next := isAuthBlockedForModel(auth, model, now)
+		if reason == blockReasonDisabled {
+			continue
+		}
+		if !blocked || next.IsZero() {
+			return 0, false
+		}
+		if wait := next.Sub(now); !found || wait < minWait {
+			minWait = wait
+			found = true
+		}
+	}
+	return minWait, found
+}
+
+// fallbackModelAllowed reports whether the client's model allow-list, when present, admits the
+// fallback model. As for the requested model, the thinking suffix is ignored.
+func fallbackModelAllowed(allowed []string, model string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	base := thinking.ParseSuffix(model).ModelName
+	for _, pattern := range allowed {
+		if util.MatchModelPattern(pattern, model) || (base != "" && util.MatchModelPattern(pattern, base)) {
+			return true
+		}
+	}
+	return false
+}
+
+// modelFallbackChain returns the configured fallback models for model. The thinking suffix of
+// the requested model is carried over to fallback entries that do not specify their own.
+func (m *Manager) modelFallbackChain(model string) []string {
+	if m == nil {
+		return nil
+	}
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || len(cfg.ModelFallbacks) == 0 {
+		return nil
+	}
+	model = strings.TrimSpace(model)
+	parsed := thinking.ParseSuffix(model)
+	chain, ok := cfg.ModelFallbacks[model]
+	if !ok {
+		chain, ok = cfg.ModelFallbacks[parsed.ModelName]
+	}
+	if !ok {
+		for key, entries := range cfg.ModelFallbacks {
+			if strings.EqualFold(key, model) || strings.EqualFold(key, parsed.ModelName) {
+				chain = entries
+				break
+			}
+		}
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(chain))
+	for _, entry := range chain {
+		if parsed.HasSuffix && !thinking.ParseSuffix(entry).HasSuffix {
+			entry = entry + "(" + parsed.RawSuffix + ")"
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// modelFallbackOptions clones opts.Metadata so the fallback attempt sees its own requested model
+// without mutating the caller's metadata.
+func modelFallbackOptions(opts cliproxyexecutor.Options, model string) cliproxyexecutor.Options {
+	meta := make(map[string]any, len(opts.Metadata)+1)
+	for k, v := range opts.Metadata {
+		meta[k] = v
+	}
+	meta[cliproxyexecutor.RequestedModelMetadataKey] = model
+	opts.Metadata = meta
+	return opts
+}
+
+func publishServedModelMetadata(meta map[string]any, model string) {
+	if meta == nil {
+		return
+	}
+	meta[cliproxyexecutor.ServedModelMetadataKey] = model
+}
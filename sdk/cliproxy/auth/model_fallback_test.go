@@ -0,0 +1,240 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+type fallbackTestExecutor struct {
+	id      string
+	status  int
+	message string
+
+	mu     sync.Mutex
+	models []string
+}
+
+func (e *fallbackTestExecutor) Identifier() string { return e.id }
+
+func (e *fallbackTestExecutor) record(model string) error {
+	e.mu.Lock()
+	e.models = append(e.models, model)
+	e.mu.Unlock()
+	if e.status != 0 {
+		message := e.message
+		if message == "" {
+			message = "upstream failure"
+		}
+		return &Error{HTTPStatus: e.status, Message: message}
+	}
+	return nil
+}
+
+func (e *fallbackTestExecutor) Execute(_ context.Context, _ *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	if err := e.record(req.Model); err != nil {
+		return cliproxyexecutor.Response{}, err
+	}
+	return cliproxyexecutor.Response{Payload: []byte(e.id)}, nil
+}
+
+func (e *fallbackTestExecutor) ExecuteStream(_ context.Context, _ *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (*cliproxyexecutor.StreamResult, error) {
+	if err := e.record(req.Model); err != nil {
+		return nil, err
+	}
+	ch := make(chan cliproxyexecutor.StreamChunk, 1)
+	ch <- cliproxyexecutor.StreamChunk{Payload: []byte(e.id)}
+	close(ch)
+	return &cliproxyexecutor.StreamResult{Chunks: ch}, nil
+}
+
+func (e *fallbackTestExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) {
+	return auth, nil
+}
+
+func (e *fallbackTestExecutor) CountTokens(_ context.Context, _ *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	if err := e.record(req.Model); err != nil {
+		return cliproxyexecutor.Response{}, err
+	}
+	return cliproxyexecutor.Response{Payload: []byte(e.id)}, nil
+}
+
+func (e *fallbackTestExecutor) HttpRequest(context.Context, *Auth, *http.Request) (*http.Response, error) {
+	return nil, nil
+}
+
+func (e *fallbackTestExecutor) Models() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.models...)
+}
+
+func newModelFallbackTestManager(t *testing.T, primaryModel, fallbackModel string, primaryStatus int) (*Manager, *fallbackTestExecutor, *fallbackTestExecutor) {
+	t.Helper()
+
+	primary := &fallbackTestExecutor{id: "claude", status: primaryStatus}
+	fallback := &fallbackTestExecutor{id: "gemini"}
+	manager := NewManager(nil, &RoundRobinSelector{}, nil)
+	manager.SetConfig(&internalconfig.Config{
+		ModelFallbacks: map[string][]string{primaryModel: {fallbackModel}},
+	})
+	manager.RegisterExecutor(primary)
+	manager.RegisterExecutor(fallback)
+
+	reg := registry.GetGlobalRegistry()
+	for _, auth := range []*Auth{
+		{ID: "fallback-" + primaryModel, Provider: "claude"},
+		{ID: "fallback-" + fallbackModel, Provider: "gemini"},
+	} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("Register(%s) error = %v", auth.ID, err)
+		}
+	}
+	reg.RegisterClient("fallback-"+primaryModel, "claude", []*registry.ModelInfo{{ID: primaryModel}})
+	reg.RegisterClient("fallback-"+fallbackModel, "gemini", []*registry.ModelInfo{{ID: fallbackModel}})
+	t.Cleanup(func() {
+		reg.UnregisterClient("fallback-" + primaryModel)
+		reg.UnregisterClient("fallback-" + fallbackModel)
+	})
+	return manager, primary, fallback
+}
+
+func TestManagerExecute_ModelFallbackOnExhaustion(t *testing.T) {
+	t.Parallel()
+
+	manager, primary, fallback := newModelFallbackTestManager(t, "mf-exec-primary", "mf-exec-fallback", http.StatusTooManyRequests)
+	meta := map[string]any{}
+	resp, err := manager.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "mf-exec-primary"}, cliproxyexecutor.Options{Metadata: meta})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if string(resp.Payload) != "gemini" {
+		t.Fatalf("Execute() payload = %q, want %q", resp.Payload, "gemini")
+	}
+	if got := primary.Models(); len(got) != 1 || got[0] != "mf-exec-primary" {
+		t.Fatalf("primary models = %v, want [mf-exec-primary]", got)
+	}
+	if got := fallback.Models(); len(got) != 1 || got[0] != "mf-exec-fallback" {
+		t.Fatalf("fallback models = %v, want [mf-exec-fallback]", got)
+	}
+	if got := meta[cliproxyexecutor.ServedModelMetadataKey]; got != "mf-exec-fallback" {
+		t.Fatalf("served model = %v, want %q", got, "mf-exec-fallback")
+	}
+	if got := meta[cliproxyexecutor.RequestedModelMetadataKey]; got != nil {
+		t.Fatalf("caller metadata requested model = %v, want untouched", got)
+	}
+}
+
+func TestManagerExecuteStream_ModelFallbackOnExhaustion(t *testing.T) {
+	t.Parallel()
+
+	manager, _, _ := newModelFallbackTestManager(t, "mf-stream-primary", "mf-stream-fallback", http.StatusTooManyRequests)
+	meta := map[string]any{}
+	result, err := manager.ExecuteStream(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "mf-stream-primary"}, cliproxyexecutor.Options{Stream: true, Metadata: meta})
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	var payload []byte
+	for chunk := range result.Chunks {
+		payload = append(payload, chunk.Payload...)
+	}
+	if string(payload) != "gemini" {
+		t.Fatalf("ExecuteStream() payload = %q, want %q", payload, "gemini")
+	}
+	if got := meta[cliproxyexecutor.ServedModelMetadataKey]; got != "mf-stream-fallback" {
+		t.Fatalf("served model = %v, want %q", got, "mf-stream-fallback")
+	}
+}
+
+func TestManagerExecute_ModelFallbackSkippedForOtherErrors(t *testing.T) {
+	t.Parallel()
+
+	manager, _, fallback := newModelFallbackTestManager(t, "mf-other-primary", "mf-other-fallback", http.StatusInternalServerError)
+	_, err := manager.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "mf-other-primary"}, cliproxyexecutor.Options{})
+	if err == nil {
+		t.Fatal("Execute() error = nil, want upstream failure")
+	}
+	if got := fallback.Models(); len(got) != 0 {
+		t.Fatalf("fallback models = %v, want none", got)
+	}
+}
+
+func TestManagerExecuteCount_ModelFallbackOnExhaustion(t *testing.T) {
+	t.Parallel()
+
+	manager, _, _ := newModelFallbackTestManager(t, "mf-count-primary", "mf-count-fallback", http.StatusTooManyRequests)
+	resp, err := manager.ExecuteCount(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "mf-count-primary"}, cliproxyexecutor.Options{})
+	if err != nil {
+		t.Fatalf("ExecuteCount() error = %v", err)
+	}
+	if string(resp.Payload) != "gemini" {
+		t.Fatalf("ExecuteCount() payload = %q, want %q", resp.Payload, "gemini")
+	}
+}
+
+func TestManagerExecute_ModelFallbackSkippedForShortRateLimit(t *testing.T) {
+	t.Parallel()
+
+	manager, _, fallback := newModelFallbackTestManager(t, "mf-short-primary", "mf-short-fallback", http.StatusTooManyRequests)
+	manager.SetRetryConfig(0, 30*time.Second)
+	_, err := manager.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "mf-short-primary"}, cliproxyexecutor.Options{})
+	if err == nil {
+		t.Fatal("Execute() error = nil, want the rate limit")
+	}
+	if got := fallback.Models(); len(got) != 0 {
+		t.Fatalf("fallback models = %v, want none for a rate limit within the retry budget", got)
+	}
+}
+
+func TestManagerExecute_ModelFallbackOnQuotaExhaustedWithinRetryBudget(t *testing.T) {
+	t.Parallel()
+
+	manager, primary, fallback := newModelFallbackTestManager(t, "mf-quota-primary", "mf-quota-fallback", http.StatusTooManyRequests)
+	primary.message = `{"error":{"type":"insufficient_quota","message":"You exceeded your current quota"}}`
+	manager.SetRetryConfig(0, 30*time.Second)
+	if _, err := manager.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "mf-quota-primary"}, cliproxyexecutor.Options{}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got := fallback.Models(); len(got) != 1 {
+		t.Fatalf("fallback models = %v, want the quota error to fall back", got)
+	}
+}
+
+func TestManagerModelFallbackChain_CarriesThinkingSuffix(t *testing.T) {
+	t.Parallel()
+
+	manager := NewManager(nil, nil, nil)
+	manager.SetConfig(&internalconfig.Config{
+		ModelFallbacks: map[string][]string{"claude-opus-4-5": {"gpt-5", "gemini-2.5-pro(8192)"}},
+	})
+
+	got := manager.modelFallbackChain("claude-opus-4-5(high)")
+	want := []string{"gpt-5(high)", "gemini-2.5-pro(8192)"}
+	if len(got) != len(want) {
+		t.Fatalf("modelFallbackChain() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("modelFallbackChain()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestManagerExecute_ModelFallbackHonoursClientAllowList(t *testing.T) {
+	t.Parallel()
+
+	manager, _, fallback := newModelFallbackTestManager(t, "mf-allow-primary", "mf-allow-fallback", http.StatusTooManyRequests)
+	meta := map[string]any{cliproxyexecutor.AllowedModelsMetadataKey: []string{"mf-allow-primary"}}
+	if _, err := manager.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "mf-allow-primary"}, cliproxyexecutor.Options{Metadata: meta}); err == nil {
+		t.Fatal("Execute() error = nil, want the exhaustion error")
+	}
+	if got := fallback.Models(); len(got) != 0 {
+		t.Fatalf("fallback models = %v, want none outside the allow-list", got)
+	}
+}
@@ -121,6 +121,22 @@ func authPriority(auth *Auth) int {
 	return parsed
 }
 
+// authWeight returns the relative traffic share configured for the auth, defaulting to 1.
+func authWeight(auth *Auth) int {
+	if auth == nil || auth.Attributes == nil {
+		return 1
+	}
+	raw := strings.TrimSpace(auth.Attributes["weight"])
+	if raw == "" {
+		return 1
+	}
+	parsed, err := strconv.Atoi(raw)
+	if err != nil || parsed <= 0 {
+		return 1
+	}
+	return parsed
+}
+
 func canonicalModelKey(model string) string {
 	model = strings.TrimSpace(model)
 	if model == "" {
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 )
 
@@ -402,3 +403,171 @@ func TestRoundRobinSelectorPick_CursorKeyCap(t *testing.T) {
 		t.Fatalf("selector.cursors missing key %q", "gemini:m3")
 	}
 }
+
+func TestLeastLatencySelectorPick_PrefersFasterAuth(t *testing.T) {
+	t.Parallel()
+
+	draws := []float64{0.5}
+	selector := &LeastLatencySelector{random: func() float64 { return draws[0] }}
+	model := "test-model"
+	for i := 0; i < 5; i++ {
+		selector.ObserveResult(Result{AuthID: "slow", Model: model, Success: true, Latency: 4 * time.Second})
+		selector.ObserveResult(Result{AuthID: "fast", Model: model, Success: true, Latency: 200 * time.Millisecond})
+	}
+	auths := []*Auth{{ID: "slow"}, {ID: "fast"}}
+
+	got, err := selector.Pick(context.Background(), "claude", model, cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got == nil || got.ID != "fast" {
+		t.Fatalf("Pick() auth = %v, want %q", got, "fast")
+	}
+
+	// Only a draw in the slow auth's small weight share should select it.
+	draws[0] = 0.99
+	got, err = selector.Pick(context.Background(), "claude", model, cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got == nil || got.ID != "slow" {
+		t.Fatalf("Pick() auth = %v, want %q", got, "slow")
+	}
+}
+
+func TestLeastLatencySelectorPick_PenalizesFailures(t *testing.T) {
+	t.Parallel()
+
+	selector := &LeastLatencySelector{random: func() float64 { return 0.5 }}
+	model := "test-model(high)"
+	selector.ObserveResult(Result{AuthID: "a", Model: model, Success: true, Latency: 300 * time.Millisecond})
+	selector.ObserveResult(Result{AuthID: "b", Model: model, Success: true, Latency: 300 * time.Millisecond})
+	for i := 0; i < 5; i++ {
+		selector.ObserveResult(Result{AuthID: "a", Model: model, Success: false, Error: &Error{HTTPStatus: http.StatusBadGateway}})
+		// Client errors do not count against the credential.
+		selector.ObserveResult(Result{AuthID: "b", Model: model, Success: false, Error: &Error{HTTPStatus: http.StatusBadRequest}})
+	}
+
+	got, err := selector.Pick(context.Background(), "mixed", "test-model", cliproxyexecutor.Options{}, []*Auth{{ID: "a"}, {ID: "b"}})
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got == nil || got.ID != "b" {
+		t.Fatalf("Pick() auth = %v, want %q", got, "b")
+	}
+}
+
+func TestManagerExecuteCount_LeavesLatencyAverageUntouched(t *testing.T) {
+	t.Parallel()
+
+	selector := &LeastLatencySelector{}
+	manager := NewManager(nil, selector, nil)
+	manager.RegisterExecutor(&fallbackTestExecutor{id: "claude"})
+	if _, err := manager.Register(context.Background(), &Auth{ID: "count-auth", Provider: "claude"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient("count-auth", "claude", []*registry.ModelInfo{{ID: "count-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient("count-auth") })
+
+	if _, err := manager.ExecuteCount(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "count-model"}, cliproxyexecutor.Options{}); err != nil {
+		t.Fatalf("ExecuteCount() error = %v", err)
+	}
+	selector.mu.Lock()
+	stats := selector.stats[latencyStatsKey("count-auth", canonicalModelKey("count-model"))]
+	selector.mu.Unlock()
+	if stats == nil || stats.samples != 1 {
+		t.Fatalf("stats = %+v, want one observed result", stats)
+	}
+	if stats.latency != 0 {
+		t.Fatalf("latency average = %v, want count requests left out", stats.latency)
+	}
+}
+
+func TestLeastLatencySelectorPick_HonoursPriority(t *testing.T) {
+	t.Parallel()
+
+	selector := &LeastLatencySelector{random: func() float64 { return 0 }}
+	model := "test-model"
+	selector.ObserveResult(Result{AuthID: "low", Model: model, Success: true, Latency: 100 * time.Millisecond})
+	selector.ObserveResult(Result{AuthID: "high", Model: model, Success: true, Latency: 5 * time.Second})
+	auths := []*Auth{
+		{ID: "low", Attributes: map[string]string{"priority": "0"}},
+		{ID: "high", Attributes: map[string]string{"priority": "10"}},
+	}
+
+	got, err := selector.Pick(context.Background(), "mixed", model, cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got == nil || got.ID != "high" {
+		t.Fatalf("Pick() auth = %v, want %q", got, "high")
+	}
+}
+
+func TestManagerMarkResult_NotifiesResultObserver(t *testing.T) {
+	t.Parallel()
+
+	selector := &LeastLatencySelector{}
+	manager := NewManager(nil, selector, nil)
+	if _, err := manager.Register(context.Background(), &Auth{ID: "a", Provider: "gemini"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	manager.MarkResult(context.Background(), Result{AuthID: "a", Provider: "gemini", Model: "test-model", Success: true, Latency: time.Second})
+
+	selector.mu.Lock()
+	defer selector.mu.Unlock()
+	stats := selector.stats[latencyStatsKey("a", "test-model")]
+	if stats == nil || stats.samples != 1 {
+		t.Fatalf("selector stats = %+v, want one sample", stats)
+	}
+	if stats.latency != 1000 {
+		t.Fatalf("selector latency = %v, want %v", stats.latency, 1000)
+	}
+}
+
+func TestWeightedSelectorPick_SplitsByWeight(t *testing.T) {
+	t.Parallel()
+
+	auths := []*Auth{
+		{ID: "a", Attributes: map[string]string{"weight": "7"}},
+		{ID: "b", Attributes: map[string]string{"weight": "3"}},
+	}
+	cases := []struct {
+		draw float64
+		want string
+	}{
+		{draw: 0, want: "a"},
+		{draw: 0.69, want: "a"},
+		{draw: 0.7, want: "b"},
+		{draw: 0.99, want: "b"},
+	}
+	for _, tc := range cases {
+		selector := &WeightedSelector{random: func() float64 { return tc.draw }}
+		got, err := selector.Pick(context.Background(), "claude", "claude-sonnet", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("Pick(draw=%v) error = %v", tc.draw, err)
+		}
+		if got == nil || got.ID != tc.want {
+			t.Fatalf("Pick(draw=%v) auth = %v, want %q", tc.draw, got, tc.want)
+		}
+	}
+}
+
+func TestWeightedSelectorPick_HonoursPriorityAndDefaultsWeight(t *testing.T) {
+	t.Parallel()
+
+	selector := &WeightedSelector{random: func() float64 { return 0.6 }}
+	auths := []*Auth{
+		{ID: "heavy-low", Attributes: map[string]string{"priority": "0", "weight": "100"}},
+		{ID: "a", Attributes: map[string]string{"priority": "5"}},
+		{ID: "b", Attributes: map[string]string{"priority": "5", "weight": "invalid"}},
+	}
+
+	got, err := selector.Pick(context.Background(), "mixed", "", cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got == nil || got.ID != "b" {
+		t.Fatalf("Pick() auth = %v, want %q", got, "b")
+	}
+}
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	defaultSessionAffinityTTL    = time.Hour
+	defaultSessionAffinityHeader = "X-Session-ID"
+	sessionAffinityMaxEntries    = 16384
+)
+
+type sessionBinding struct {
+	authID  string
+	expires time.Time
+}
+
+// sessionAffinity pins conversation keys to the auth that served them so that
+// upstream prompt caches stay warm across turns.
+type sessionAffinity struct {
+	mu       sync.Mutex
+	bindings map[string]sessionBinding
+}
+
+// preferred returns the bound auth for key when it is still among the candidates and
+// not blocked for the model. Expired, missing or cooling-down bindings yield nil so the
+// caller falls back to the regular selector.
+func (s *sessionAffinity) preferred(key, model string, candidates []*Auth, now time.Time) *Auth {
+	s.mu.Lock()
+	binding, ok := s.bindings[key]
+	if ok && !binding.expires.After(now) {
+		delete(s.bindings, key)
+		ok = false
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	for _, candidate := range candidates {
+		if candidate == nil || candidate.ID != binding.authID {
+			continue
+		}
+		if blocked, _, _ := isAuthBlockedForModel(candidate, model, now); blocked {
+			return nil
+		}
+		return candidate
+	}
+	return nil
+}
+
+// bind records (or refreshes) the binding between key and authID.
+func (s *sessionAffinity) bind(key, authID string, ttl time.Duration, now time.Time) {
+	if key == "" || authID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bindings == nil {
+		s.bindings = make(map[string]sessionBinding)
+	}
+	if _, exists := s.bindings[key]; !exists && len(s.bindings) >= sessionAffinityMaxEntries {
+		for k, v := range s.bindings {
+			if !v.expires.After(now) {
+				delete(s.bindings, k)
+			}
+		}
+		if len(s.bindings) >= sessionAffinityMaxEntries {
+			s.bindings = make(map[string]sessionBinding)
+		}
+	}
+	s.bindings[key] = sessionBinding{authID: authID, expires: now.Add(ttl)}
+}
+
+// sessionAffinityKey returns the affinity key for the request and the binding TTL.
+// An empty key means session affinity is disabled or no session identifier was found.
+func (m *Manager) sessionAffinityKey(model string, opts cliproxyexecutor.Options) (string, time.Duration) {
+	if m == nil {
+		return "", 0
+	}
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || !cfg.Routing.SessionAffinity.Enable {
+		return "", 0
+	}
+	settings := cfg.Routing.SessionAffinity
+	sessionID := sessionIDFromRequest(settings.Header, opts)
+	if sessionID == "" {
+		return "", 0
+	}
+	ttl := defaultSessionAffinityTTL
+	if settings.TTLSeconds > 0 {
+		ttl = time.Duration(settings.TTLSeconds) * time.Second
+	}
+	return sessionID + "|" + canonicalModelKey(model), ttl
+}
+
+// sessionIDFromRequest extracts a conversation identifier from the configured header,
+// the Claude metadata.user_id field, or the OpenAI prompt_cache_key field.
+func sessionIDFromRequest(header string, opts cliproxyexecutor.Options) string {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		header = defaultSessionAffinityHeader
+	}
+	if opts.Headers != nil {
+		if value := strings.TrimSpace(opts.Headers.Get(header)); value != "" {
+			return "header:" + value
+		}
+	}
+	if len(opts.OriginalRequest) == 0 {
+		return ""
+	}
+	if value := strings.TrimSpace(gjson.GetBytes(opts.OriginalRequest, "metadata.user_id").String()); value != "" {
+		return "user:" + value
+	}
+	if value := strings.TrimSpace(gjson.GetBytes(opts.OriginalRequest, "prompt_cache_key").String()); value != "" {
+		return "cache:" + value
+	}
+	return ""
+}
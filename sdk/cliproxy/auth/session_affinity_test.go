@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func newSessionAffinityTestManager(t *testing.T, enable bool) *Manager {
+	t.Helper()
+
+	manager := NewManager(nil, &RoundRobinSelector{}, nil)
+	manager.SetConfig(&internalconfig.Config{
+		Routing: internalconfig.RoutingConfig{
+			SessionAffinity: internalconfig.SessionAffinityConfig{Enable: enable},
+		},
+	})
+	manager.RegisterExecutor(&replaceAwareExecutor{id: "claude"})
+	for _, id := range []string{"a", "b", "c"} {
+		if _, err := manager.Register(context.Background(), &Auth{ID: id, Provider: "claude"}); err != nil {
+			t.Fatalf("Register(%s) error = %v", id, err)
+		}
+	}
+	return manager
+}
+
+func pickAuthID(t *testing.T, manager *Manager, opts cliproxyexecutor.Options) string {
+	t.Helper()
+
+	auth, _, _, err := manager.pickNextMixed(context.Background(), []string{"claude"}, "", opts, map[string]struct{}{})
+	if err != nil {
+		t.Fatalf("pickNextMixed() error = %v", err)
+	}
+	return auth.ID
+}
+
+func TestPickNextMixed_SessionAffinityPinsConversation(t *testing.T) {
+	t.Parallel()
+
+	manager := newSessionAffinityTestManager(t, true)
+	headers := http.Header{}
+	headers.Set("X-Session-ID", "conversation-1")
+	opts := cliproxyexecutor.Options{Headers: headers}
+
+	first := pickAuthID(t, manager, opts)
+	for i := 0; i < 5; i++ {
+		if got := pickAuthID(t, manager, opts); got != first {
+			t.Fatalf("pick #%d auth = %q, want pinned %q", i, got, first)
+		}
+	}
+
+	other := cliproxyexecutor.Options{OriginalRequest: []byte(`{"metadata":{"user_id":"user_abc_session_1"}}`)}
+	if got := pickAuthID(t, manager, other); got == first {
+		t.Fatalf("unrelated session auth = %q, want round-robin to move on", got)
+	}
+}
+
+func TestPickNextMixed_SessionAffinityFallsBackWhenCoolingDown(t *testing.T) {
+	t.Parallel()
+
+	manager := newSessionAffinityTestManager(t, true)
+	opts := cliproxyexecutor.Options{OriginalRequest: []byte(`{"prompt_cache_key":"cache-1"}`)}
+
+	first := pickAuthID(t, manager, opts)
+	manager.mu.Lock()
+	manager.auths[first].Unavailable = true
+	manager.auths[first].NextRetryAfter = time.Now().Add(time.Minute)
+	manager.auths[first].Quota.Exceeded = true
+	manager.mu.Unlock()
+
+	second := pickAuthID(t, manager, opts)
+	if second == first {
+		t.Fatalf("auth = %q, want fallback away from cooling auth", second)
+	}
+	if got := pickAuthID(t, manager, opts); got != second {
+		t.Fatalf("auth = %q, want session rebound to %q", got, second)
+	}
+}
+
+func TestPickNextMixed_SessionAffinityDisabled(t *testing.T) {
+	t.Parallel()
+
+	manager := newSessionAffinityTestManager(t, false)
+	headers := http.Header{}
+	headers.Set("X-Session-ID", "conversation-1")
+	opts := cliproxyexecutor.Options{Headers: headers}
+
+	first := pickAuthID(t, manager, opts)
+	if got := pickAuthID(t, manager, opts); got == first {
+		t.Fatalf("auth = %q, want round-robin rotation when affinity is disabled", got)
+	}
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startAttemptSpan opens the span covering one pass of a retry loop over all candidate credentials.
+func startAttemptSpan(ctx context.Context, name, model string, attempt int) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name,
+		attribute.String("cliproxy.model", model),
+		attribute.Int("cliproxy.attempt", attempt),
+	)
+}
+
+// startExecutionSpan opens the span covering a single executor call with the selected credential.
+func startExecutionSpan(ctx context.Context, name string, auth *Auth, provider, model string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name,
+		attribute.String("cliproxy.provider", provider),
+		attribute.String("cliproxy.auth_id", auth.ID),
+		attribute.String("cliproxy.upstream_model", model),
+	)
+}
+
+// selectAuth wraps pickNextMixed in an auth selection span.
+func (m *Manager) selectAuth(ctx context.Context, providers []string, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, string, error) {
+	pickCtx, span := tracing.Start(ctx, "cliproxy.auth.select",
+		attribute.String("cliproxy.model", model),
+		attribute.Int("cliproxy.tried", len(tried)),
+	)
+	auth, executor, provider, err := m.pickNextMixed(pickCtx, providers, model, opts, tried)
+	if err == nil && auth != nil {
+		span.SetAttributes(
+			attribute.String("cliproxy.provider", provider),
+			attribute.String("cliproxy.auth_id", auth.ID),
+		)
+	}
+	tracing.End(span, err)
+	return auth, executor, provider, err
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// WeightedSelector picks credentials at random in proportion to their configured weight.
+// Only the highest available priority tier is considered, so weights split traffic between
+// credentials of equal priority (e.g. 70/30 between two keys serving the same model).
+type WeightedSelector struct {
+	// random returns a value in [0, 1); nil falls back to math/rand/v2.
+	random func() float64
+}
+
+// Pick selects an available auth using weighted random sampling.
+func (s *WeightedSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = opts
+	now := time.Now()
+	available, err := getAvailableAuths(auths, provider, model, now)
+	if err != nil {
+		return nil, err
+	}
+	available = preferCodexWebsocketAuths(ctx, provider, available)
+	if len(available) == 1 {
+		return available[0], nil
+	}
+
+	total := 0
+	for i := range available {
+		total += authWeight(available[i])
+	}
+	random := s.random
+	if random == nil {
+		random = rand.Float64
+	}
+	target := int(random() * float64(total))
+	for i := range available {
+		target -= authWeight(available[i])
+		if target < 0 {
+			return available[i], nil
+		}
+	}
+	return available[len(available)-1], nil
+}
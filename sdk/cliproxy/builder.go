@@ -12,7 +12,9 @@ import (
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/pipeline"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
 )
 
 // Builder constructs a Service instance with customizable providers.
@@ -48,6 +50,12 @@ type Builder struct {
 
 	// serverOptions contains additional server configuration options.
 	serverOptions []api.ServerOption
+
+	// requestMiddleware decorates request translation for every executed request.
+	requestMiddleware []sdktranslator.RequestMiddleware
+
+	// responseMiddleware decorates translation of every response body and stream chunk.
+	responseMiddleware []sdktranslator.ResponseMiddleware
 }
 
 // Hooks allows callers to plug into service lifecycle stages.
@@ -61,6 +69,10 @@ type Hooks struct {
 	// OnAfterStart is called after the service has started successfully,
 	// providing access to the service instance for additional operations.
 	OnAfterStart func(*Service)
+
+	// Execution hooks run around every executor call made by the core manager,
+	// including retries on other credentials.
+	Execution []pipeline.Hook
 }
 
 // NewBuilder creates a Builder with default dependencies left unset.
@@ -114,7 +126,8 @@ func (b *Builder) WithWatcherFactory(factory WatcherFactory) *Builder {
 	return b
 }
 
-// WithHooks registers lifecycle hooks executed around service startup.
+// WithHooks registers lifecycle hooks executed around service startup and execution hooks
+// invoked around every executor call.
 func (b *Builder) WithHooks(h Hooks) *Builder {
 	b.hooks = h
 	return b
@@ -153,6 +166,20 @@ func (b *Builder) WithLocalManagementPassword(password string) *Builder {
 	return b
 }
 
+// WithRequestMiddleware appends translator middleware run around every request translation, in
+// registration order. Middleware sees the client payload and may rewrite it before or after calling next.
+func (b *Builder) WithRequestMiddleware(mw ...sdktranslator.RequestMiddleware) *Builder {
+	b.requestMiddleware = append(b.requestMiddleware, mw...)
+	return b
+}
+
+// WithResponseMiddleware appends translator middleware run around every non-stream response and
+// every upstream stream chunk, in registration order.
+func (b *Builder) WithResponseMiddleware(mw ...sdktranslator.ResponseMiddleware) *Builder {
+	b.responseMiddleware = append(b.responseMiddleware, mw...)
+	return b
+}
+
 // Build validates inputs, applies defaults, and returns a ready-to-run service.
 func (b *Builder) Build() (*Service, error) {
 	if b.cfg == nil {
@@ -205,6 +232,10 @@ func (b *Builder) Build() (*Service, error) {
 		switch strategy {
 		case "fill-first", "fillfirst", "ff":
 			selector = &coreauth.FillFirstSelector{}
+		case "least-latency", "leastlatency", "adaptive":
+			selector = &coreauth.LeastLatencySelector{}
+		case "weighted", "weighted-random":
+			selector = &coreauth.WeightedSelector{}
 		default:
 			selector = &coreauth.RoundRobinSelector{}
 		}
@@ -215,6 +246,21 @@ func (b *Builder) Build() (*Service, error) {
 	coreManager.SetRoundTripperProvider(newDefaultRoundTripperProvider())
 	coreManager.SetConfig(b.cfg)
 	coreManager.SetOAuthModelAlias(b.cfg.OAuthModelAlias)
+	if hook := pipeline.NewExecutionHook(b.hooks.Execution...); hook != nil {
+		coreManager.SetExecutionHook(hook)
+	}
+
+	serverOptions := append([]api.ServerOption(nil), b.serverOptions...)
+	if len(b.requestMiddleware) > 0 || len(b.responseMiddleware) > 0 {
+		pipeline := sdktranslator.NewPipeline(nil)
+		for _, mw := range b.requestMiddleware {
+			pipeline.UseRequest(mw)
+		}
+		for _, mw := range b.responseMiddleware {
+			pipeline.UseResponse(mw)
+		}
+		serverOptions = append(serverOptions, api.WithTranslatorPipeline(pipeline))
+	}
 
 	service := &Service{
 		cfg:            b.cfg,
@@ -226,7 +272,7 @@ func (b *Builder) Build() (*Service, error) {
 		authManager:    authManager,
 		accessManager:  accessManager,
 		coreManager:    coreManager,
-		serverOptions:  append([]api.ServerOption(nil), b.serverOptions...),
+		serverOptions:  serverOptions,
 	}
 	return service, nil
 }
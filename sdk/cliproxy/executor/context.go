@@ -1,6 +1,10 @@
 package executor
 
-import "context"
+import (
+	"bytes"
+	"context"
+	"sync"
+)
 
 type downstreamWebsocketContextKey struct{}
 
@@ -21,3 +25,45 @@ func DownstreamWebsocket(ctx context.Context) bool {
 	enabled, ok := raw.(bool)
 	return ok && enabled
 }
+
+type upstreamRequestContextKey struct{}
+
+// UpstreamRequest captures the provider-facing payload an executor sent for one call.
+type UpstreamRequest struct {
+	mu   sync.Mutex
+	body []byte
+}
+
+// Body returns the last payload recorded for the call, or nil when the executor reported none.
+func (r *UpstreamRequest) Body() []byte {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body
+}
+
+// WithUpstreamRequest returns a context whose executor call records its upstream payload into the returned holder.
+func WithUpstreamRequest(ctx context.Context) (context.Context, *UpstreamRequest) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	holder := &UpstreamRequest{}
+	return context.WithValue(ctx, upstreamRequestContextKey{}, holder), holder
+}
+
+// RecordUpstreamRequest stores body as the payload sent upstream for the call tracked by ctx.
+// Executors retrying internally overwrite earlier payloads, so the holder keeps the final attempt.
+func RecordUpstreamRequest(ctx context.Context, body []byte) {
+	if ctx == nil || len(body) == 0 {
+		return
+	}
+	holder, ok := ctx.Value(upstreamRequestContextKey{}).(*UpstreamRequest)
+	if !ok || holder == nil {
+		return
+	}
+	holder.mu.Lock()
+	holder.body = bytes.Clone(body)
+	holder.mu.Unlock()
+}
@@ -1,8 +1,10 @@
 package executor
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
 )
@@ -17,6 +19,14 @@ const (
 	SelectedAuthMetadataKey = "selected_auth_id"
 	// SelectedAuthCallbackMetadataKey carries an optional callback invoked with the selected auth ID.
 	SelectedAuthCallbackMetadataKey = "selected_auth_callback"
+	// AllowedAuthPrefixesMetadataKey restricts selection to auths whose prefix is listed ([]string).
+	AllowedAuthPrefixesMetadataKey = "allowed_auth_prefixes"
+	// AllowedModelsMetadataKey restricts model fallbacks to models matching these globs ([]string).
+	AllowedModelsMetadataKey = "allowed_models"
+	// ExcludedAuthsMetadataKey lists auth IDs the scheduler must not select ([]string).
+	ExcludedAuthsMetadataKey = "excluded_auth_ids"
+	// ServedModelMetadataKey stores the model that served the request after a model fallback.
+	ServedModelMetadataKey = "served_model"
 	// ExecutionSessionMetadataKey identifies a long-lived downstream execution session.
 	ExecutionSessionMetadataKey = "execution_session_id"
 )
@@ -85,3 +95,23 @@ type StatusError interface {
 	error
 	StatusCode() int
 }
+
+// TimeoutError reports an upstream request that exceeded one of its configured timeouts.
+// It maps to 504 Gateway Timeout and is retriable: the manager fails a stream over to another
+// credential when the timeout hits before the first chunk.
+type TimeoutError struct {
+	// Phase names the exceeded bound: "first-byte", "idle" or "total".
+	Phase string
+	// Timeout is the configured bound.
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Phase == "idle" {
+		return fmt.Sprintf("upstream stream stalled: no data for %s", e.Timeout)
+	}
+	return fmt.Sprintf("upstream %s timeout after %s", e.Phase, e.Timeout)
+}
+
+// StatusCode implements StatusError.
+func (e *TimeoutError) StatusCode() int { return http.StatusGatewayTimeout }
@@ -0,0 +1,84 @@
+package cliproxy
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/metrics"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// applyMetricsConfig serves /metrics on the dedicated listener when metrics.addr is set and on
+// the main API server otherwise.
+func (s *Service) applyMetricsConfig(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	if s.metricsServer == nil {
+		s.metricsServer = newAuxServer("metrics", newMetricsMux)
+		if s.coreManager != nil {
+			metrics.SetAuthStateSource(authStateSource(s.coreManager))
+		}
+	}
+	separate := cfg.Metrics.Addr != ""
+	metrics.SetEnabled(cfg.Metrics.Enable && !separate)
+	s.metricsServer.Apply(cfg.Metrics.Enable && separate, cfg.Metrics.Addr)
+}
+
+func (s *Service) shutdownMetrics(ctx context.Context) error {
+	if s == nil || s.metricsServer == nil {
+		return nil
+	}
+	return s.metricsServer.Shutdown(ctx)
+}
+
+func newMetricsMux() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	return mux
+}
+
+// authStateSource classifies every credential, and each of its model states, as active,
+// cooling down or disabled for the credential state gauges.
+func authStateSource(manager *coreauth.Manager) metrics.AuthStateSource {
+	return func() ([]metrics.AuthStateCount, []metrics.AuthStateCount) {
+		now := time.Now()
+		list := manager.List()
+		auths := make([]metrics.AuthStateCount, 0, len(list))
+		var models []metrics.AuthStateCount
+		for _, auth := range list {
+			if auth == nil {
+				continue
+			}
+			disabled := auth.Disabled || auth.Status == coreauth.StatusDisabled
+			cooling := 0
+			for _, state := range auth.ModelStates {
+				if state == nil {
+					continue
+				}
+				modelState := metrics.AuthStateActive
+				switch {
+				case disabled || state.Status == coreauth.StatusDisabled:
+					modelState = metrics.AuthStateDisabled
+				case state.Unavailable && state.NextRetryAfter.After(now):
+					modelState = metrics.AuthStateCooldown
+					cooling++
+				}
+				models = append(models, metrics.AuthStateCount{Provider: auth.Provider, State: modelState, Count: 1})
+			}
+			authState := metrics.AuthStateActive
+			switch {
+			case disabled:
+				authState = metrics.AuthStateDisabled
+			case auth.Unavailable && auth.NextRetryAfter.After(now):
+				authState = metrics.AuthStateCooldown
+			case cooling > 0 && cooling == len(auth.ModelStates):
+				authState = metrics.AuthStateCooldown
+			}
+			auths = append(auths, metrics.AuthStateCount{Provider: auth.Provider, State: authState, Count: 1})
+		}
+		return auths, models
+	}
+}
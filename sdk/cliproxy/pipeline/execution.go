@@ -0,0 +1,79 @@
+package pipeline
+
+import (
+	"context"
+	"net/http"
+
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+// NewExecutionHook adapts hooks to the auth manager's execution callbacks. Hooks run in
+// registration order and share one Context per executor call, so changes a hook makes to the
+// request, options or HTTP client in BeforeExecute apply to that call. Once the executor has sent
+// its upstream request, Context.Request.Payload holds the translated payload.
+func NewExecutionHook(hooks ...Hook) cliproxyauth.ExecutionHook {
+	filtered := make(executionHooks, 0, len(hooks))
+	for _, hook := range hooks {
+		if hook != nil {
+			filtered = append(filtered, hook)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	return filtered
+}
+
+type executionHooks []Hook
+
+// BeforeExecute implements cliproxyauth.ExecutionHook.
+func (h executionHooks) BeforeExecute(ctx context.Context, call *cliproxyauth.ExecutionCall) {
+	execCtx := &Context{
+		Request:    call.Request,
+		Options:    call.Options,
+		Auth:       call.Auth,
+		Translator: sdktranslator.PipelineFromContext(ctx),
+	}
+	if call.RoundTripper != nil {
+		execCtx.HTTPClient = &http.Client{Transport: call.RoundTripper}
+	}
+	for _, hook := range h {
+		hook.BeforeExecute(ctx, execCtx)
+	}
+	call.Request = execCtx.Request
+	call.Options = execCtx.Options
+	if execCtx.HTTPClient != nil && execCtx.HTTPClient.Transport != nil {
+		call.RoundTripper = execCtx.HTTPClient.Transport
+	}
+	call.State = execCtx
+}
+
+// AfterExecute implements cliproxyauth.ExecutionHook.
+func (h executionHooks) AfterExecute(ctx context.Context, call *cliproxyauth.ExecutionCall, resp cliproxyexecutor.Response, err error) {
+	execCtx := contextFor(call)
+	for _, hook := range h {
+		hook.AfterExecute(ctx, execCtx, resp, err)
+	}
+}
+
+// OnStreamChunk implements cliproxyauth.ExecutionHook.
+func (h executionHooks) OnStreamChunk(ctx context.Context, call *cliproxyauth.ExecutionCall, chunk cliproxyexecutor.StreamChunk) {
+	execCtx := contextFor(call)
+	for _, hook := range h {
+		hook.OnStreamChunk(ctx, execCtx, chunk)
+	}
+}
+
+func contextFor(call *cliproxyauth.ExecutionCall) *Context {
+	execCtx, ok := call.State.(*Context)
+	if !ok || execCtx == nil {
+		execCtx = &Context{Request: call.Request, Options: call.Options, Auth: call.Auth}
+		call.State = execCtx
+	}
+	if len(call.TranslatedRequest) > 0 {
+		execCtx.Request.Payload = call.TranslatedRequest
+	}
+	return execCtx
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/wsrelay"
@@ -58,7 +59,10 @@ type Service struct {
 	server *api.Server
 
 	// pprofServer manages the optional pprof HTTP debug server.
-	pprofServer *pprofServer
+	pprofServer *auxServer
+
+	// metricsServer manages the optional dedicated Prometheus metrics listener.
+	metricsServer *auxServer
 
 	// serverErr channel for server startup/shutdown errors.
 	serverErr chan error
@@ -198,7 +202,9 @@ func (s *Service) handleAuthUpdate(ctx context.Context, update watcher.AuthUpdat
 		s.applyCoreAuthRemoval(ctx, id)
 	default:
 		log.Debugf("received unknown auth update action: %v", update.Action)
+		return
 	}
+	s.pruneAuthTransports()
 }
 
 func (s *Service) ensureWebsocketGateway() {
@@ -359,6 +365,23 @@ func openAICompatInfoFromAuth(a *coreauth.Auth) (providerKey string, compatName
 	return "", "", false
 }
 
+// anthropicCompatInfoFromAuth reports whether a was synthesized from an anthropic-compatibility
+// entry, returning its provider key and the configured provider name.
+func anthropicCompatInfoFromAuth(a *coreauth.Auth) (providerKey string, compatName string, ok bool) {
+	if a == nil || len(a.Attributes) == 0 {
+		return "", "", false
+	}
+	compatName = strings.TrimSpace(a.Attributes["anthropic_compat_name"])
+	if compatName == "" {
+		return "", "", false
+	}
+	providerKey = strings.TrimSpace(a.Attributes["provider_key"])
+	if providerKey == "" {
+		providerKey = compatName
+	}
+	return strings.ToLower(providerKey), compatName, true
+}
+
 func (s *Service) ensureExecutorsForAuth(a *coreauth.Auth) {
 	s.ensureExecutorsForAuthWithMode(a, false)
 }
@@ -386,6 +409,10 @@ func (s *Service) ensureExecutorsForAuthWithMode(a *coreauth.Auth, forceReplace
 	if a.Disabled {
 		return
 	}
+	if compatProviderKey, _, isCompat := anthropicCompatInfoFromAuth(a); isCompat {
+		s.coreManager.RegisterExecutor(executor.NewAnthropicCompatExecutor(compatProviderKey, s.cfg))
+		return
+	}
 	if compatProviderKey, _, isCompat := openAICompatInfoFromAuth(a); isCompat {
 		if compatProviderKey == "" {
 			compatProviderKey = strings.ToLower(strings.TrimSpace(a.Provider))
@@ -463,6 +490,7 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 
 	usage.StartDefault(ctx)
+	configureUsageStore(s.cfg)
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
@@ -477,6 +505,7 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 
 	s.applyRetryConfig(s.cfg)
+	s.applyTransportConfig(s.cfg)
 
 	if s.coreManager != nil {
 		if errLoad := s.coreManager.Load(ctx); errLoad != nil {
@@ -547,6 +576,8 @@ func (s *Service) Run(ctx context.Context) error {
 	fmt.Printf("API server started successfully on: %s:%d\n", s.cfg.Host, s.cfg.Port)
 
 	s.applyPprofConfig(s.cfg)
+	s.applyMetricsConfig(s.cfg)
+	s.applyTracingConfig(s.cfg)
 
 	if s.hooks.OnAfterStart != nil {
 		s.hooks.OnAfterStart(s)
@@ -575,6 +606,10 @@ func (s *Service) Run(ctx context.Context) error {
 			switch strategy {
 			case "fill-first", "fillfirst", "ff":
 				return "fill-first"
+			case "least-latency", "leastlatency", "adaptive":
+				return "least-latency"
+			case "weighted", "weighted-random":
+				return "weighted"
 			default:
 				return "round-robin"
 			}
@@ -586,6 +621,10 @@ func (s *Service) Run(ctx context.Context) error {
 			switch nextStrategy {
 			case "fill-first":
 				selector = &coreauth.FillFirstSelector{}
+			case "least-latency":
+				selector = &coreauth.LeastLatencySelector{}
+			case "weighted":
+				selector = &coreauth.WeightedSelector{}
 			default:
 				selector = &coreauth.RoundRobinSelector{}
 			}
@@ -593,7 +632,10 @@ func (s *Service) Run(ctx context.Context) error {
 		}
 
 		s.applyRetryConfig(newCfg)
+		s.applyTransportConfig(newCfg)
 		s.applyPprofConfig(newCfg)
+		s.applyMetricsConfig(newCfg)
+		s.applyTracingConfig(newCfg)
 		if s.server != nil {
 			s.server.UpdateClients(newCfg)
 		}
@@ -694,6 +736,17 @@ func (s *Service) Shutdown(ctx context.Context) error {
 			}
 		}
 
+		if errShutdownMetrics := s.shutdownMetrics(ctx); errShutdownMetrics != nil {
+			log.Errorf("failed to stop metrics server: %v", errShutdownMetrics)
+			if shutdownErr == nil {
+				shutdownErr = errShutdownMetrics
+			}
+		}
+
+		if errShutdownTracing := tracing.Shutdown(ctx); errShutdownTracing != nil {
+			log.Errorf("failed to flush traces: %v", errShutdownTracing)
+		}
+
 		// no legacy clients to persist
 
 		if s.server != nil {
@@ -759,6 +812,10 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 			}
 		}
 	}
+	if providerKey, compatName, ok := anthropicCompatInfoFromAuth(a); ok {
+		s.registerAnthropicCompatModels(a, providerKey, compatName)
+		return
+	}
 	provider := strings.ToLower(strings.TrimSpace(a.Provider))
 	compatProviderKey, compatDisplayName, compatDetected := openAICompatInfoFromAuth(a)
 	if compatDetected {
@@ -931,6 +988,25 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 	GlobalModelRegistry().UnregisterClient(a.ID)
 }
 
+// registerAnthropicCompatModels registers the models of the anthropic-compatibility provider an
+// auth belongs to, or drops the auth's registration when the provider is gone or lists none.
+func (s *Service) registerAnthropicCompatModels(a *coreauth.Auth, providerKey, compatName string) {
+	if s.cfg != nil {
+		for i := range s.cfg.AnthropicCompatibility {
+			compat := &s.cfg.AnthropicCompatibility[i]
+			if !strings.EqualFold(compat.Name, compatName) {
+				continue
+			}
+			if models := buildConfigModels(compat.Models, compat.Name, "anthropic-compatibility"); len(models) > 0 {
+				GlobalModelRegistry().RegisterClient(a.ID, providerKey, applyModelPrefixes(models, a.Prefix, s.cfg.ForceModelPrefix))
+				return
+			}
+			break
+		}
+	}
+	GlobalModelRegistry().UnregisterClient(a.ID)
+}
+
 func (s *Service) resolveConfigClaudeKey(auth *coreauth.Auth) *config.ClaudeKey {
 	if auth == nil || s.cfg == nil {
 		return nil
@@ -0,0 +1,18 @@
+package cliproxy
+
+import (
+	"context"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/tracing"
+	log "github.com/sirupsen/logrus"
+)
+
+func (s *Service) applyTracingConfig(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	if err := tracing.Configure(context.Background(), cfg.Tracing); err != nil {
+		log.Errorf("failed to configure tracing: %v", err)
+	}
+}
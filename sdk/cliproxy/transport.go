@@ -0,0 +1,30 @@
+package cliproxy
+
+import (
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/transport"
+)
+
+// applyTransportConfig resizes the upstream connection pools; pooled transports built for
+// outdated settings or a replaced global proxy are evicted.
+func (s *Service) applyTransportConfig(cfg *config.Config) {
+	if s == nil || cfg == nil {
+		return
+	}
+	transport.Configure(&cfg.SDKConfig)
+}
+
+// pruneAuthTransports evicts pooled transports for proxy URLs that no live auth uses any more,
+// so a changed or removed per-auth proxy does not keep its connections open.
+func (s *Service) pruneAuthTransports() {
+	if s == nil || s.coreManager == nil {
+		return
+	}
+	var proxyURLs []string
+	for _, auth := range s.coreManager.List() {
+		if auth != nil && !auth.Disabled && auth.ProxyURL != "" {
+			proxyURLs = append(proxyURLs, auth.ProxyURL)
+		}
+	}
+	transport.RetainProxyURLs(proxyURLs)
+}
@@ -3,6 +3,7 @@ package usage
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -18,7 +19,10 @@ type Record struct {
 	Source      string
 	RequestedAt time.Time
 	Failed      bool
-	Detail      Detail
+	// Hedged marks the usage of a hedged attempt that lost the race to another credential.
+	// Its tokens were consumed upstream but did not serve the client.
+	Hedged bool
+	Detail Detail
 }
 
 // Detail holds the token usage breakdown.
@@ -111,6 +115,11 @@ func (m *Manager) Publish(ctx context.Context, record Record) {
 	}
 	// ensure worker is running even if Start was not called explicitly
 	m.Start(context.Background())
+	if ctx != nil {
+		if lost, ok := ctx.Value(hedgeContextKey{}).(*atomic.Bool); ok && lost.Load() {
+			record.Hedged = true
+		}
+	}
 	m.mu.Lock()
 	if m.closed {
 		m.mu.Unlock()
@@ -121,6 +130,25 @@ func (m *Manager) Publish(ctx context.Context, record Record) {
 	m.cond.Signal()
 }
 
+type hedgeContextKey struct{}
+
+// WithHedgeAttempt marks ctx as one attempt of a hedged request. Calling the returned function
+// declares the attempt the loser, so every record it publishes from then on is marked Hedged.
+func WithHedgeAttempt(ctx context.Context) (context.Context, func()) {
+	lost := new(atomic.Bool)
+	return context.WithValue(ctx, hedgeContextKey{}, lost), func() { lost.Store(true) }
+}
+
+// QueueDepth returns the number of records waiting to be dispatched to plugins.
+func (m *Manager) QueueDepth() int {
+	if m == nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.queue)
+}
+
 func (m *Manager) run(ctx context.Context) {
 	for {
 		m.mu.Lock()
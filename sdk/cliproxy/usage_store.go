@@ -0,0 +1,82 @@
+package cliproxy
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/store"
+	internalusage "github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// configureUsageStore installs the persistent usage store described by cfg.UsageStore and, when
+// in-memory statistics are enabled, reloads retained records so the dashboard survives restarts.
+// The postgres backend shares the connection of a registered postgres token store. A store that
+// is already installed is kept.
+func configureUsageStore(cfg *config.Config) {
+	if cfg == nil || !cfg.UsageStore.Enable || internalusage.ActiveStore() != nil {
+		return
+	}
+	retention := time.Duration(cfg.UsageStore.RetentionDays) * 24 * time.Hour
+
+	var backend internalusage.Store
+	if cfg.UsageStore.Backend == "postgres" {
+		pgStore, ok := sdkAuth.GetTokenStore().(*store.PostgresStore)
+		if !ok || pgStore == nil {
+			log.Warn("usage-store: postgres backend requires PGSTORE_DSN, falling back to file backend")
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			pgUsage, err := pgStore.UsageStore(ctx)
+			cancel()
+			if err != nil {
+				log.Errorf("usage-store: %v", err)
+				return
+			}
+			backend = pgUsage
+		}
+	}
+	if backend == nil {
+		dir := cfg.UsageStore.Path
+		if dir == "" {
+			if base := util.WritablePath(); base != "" {
+				dir = filepath.Join(base, "usage")
+			} else {
+				dir = filepath.Join(cfg.AuthDir, "usage")
+			}
+		}
+		fileStore, err := internalusage.NewFileStore(dir)
+		if err != nil {
+			log.Errorf("usage-store: %v", err)
+			return
+		}
+		backend = fileStore
+		log.Infof("usage-store: persisting usage records to %s", fileStore.Dir())
+	}
+	internalusage.SetStore(backend, retention)
+
+	if !cfg.UsageStatisticsEnabled {
+		return
+	}
+	var since time.Time
+	if retention > 0 {
+		since = time.Now().Add(-retention)
+	}
+	apiKeys := append([]string(nil), cfg.APIKeys...)
+	for _, entry := range cfg.ClientKeys {
+		apiKeys = append(apiKeys, entry.Key)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	result, err := internalusage.RestoreFromStore(ctx, backend, internalusage.GetRequestStatistics(), since, apiKeys)
+	if err != nil {
+		log.Warnf("usage-store: restore statistics: %v", err)
+		return
+	}
+	if result.Added > 0 {
+		log.Infof("usage-store: restored %d usage records", result.Added)
+	}
+}
@@ -11,6 +11,15 @@ type SDKConfig = internalconfig.SDKConfig
 type Config = internalconfig.Config
 
 type StreamingConfig = internalconfig.StreamingConfig
+type RateLimitConfig = internalconfig.RateLimitConfig
+type FanOutConfig = internalconfig.FanOutConfig
+type CompletionsConfig = internalconfig.CompletionsConfig
+type TransportConfig = internalconfig.TransportConfig
+type TimeoutsConfig = internalconfig.TimeoutsConfig
+type HedgingRule = internalconfig.HedgingRule
+type Timeouts = internalconfig.Timeouts
+type ClientKey = internalconfig.ClientKey
+type RateLimitOverride = internalconfig.RateLimitOverride
 type TLSConfig = internalconfig.TLSConfig
 type RemoteManagement = internalconfig.RemoteManagement
 type AmpCode = internalconfig.AmpCode
@@ -28,6 +37,9 @@ type VertexCompatModel = internalconfig.VertexCompatModel
 type OpenAICompatibility = internalconfig.OpenAICompatibility
 type OpenAICompatibilityAPIKey = internalconfig.OpenAICompatibilityAPIKey
 type OpenAICompatibilityModel = internalconfig.OpenAICompatibilityModel
+type AnthropicCompatibility = internalconfig.AnthropicCompatibility
+type AnthropicCompatibilityAPIKey = internalconfig.AnthropicCompatibilityAPIKey
+type AnthropicCompatibilityModel = internalconfig.AnthropicCompatibilityModel
 
 type TLS = internalconfig.TLSConfig
 
@@ -0,0 +1,208 @@
+// Package conformance replays recorded translator fixtures against a registry and compares the
+// output with golden files. A case captures one client request together with the upstream
+// response and stream payloads exactly as an executor hands them to the translator, so a
+// reported translation bug becomes a regression test by adding one more case file.
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+const goldenSuffix = ".golden.json"
+
+// Case is one recorded exchange for a translator pair.
+type Case struct {
+	// Name identifies the case in test output; LoadCases derives it from the file path.
+	Name string `json:"-"`
+	// Path is the case file the case was loaded from.
+	Path string `json:"-"`
+
+	// From is the client-facing schema and To the upstream schema.
+	From sdktranslator.Format `json:"from"`
+	To   sdktranslator.Format `json:"to"`
+	// Model is passed to the translators unchanged.
+	Model string `json:"model"`
+	// Stream selects the streaming variant of the request translation.
+	Stream bool `json:"stream,omitempty"`
+	// Alt is the executor's alt option, which executors expose to translators through the context.
+	Alt string `json:"alt,omitempty"`
+
+	// Request is the inbound client payload.
+	Request json.RawMessage `json:"request"`
+	// Response is the upstream non-stream body handed to TranslateNonStream, if any. A JSON
+	// string is used verbatim, for executors that hand over a buffered SSE body.
+	Response json.RawMessage `json:"response,omitempty"`
+	// StreamPayloads are the upstream stream payloads handed to TranslateStream, in order.
+	// They are recorded as the executor passes them, including SSE prefixes and a trailing
+	// "[DONE]" where the executor appends one.
+	StreamPayloads []string `json:"stream_payloads,omitempty"`
+
+	// Mask lists additional JSON keys whose values change between runs, such as generated IDs.
+	Mask []string `json:"mask,omitempty"`
+	// SkipInvariants names invariants known not to hold for this pair; Notes records why.
+	SkipInvariants []string `json:"skip_invariants,omitempty"`
+	Notes          string   `json:"notes,omitempty"`
+}
+
+// Result holds the translator output for a case.
+type Result struct {
+	Request  []byte
+	Response string
+	Stream   []string
+}
+
+// responseBody returns a copy of the upstream non-stream body.
+func (c Case) responseBody() []byte {
+	var text string
+	if err := json.Unmarshal(c.Response, &text); err == nil {
+		return []byte(text)
+	}
+	return bytes.Clone(c.Response)
+}
+
+// LoadCases reads every case file below dir. Golden files are skipped.
+func LoadCases(dir string) ([]Case, error) {
+	var cases []Case
+	errWalk := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".json") || strings.HasSuffix(path, goldenSuffix) {
+			return nil
+		}
+		data, errRead := os.ReadFile(path)
+		if errRead != nil {
+			return errRead
+		}
+		var c Case
+		if errDecode := json.Unmarshal(data, &c); errDecode != nil {
+			return fmt.Errorf("conformance: decode %s: %w", path, errDecode)
+		}
+		if c.From == "" || c.To == "" || len(c.Request) == 0 {
+			return fmt.Errorf("conformance: %s: from, to and request are required", path)
+		}
+		rel, errRel := filepath.Rel(dir, path)
+		if errRel != nil {
+			rel = path
+		}
+		c.Name = filepath.ToSlash(strings.TrimSuffix(rel, ".json"))
+		c.Path = path
+		cases = append(cases, c)
+		return nil
+	})
+	if errWalk != nil {
+		return nil, errWalk
+	}
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+// Replay runs the case through the registry the same way an executor would: the request is
+// translated once, the non-stream body is translated with its own state, and the stream
+// payloads share one state across chunks.
+func Replay(ctx context.Context, registry *sdktranslator.Registry, c Case) Result {
+	if registry == nil {
+		registry = sdktranslator.Default()
+	}
+	ctx = context.WithValue(ctx, "alt", c.Alt)
+	var result Result
+	result.Request = registry.TranslateRequest(c.From, c.To, c.Model, bytes.Clone(c.Request), c.Stream)
+	if body := c.responseBody(); len(body) > 0 {
+		var param any
+		result.Response = registry.TranslateNonStream(ctx, c.To, c.From, c.Model, c.Request, result.Request, body, &param)
+	}
+	if len(c.StreamPayloads) > 0 {
+		var param any
+		for _, payload := range c.StreamPayloads {
+			result.Stream = append(result.Stream, registry.TranslateStream(ctx, c.To, c.From, c.Model, c.Request, result.Request, []byte(payload), &param)...)
+		}
+	}
+	return result
+}
+
+// Run replays every case below dir, checks the response invariants and compares the output with
+// the golden file stored next to each case. With update set, golden files are rewritten instead.
+// Every translator pair registered in registry must be covered by at least one case.
+func Run(t *testing.T, registry *sdktranslator.Registry, dir string, update bool) {
+	t.Helper()
+	if registry == nil {
+		registry = sdktranslator.Default()
+	}
+	cases, err := LoadCases(dir)
+	if err != nil {
+		t.Fatalf("load cases: %v", err)
+	}
+
+	covered := make(map[sdktranslator.Pair]bool)
+	for _, c := range cases {
+		covered[sdktranslator.Pair{From: c.From, To: c.To}] = true
+		t.Run(c.Name, func(t *testing.T) {
+			result := Replay(context.Background(), registry, c)
+			for _, violation := range CheckInvariants(c, result) {
+				t.Error(violation)
+			}
+			golden, errGolden := renderGolden(c, result)
+			if errGolden != nil {
+				t.Fatalf("render golden: %v", errGolden)
+			}
+			goldenPath := strings.TrimSuffix(c.Path, ".json") + goldenSuffix
+			if update {
+				if errWrite := os.WriteFile(goldenPath, golden, 0o644); errWrite != nil {
+					t.Fatalf("write golden: %v", errWrite)
+				}
+				return
+			}
+			want, errRead := os.ReadFile(goldenPath)
+			if errRead != nil {
+				t.Fatalf("read golden: %v (run with -update to create it)", errRead)
+			}
+			if !bytes.Equal(want, golden) {
+				t.Errorf("output differs from %s (run with -update to accept it)\n--- want\n%s\n--- got\n%s", goldenPath, want, golden)
+			}
+		})
+	}
+
+	for _, pair := range registry.Pairs() {
+		if !covered[pair] {
+			t.Errorf("no conformance case for %s -> %s; add one under %s", pair.From, pair.To, dir)
+		}
+	}
+}
+
+type goldenFile struct {
+	Request  any   `json:"request"`
+	Response any   `json:"response,omitempty"`
+	Stream   []any `json:"stream,omitempty"`
+}
+
+// renderGolden serializes a result with volatile values masked and object keys sorted, so the
+// golden file only changes when the translation does.
+func renderGolden(c Case, result Result) ([]byte, error) {
+	mask := volatileKeys(c.Mask)
+	golden := goldenFile{Request: normalizeDocument(string(result.Request), mask)}
+	if len(c.Response) > 0 {
+		golden.Response = normalizeDocument(result.Response, mask)
+	}
+	for _, chunk := range result.Stream {
+		golden.Stream = append(golden.Stream, normalizeChunk(chunk, mask))
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(golden); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,287 @@
+package conformance
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+// Invariant names accepted in Case.SkipInvariants.
+const (
+	InvariantToolCalls    = "tool_calls"
+	InvariantReasoning    = "reasoning"
+	InvariantUsage        = "usage"
+	InvariantFinishReason = "finish_reason"
+)
+
+// Canonical finish reasons shared by every schema.
+const (
+	FinishStop      = "stop"
+	FinishLength    = "length"
+	FinishToolCalls = "tool_calls"
+)
+
+// ToolCall is a tool invocation found in a response.
+type ToolCall struct {
+	ID   string
+	Name string
+}
+
+// Summary is the schema-independent content of a response that a translation must preserve.
+type Summary struct {
+	ToolCalls    []ToolCall
+	Reasoning    bool
+	FinishReason string
+	InputTokens  int64
+	OutputTokens int64
+}
+
+// Summarize extracts the summary of a response in format from its payloads: one body for a
+// non-stream response, or every chunk of a stream in order.
+func Summarize(format sdktranslator.Format, payloads ...[]byte) Summary {
+	var s Summary
+	for _, payload := range payloads {
+		for _, doc := range documents(payload) {
+			root := gjson.ParseBytes(doc)
+			switch format {
+			case sdktranslator.FormatOpenAI:
+				s.addOpenAI(root)
+			case sdktranslator.FormatClaude:
+				s.addClaude(root)
+			case sdktranslator.FormatGemini, sdktranslator.FormatGeminiCLI, sdktranslator.FormatAntigravity:
+				if wrapped := root.Get("response"); wrapped.IsObject() {
+					root = wrapped
+				}
+				s.addGemini(root)
+			case sdktranslator.FormatOpenAIResponse, sdktranslator.FormatCodex:
+				s.addResponses(root)
+			}
+		}
+	}
+	// Gemini and the Responses API report a plain stop when the turn ends in tool calls.
+	switch format {
+	case sdktranslator.FormatOpenAI, sdktranslator.FormatClaude:
+	default:
+		if s.FinishReason == FinishStop && len(s.ToolCalls) > 0 {
+			s.FinishReason = FinishToolCalls
+		}
+	}
+	return s
+}
+
+func (s *Summary) addToolCall(id, name string) {
+	if id == "" && name == "" {
+		return
+	}
+	for _, call := range s.ToolCalls {
+		if id != "" && call.ID == id {
+			return
+		}
+	}
+	s.ToolCalls = append(s.ToolCalls, ToolCall{ID: id, Name: name})
+}
+
+// setUsage records token counts; zero values leave earlier counts from the same stream in place.
+func (s *Summary) setUsage(input, output int64) {
+	if input > 0 {
+		s.InputTokens = input
+	}
+	if output > 0 {
+		s.OutputTokens = output
+	}
+}
+
+func (s *Summary) addOpenAI(root gjson.Result) {
+	root.Get("choices").ForEach(func(_, choice gjson.Result) bool {
+		message := choice.Get("message")
+		if !message.Exists() {
+			message = choice.Get("delta")
+		}
+		message.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+			s.addToolCall(call.Get("id").String(), call.Get("function.name").String())
+			return true
+		})
+		// OpenAI-compatible providers disagree on the reasoning field name.
+		if message.Get("reasoning_content").String() != "" || message.Get("reasoning").String() != "" {
+			s.Reasoning = true
+		}
+		switch reason := choice.Get("finish_reason").String(); reason {
+		case "":
+		case "tool_calls", "function_call":
+			s.FinishReason = FinishToolCalls
+		default:
+			s.FinishReason = reason
+		}
+		return true
+	})
+	if usage := root.Get("usage"); usage.IsObject() {
+		s.setUsage(usage.Get("prompt_tokens").Int(), usage.Get("completion_tokens").Int())
+	}
+}
+
+func (s *Summary) addClaude(root gjson.Result) {
+	addBlock := func(block gjson.Result) {
+		switch block.Get("type").String() {
+		case "tool_use", "server_tool_use":
+			s.addToolCall(block.Get("id").String(), block.Get("name").String())
+		case "thinking", "redacted_thinking":
+			s.Reasoning = true
+		}
+	}
+	setStop := func(reason string) {
+		switch reason {
+		case "":
+		case "end_turn", "stop_sequence":
+			s.FinishReason = FinishStop
+		case "max_tokens":
+			s.FinishReason = FinishLength
+		case "tool_use":
+			s.FinishReason = FinishToolCalls
+		default:
+			s.FinishReason = reason
+		}
+	}
+	switch root.Get("type").String() {
+	case "message":
+		root.Get("content").ForEach(func(_, block gjson.Result) bool {
+			addBlock(block)
+			return true
+		})
+		setStop(root.Get("stop_reason").String())
+		s.setUsage(root.Get("usage.input_tokens").Int(), root.Get("usage.output_tokens").Int())
+	case "message_start":
+		s.setUsage(root.Get("message.usage.input_tokens").Int(), 0)
+	case "content_block_start":
+		addBlock(root.Get("content_block"))
+	case "message_delta":
+		setStop(root.Get("delta.stop_reason").String())
+		s.setUsage(root.Get("usage.input_tokens").Int(), root.Get("usage.output_tokens").Int())
+	}
+}
+
+func (s *Summary) addGemini(root gjson.Result) {
+	root.Get("candidates").ForEach(func(_, candidate gjson.Result) bool {
+		candidate.Get("content.parts").ForEach(func(_, part gjson.Result) bool {
+			if call := part.Get("functionCall"); call.Exists() {
+				s.addToolCall(call.Get("id").String(), call.Get("name").String())
+			}
+			if part.Get("thought").Bool() {
+				s.Reasoning = true
+			}
+			return true
+		})
+		switch reason := candidate.Get("finishReason").String(); reason {
+		case "":
+		case "STOP":
+			s.FinishReason = FinishStop
+		case "MAX_TOKENS":
+			s.FinishReason = FinishLength
+		default:
+			s.FinishReason = strings.ToLower(reason)
+		}
+		return true
+	})
+	if usage := root.Get("usageMetadata"); usage.IsObject() {
+		s.setUsage(usage.Get("promptTokenCount").Int(), usage.Get("candidatesTokenCount").Int()+usage.Get("thoughtsTokenCount").Int())
+	}
+}
+
+func (s *Summary) addResponses(root gjson.Result) {
+	addItem := func(item gjson.Result) {
+		switch item.Get("type").String() {
+		case "function_call":
+			s.addToolCall(item.Get("call_id").String(), item.Get("name").String())
+		case "reasoning":
+			s.Reasoning = true
+		}
+	}
+	if item := root.Get("item"); item.IsObject() {
+		addItem(item)
+	}
+	response := root
+	if wrapped := root.Get("response"); wrapped.IsObject() {
+		response = wrapped
+	}
+	if response.Get("object").String() != "response" {
+		return
+	}
+	response.Get("output").ForEach(func(_, item gjson.Result) bool {
+		addItem(item)
+		return true
+	})
+	switch response.Get("status").String() {
+	case "completed":
+		s.FinishReason = FinishStop
+	case "incomplete":
+		if response.Get("incomplete_details.reason").String() == "max_output_tokens" {
+			s.FinishReason = FinishLength
+		}
+	}
+	if usage := response.Get("usage"); usage.IsObject() {
+		s.setUsage(usage.Get("input_tokens").Int(), usage.Get("output_tokens").Int())
+	}
+}
+
+// CheckInvariants compares the upstream response of a case with its translation. Tool calls must
+// keep their names and order, and their identifiers whenever both schemas carry one; reasoning,
+// token usage and the finish reason must survive whenever the upstream reported them.
+func CheckInvariants(c Case, result Result) []error {
+	var violations []error
+	if len(c.Response) > 0 {
+		upstream := Summarize(c.To, c.responseBody())
+		translated := Summarize(c.From, []byte(result.Response))
+		violations = append(violations, compareSummaries(c, "response", upstream, translated)...)
+	}
+	if len(c.StreamPayloads) > 0 {
+		upstreamChunks := make([][]byte, 0, len(c.StreamPayloads))
+		for _, payload := range c.StreamPayloads {
+			upstreamChunks = append(upstreamChunks, []byte(payload))
+		}
+		translatedChunks := make([][]byte, 0, len(result.Stream))
+		for _, chunk := range result.Stream {
+			translatedChunks = append(translatedChunks, []byte(chunk))
+		}
+		violations = append(violations, compareSummaries(c, "stream", Summarize(c.To, upstreamChunks...), Summarize(c.From, translatedChunks...))...)
+	}
+	return violations
+}
+
+func compareSummaries(c Case, kind string, upstream, translated Summary) []error {
+	var violations []error
+	report := func(invariant, format string, args ...any) {
+		if slices.Contains(c.SkipInvariants, invariant) {
+			return
+		}
+		violations = append(violations, fmt.Errorf("%s %s: %s", kind, invariant, fmt.Sprintf(format, args...)))
+	}
+
+	if len(upstream.ToolCalls) != len(translated.ToolCalls) {
+		report(InvariantToolCalls, "upstream has %d tool calls, translation has %d", len(upstream.ToolCalls), len(translated.ToolCalls))
+	} else {
+		for i, want := range upstream.ToolCalls {
+			got := translated.ToolCalls[i]
+			if got.Name != want.Name {
+				report(InvariantToolCalls, "tool call %d name = %q, want %q", i, got.Name, want.Name)
+			}
+			if want.ID != "" && got.ID != "" && got.ID != want.ID {
+				report(InvariantToolCalls, "tool call %d id = %q, want %q", i, got.ID, want.ID)
+			}
+		}
+	}
+	if upstream.Reasoning && !translated.Reasoning {
+		report(InvariantReasoning, "upstream reasoning was dropped")
+	}
+	if upstream.InputTokens > 0 && translated.InputTokens != upstream.InputTokens {
+		report(InvariantUsage, "input tokens = %d, want %d", translated.InputTokens, upstream.InputTokens)
+	}
+	if upstream.OutputTokens > 0 && translated.OutputTokens != upstream.OutputTokens {
+		report(InvariantUsage, "output tokens = %d, want %d", translated.OutputTokens, upstream.OutputTokens)
+	}
+	if upstream.FinishReason != "" && translated.FinishReason != upstream.FinishReason {
+		report(InvariantFinishReason, "finish reason = %q, want %q", translated.FinishReason, upstream.FinishReason)
+	}
+	return violations
+}
@@ -0,0 +1,40 @@
+package conformance
+
+import (
+	"encoding/json"
+	"testing"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+func TestSummarizeCanonicalizesFinishReasons(t *testing.T) {
+	gemini := Summarize(sdktranslator.FormatGemini, []byte(`{"candidates":[{"content":{"parts":[{"functionCall":{"name":"f","args":{}}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":2,"thoughtsTokenCount":1}}`))
+	if gemini.FinishReason != FinishToolCalls || gemini.InputTokens != 3 || gemini.OutputTokens != 3 {
+		t.Fatalf("gemini summary = %+v", gemini)
+	}
+
+	claude := Summarize(sdktranslator.FormatClaude,
+		[]byte("event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":5}}}"),
+		[]byte(`data: {"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"f"}}`),
+		[]byte(`data: {"type":"message_delta","delta":{"stop_reason":"max_tokens"},"usage":{"output_tokens":4}}`),
+	)
+	if claude.FinishReason != FinishLength || len(claude.ToolCalls) != 1 || claude.ToolCalls[0].ID != "toolu_1" || claude.InputTokens != 5 || claude.OutputTokens != 4 {
+		t.Fatalf("claude summary = %+v", claude)
+	}
+}
+
+func TestCheckInvariantsHonoursSkips(t *testing.T) {
+	c := Case{
+		From:     sdktranslator.FormatOpenAI,
+		To:       sdktranslator.FormatOpenAI,
+		Response: json.RawMessage(`{"choices":[{"message":{"tool_calls":[{"id":"call_1","function":{"name":"f"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":2,"completion_tokens":1}}`),
+	}
+	result := Result{Response: `{"choices":[{"message":{"tool_calls":[{"id":"call_2","function":{"name":"f"}}]},"finish_reason":"stop"}]}`}
+	if got := len(CheckInvariants(c, result)); got != 4 {
+		t.Fatalf("violations = %d, want tool id, two usage and finish reason", got)
+	}
+	c.SkipInvariants = []string{InvariantUsage, InvariantToolCalls, InvariantFinishReason}
+	if violations := CheckInvariants(c, result); len(violations) != 0 {
+		t.Fatalf("violations = %v, want none after skipping", violations)
+	}
+}
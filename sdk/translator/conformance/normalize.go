@@ -0,0 +1,97 @@
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// defaultVolatileKeys are JSON keys whose values translators generate per call: response and
+// item identifiers, timestamps and synthesized user identifiers. Tool call identifiers are
+// checked by the invariants instead.
+var defaultVolatileKeys = []string{"id", "created", "created_at", "createdAt", "createTime", "responseId", "user_id"}
+
+const maskedValue = "<masked>"
+
+func volatileKeys(extra []string) map[string]struct{} {
+	keys := make(map[string]struct{}, len(defaultVolatileKeys)+len(extra))
+	for _, key := range defaultVolatileKeys {
+		keys[key] = struct{}{}
+	}
+	for _, key := range extra {
+		keys[key] = struct{}{}
+	}
+	return keys
+}
+
+// normalizeDocument decodes a JSON document and masks volatile values. Text that is not JSON is
+// returned unchanged.
+func normalizeDocument(text string, mask map[string]struct{}) any {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return text
+	}
+	return maskValue(value, mask)
+}
+
+// normalizeChunk renders one translated stream chunk. JSON chunks are normalized as documents;
+// SSE chunks become a list of their lines with each data line decoded.
+func normalizeChunk(chunk string, mask map[string]struct{}) any {
+	trimmed := strings.TrimSpace(chunk)
+	if json.Valid([]byte(trimmed)) {
+		return normalizeDocument(trimmed, mask)
+	}
+	lines := strings.Split(strings.TrimRight(chunk, "\n"), "\n")
+	out := make([]any, 0, len(lines))
+	for _, line := range lines {
+		data, isData := strings.CutPrefix(line, "data:")
+		data = strings.TrimSpace(data)
+		if isData && json.Valid([]byte(data)) {
+			out = append(out, map[string]any{"data": normalizeDocument(data, mask)})
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func maskValue(value any, mask map[string]struct{}) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, child := range typed {
+			if _, volatile := mask[key]; volatile && child != nil {
+				typed[key] = maskedValue
+				continue
+			}
+			typed[key] = maskValue(child, mask)
+		}
+	case []any:
+		for i, child := range typed {
+			typed[i] = maskValue(child, mask)
+		}
+	}
+	return value
+}
+
+// documents extracts the JSON documents carried by a payload, which may be a bare JSON body, an
+// SSE line or a block of SSE lines. Event names, comments and "[DONE]" markers are skipped.
+func documents(payload []byte) [][]byte {
+	trimmed := bytes.TrimSpace(payload)
+	if json.Valid(trimmed) {
+		return [][]byte{trimmed}
+	}
+	var docs [][]byte
+	for _, line := range bytes.Split(trimmed, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if data, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			line = bytes.TrimSpace(data)
+		}
+		if len(line) == 0 || line[0] != '{' || !json.Valid(line) {
+			continue
+		}
+		docs = append(docs, line)
+	}
+	return docs
+}
@@ -104,3 +104,26 @@ func (p *Pipeline) TranslateResponse(ctx context.Context, from, to Format, resp
 
 	return handler(ctx, resp)
 }
+
+type pipelineContextKey struct{}
+
+// WithPipeline returns a context carrying p, so executors translating on behalf of the request run
+// its middleware. A nil pipeline leaves ctx unchanged.
+func WithPipeline(ctx context.Context, p *Pipeline) context.Context {
+	if p == nil {
+		return ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, pipelineContextKey{}, p)
+}
+
+// PipelineFromContext returns the pipeline attached by WithPipeline, or nil when none is set.
+func PipelineFromContext(ctx context.Context) *Pipeline {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(pipelineContextKey{}).(*Pipeline)
+	return p
+}
@@ -2,21 +2,24 @@ package translator
 
 import (
 	"context"
+	"sort"
 	"sync"
 )
 
 // Registry manages translation functions across schemas.
 type Registry struct {
-	mu        sync.RWMutex
-	requests  map[Format]map[Format]RequestTransform
-	responses map[Format]map[Format]ResponseTransform
+	mu         sync.RWMutex
+	requests   map[Format]map[Format]RequestTransform
+	responses  map[Format]map[Format]ResponseTransform
+	validators map[Format]RequestValidator
 }
 
 // NewRegistry constructs an empty translator registry.
 func NewRegistry() *Registry {
 	return &Registry{
-		requests:  make(map[Format]map[Format]RequestTransform),
-		responses: make(map[Format]map[Format]ResponseTransform),
+		requests:   make(map[Format]map[Format]RequestTransform),
+		responses:  make(map[Format]map[Format]ResponseTransform),
+		validators: make(map[Format]RequestValidator),
 	}
 }
 
@@ -38,6 +41,67 @@ func (r *Registry) Register(from, to Format, request RequestTransform, response
 	r.responses[from][to] = response
 }
 
+// RegisterValidator stores the inbound request validator for a schema, replacing any previous one.
+// Passing a nil validator removes it.
+func (r *Registry) RegisterValidator(format Format, validator RequestValidator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if validator == nil {
+		delete(r.validators, format)
+		return
+	}
+	r.validators[format] = validator
+}
+
+// ValidateRequest checks an inbound payload against the validator registered for its schema.
+// Schemas without a validator accept every payload.
+func (r *Registry) ValidateRequest(format Format, rawJSON []byte) error {
+	r.mu.RLock()
+	validator := r.validators[format]
+	r.mu.RUnlock()
+
+	if validator == nil {
+		return nil
+	}
+	return validator(rawJSON)
+}
+
+// Pair identifies a translator registered from a client schema to an upstream schema.
+type Pair struct {
+	From Format
+	To   Format
+}
+
+// Pairs lists every registered translator pair, sorted by source and then target schema.
+func (r *Registry) Pairs() []Pair {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[Pair]struct{})
+	for from, byTarget := range r.requests {
+		for to := range byTarget {
+			seen[Pair{From: from, To: to}] = struct{}{}
+		}
+	}
+	for from, byTarget := range r.responses {
+		for to := range byTarget {
+			seen[Pair{From: from, To: to}] = struct{}{}
+		}
+	}
+	pairs := make([]Pair, 0, len(seen))
+	for pair := range seen {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].From != pairs[j].From {
+			return pairs[i].From < pairs[j].From
+		}
+		return pairs[i].To < pairs[j].To
+	})
+	return pairs
+}
+
 // TranslateRequest converts a payload between schemas, returning the original payload
 // if no translator is registered.
 func (r *Registry) TranslateRequest(from, to Format, model string, rawJSON []byte, stream bool) []byte {
@@ -121,6 +185,16 @@ func TranslateRequest(from, to Format, model string, rawJSON []byte, stream bool
 	return defaultRegistry.TranslateRequest(from, to, model, rawJSON, stream)
 }
 
+// RegisterValidator attaches a request validator to the default registry.
+func RegisterValidator(format Format, validator RequestValidator) {
+	defaultRegistry.RegisterValidator(format, validator)
+}
+
+// ValidateRequest is a helper on the default registry.
+func ValidateRequest(format Format, rawJSON []byte) error {
+	return defaultRegistry.ValidateRequest(format, rawJSON)
+}
+
 // HasResponseTransformer inspects the default registry.
 func HasResponseTransformer(from, to Format) bool {
 	return defaultRegistry.HasResponseTransformer(from, to)
@@ -0,0 +1,20 @@
+package test
+
+import (
+	"flag"
+	"testing"
+
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/translator/conformance"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite translator conformance golden files")
+
+// TestTranslatorConformance replays the recorded cases under testdata/translator through the
+// built-in translators. Add a case by copying a neighbouring file, editing the request or the
+// upstream payloads, and running `go test ./test -run TestTranslatorConformance -update`.
+func TestTranslatorConformance(t *testing.T) {
+	conformance.Run(t, sdktranslator.Default(), "testdata/translator", *updateGolden)
+}